@@ -3,69 +3,204 @@ package main
 import (
 	"context"
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/acme/autocert"
 
 	"vessel-backend/internal/api"
+	"vessel-backend/internal/config"
 	"vessel-backend/internal/database"
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/web"
 )
 
 // Version is set at build time via -ldflags, or defaults to dev
 var Version = "0.5.2"
 
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// configPathFromArgs pre-scans os.Args for the -config flag, since the
+// config file must be loaded before the other flags declare their defaults
+func configPathFromArgs() (string, bool) {
+	args := os.Args[1:]
+	for i, arg := range args {
+		for _, name := range []string{"-config", "--config"} {
+			if arg == name && i+1 < len(args) {
+				return args[i+1], true
+			}
+			if strings.HasPrefix(arg, name+"=") {
+				return strings.TrimPrefix(arg, name+"="), true
+			}
+		}
+	}
+	if path := os.Getenv("CONFIG_PATH"); path != "" {
+		return path, true
+	}
+	return "./config.yaml", false
+}
+
+// encryptSecretFromArgs pre-scans os.Args for -encrypt-secret, since the
+// helper must work even when the config file fails to load (for example
+// because an existing secret no longer decrypts)
+func encryptSecretFromArgs() (string, bool) {
+	args := os.Args[1:]
+	for i, arg := range args {
+		for _, name := range []string{"-encrypt-secret", "--encrypt-secret"} {
+			if arg == name && i+1 < len(args) {
+				return args[i+1], true
+			}
+			if strings.HasPrefix(arg, name+"=") {
+				return strings.TrimPrefix(arg, name+"="), true
+			}
+		}
 	}
-	return defaultValue
+	return "", false
 }
 
 func main() {
+	// CLI helper: seal a value for pasting into config as enc:v1:...
+	if value, ok := encryptSecretFromArgs(); ok {
+		sealed, err := config.EncryptValue(value)
+		if err != nil {
+			slog.Error("Failed to encrypt value", "error", err)
+			os.Exit(1)
+		}
+		fmt.Println(sealed)
+		return
+	}
+
+	// Load config first so flags can default to its values.
+	// Precedence: flags > environment > config file > defaults.
+	configPath, configExplicit := configPathFromArgs()
+	cfg, err := config.Load(configPath, configExplicit)
+	if err != nil {
+		slog.Error("Failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	// Install the structured logger before anything else logs
+	logging.Setup(cfg.Logging)
+	logger := logging.Module("server")
+
 	var (
-		port      = flag.String("port", getEnvOrDefault("PORT", "8080"), "Server port")
-		dbPath    = flag.String("db", getEnvOrDefault("DB_PATH", "./data/vessel.db"), "Database file path")
-		ollamaURL = flag.String("ollama-url", getEnvOrDefault("OLLAMA_URL", "http://localhost:11434"), "Ollama API URL")
+		port           = flag.String("port", cfg.Server.Port, "Server port")
+		dbPath         = flag.String("db", cfg.Database.Path, "Database file path")
+		ollamaURL      = flag.String("ollama-url", cfg.Ollama.URL, "Ollama API URL")
+		tlsCert        = flag.String("tls-cert", cfg.Server.TLS.CertFile, "TLS certificate file (enables HTTPS together with -tls-key)")
+		tlsKey         = flag.String("tls-key", cfg.Server.TLS.KeyFile, "TLS private key file")
+		autocertDomain = flag.String("autocert-domain", cfg.Server.TLS.AutocertDomain, "Domain for automatic Let's Encrypt certificates (requires ports 80/443)")
+		migrateOnly    = flag.Bool("migrate-only", false, "Run database migrations and exit")
+		migrateDownTo  = flag.Int("migrate-down-to", -1, "Roll the schema back to this version and exit")
+		_              = flag.String("config", configPath, "Path to YAML config file")
+		_              = flag.String("encrypt-secret", "", "Encrypt a value for use as a config secret and exit")
 	)
 	flag.Parse()
 
+	// Write flag values back so config.Get() reflects the final precedence
+	cfg.Server.Port = *port
+	cfg.Database.Path = *dbPath
+	cfg.Ollama.URL = *ollamaURL
+
+	// Apply a staged restore (uploaded via /admin/restore) before opening
+	if restored, err := database.ApplyStagedRestore(*dbPath); err != nil {
+		logger.Warn("Staged restore not applied", "error", err)
+	} else if restored {
+		logger.Info("Restored database from staged backup", "database", *dbPath)
+	}
+
 	// Initialize database
 	db, err := database.OpenDatabase(*dbPath)
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		logger.Error("Failed to open database", "error", err)
+		os.Exit(1)
 	}
 	defer db.Close()
 
+	// Rollback mode: migrate down to the requested version and exit
+	if *migrateDownTo >= 0 {
+		if err := database.MigrateDown(db, *migrateDownTo); err != nil {
+			logger.Error("Failed to roll back migrations", "error", err)
+			os.Exit(1)
+		}
+		version, _ := database.SchemaVersion(db)
+		logger.Info("Schema rolled back", "version", version)
+		return
+	}
+
 	// Run migrations
 	if err := database.RunMigrations(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+		logger.Error("Failed to run migrations", "error", err)
+		os.Exit(1)
+	}
+
+	if *migrateOnly {
+		version, _ := database.SchemaVersion(db)
+		logger.Info("Migrations applied", "version", version)
+		return
 	}
 
 	// Setup Gin router
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
-	r.Use(gin.Logger())
+	r.Use(api.RequestIDMiddleware())
+	r.Use(api.RequestLoggerMiddleware())
 	r.Use(gin.Recovery())
 
-	// CORS configuration
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+	// CORS configuration - origins come from config (server.cors_origins /
+	// CORS_ORIGINS). Credentials are only allowed with an explicit origin
+	// list; the wildcard + credentials combination is rejected by browsers
+	// and would be unsafe anyway.
+	allowAllOrigins := len(cfg.Server.CORSOrigins) == 0
+	for _, origin := range cfg.Server.CORSOrigins {
+		if origin == "*" {
+			allowAllOrigins = true
+			break
+		}
+	}
+	corsConfig := cors.Config{
+		AllowMethods:  []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:  []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		ExposeHeaders: []string{"Content-Length"},
+		MaxAge:        12 * time.Hour,
+	}
+	if allowAllOrigins {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = cfg.Server.CORSOrigins
+		corsConfig.AllowCredentials = true
+	}
+	r.Use(cors.New(corsConfig))
+
+	// Security headers on every response
+	r.Use(api.SecurityHeadersMiddleware())
+
+	// Access anomaly alerts must wrap the IP filter so they see its
+	// rejections
+	r.Use(api.AccessAnomalyMiddleware(db))
+
+	// IP allow/deny lists (server.allow_ips / server.deny_ips); a no-op
+	// when neither is configured
+	r.Use(api.IPFilterMiddleware())
+
+	// Gzip JSON/text responses; streaming endpoints pass through untouched
+	r.Use(api.CompressionMiddleware())
 
 	// Register routes
 	api.SetupRoutes(r, db, *ollamaURL, Version)
 
+	// Serve the embedded frontend for everything that isn't an API route
+	r.NoRoute(web.SPAHandler())
+
+	// Announce new releases via webhook/ntfy when configured
+	api.StartUpdateNotifier(Version)
+
 	// Create server
 	srv := &http.Server{
 		Addr:    ":" + *port,
@@ -74,15 +209,28 @@ func main() {
 
 	// Initialize fetcher and log the method being used
 	fetcher := api.GetFetcher()
-	log.Printf("URL fetcher method: %s (headless Chrome: %v)", fetcher.Method(), fetcher.HasChrome())
+	logger.Info("URL fetcher initialized", "method", fetcher.Method(), "headlessChrome", fetcher.HasChrome())
 
 	// Graceful shutdown handling
 	go func() {
-		log.Printf("Server starting on port %s", *port)
-		log.Printf("Ollama URL: %s (using official Go client)", *ollamaURL)
-		log.Printf("Database: %s", *dbPath)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+		logger.Info("Server starting", "port", *port, "ollamaUrl", *ollamaURL, "database", *dbPath)
+
+		var err error
+		switch {
+		case *autocertDomain != "":
+			err = serveAutocert(srv, *autocertDomain, cfg.Server.TLS.AutocertCacheDir)
+		case *tlsCert != "" && *tlsKey != "":
+			if cfg.Server.TLS.RedirectHTTP {
+				go serveHTTPRedirect(nil)
+			}
+			logger.Info("Serving HTTPS", "cert", *tlsCert)
+			err = srv.ListenAndServeTLS(*tlsCert, *tlsKey)
+		default:
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logger.Error("Failed to start server", "error", err)
+			os.Exit(1)
 		}
 	}()
 
@@ -90,14 +238,56 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	logger.Info("Shutting down server...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		logger.Error("Server forced to shutdown", "error", err)
+		os.Exit(1)
 	}
 
-	log.Println("Server exited")
+	logger.Info("Server exited")
+}
+
+// serveAutocert serves HTTPS with automatic Let's Encrypt certificates.
+// Certificates are cached on disk so renewals survive restarts. The plain
+// HTTP listener on :80 answers ACME challenges and redirects everything
+// else to HTTPS.
+func serveAutocert(srv *http.Server, domain, cacheDir string) error {
+	if cacheDir == "" {
+		cacheDir = "./data/autocert"
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domain),
+		Cache:      autocert.DirCache(cacheDir),
+	}
+
+	srv.TLSConfig = manager.TLSConfig()
+
+	go serveHTTPRedirect(manager)
+
+	logging.Module("server").Info("Serving HTTPS with automatic certificates", "domain", domain)
+	return srv.ListenAndServeTLS("", "")
+}
+
+// serveHTTPRedirect listens on :80 and redirects to HTTPS. When an autocert
+// manager is provided, its handler answers ACME HTTP-01 challenges first.
+func serveHTTPRedirect(manager *autocert.Manager) {
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := "https://" + r.Host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+
+	var handler http.Handler = redirect
+	if manager != nil {
+		handler = manager.HTTPHandler(redirect)
+	}
+
+	if err := http.ListenAndServe(":80", handler); err != nil {
+		logging.Module("server").Error("HTTP redirect listener failed", "error", err)
+	}
 }
@@ -0,0 +1,355 @@
+// Command vessel is a companion CLI for a running vessel server: chat,
+// model management, export, and backup over the HTTP API, for headless
+// use over SSH.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultServer is where the CLI looks for vessel unless -server or
+// VESSEL_SERVER says otherwise
+const defaultServer = "http://localhost:8080"
+
+func main() {
+	server := flag.String("server", serverFromEnv(), "Vessel server URL")
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	client := &cliClient{server: strings.TrimSuffix(*server, "/")}
+
+	var err error
+	switch args[0] {
+	case "chat":
+		err = client.chat(args[1:])
+	case "models":
+		err = client.models(args[1:])
+	case "export":
+		err = client.export(args[1:])
+	case "backup":
+		err = client.backup(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", args[0])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: vessel [-server URL] <command>
+
+Commands:
+  chat -model NAME       Interactive chat session (Ctrl-D to exit)
+  models list            List models on the Ollama instance
+  models pull NAME       Pull a model, showing progress
+  export [-o FILE]       Download a portable data export (zip)
+  backup [-o FILE]       Download a database backup
+
+The server URL defaults to VESSEL_SERVER or %s.
+`, defaultServer)
+}
+
+func serverFromEnv() string {
+	if server := os.Getenv("VESSEL_SERVER"); server != "" {
+		return server
+	}
+	return defaultServer
+}
+
+// cliClient talks to one vessel server
+type cliClient struct {
+	server string
+}
+
+// httpClient has no overall timeout: chat and pull responses stream for
+// as long as the model needs
+var httpClient = &http.Client{}
+
+// chat runs an interactive REPL against the chat endpoint, keeping the
+// conversation history in memory for the session
+func (c *cliClient) chat(args []string) error {
+	fs := flag.NewFlagSet("chat", flag.ExitOnError)
+	model := fs.String("model", "", "Model to chat with (required)")
+	fs.Parse(args)
+
+	if *model == "" {
+		return fmt.Errorf("chat requires -model")
+	}
+
+	type chatMessage struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+
+	var history []chatMessage
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	fmt.Printf("Chatting with %s on %s (Ctrl-D to exit)\n", *model, c.server)
+
+	for {
+		fmt.Print("> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return scanner.Err()
+		}
+		input := strings.TrimSpace(scanner.Text())
+		if input == "" {
+			continue
+		}
+
+		history = append(history, chatMessage{Role: "user", Content: input})
+
+		body, err := json.Marshal(map[string]interface{}{
+			"model":    *model,
+			"messages": history,
+			"stream":   true,
+		})
+		if err != nil {
+			return err
+		}
+
+		resp, err := httpClient.Post(c.server+"/api/v1/ollama/api/chat", "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := readAPIError(resp)
+			resp.Body.Close()
+			return err
+		}
+
+		// Stream NDJSON frames, printing content as it arrives
+		var reply strings.Builder
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var frame struct {
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+				Done  bool   `json:"done"`
+				Error string `json:"error"`
+			}
+			if err := decoder.Decode(&frame); err != nil {
+				if err == io.EOF {
+					break
+				}
+				resp.Body.Close()
+				return err
+			}
+			if frame.Error != "" {
+				resp.Body.Close()
+				return fmt.Errorf("%s", frame.Error)
+			}
+			fmt.Print(frame.Message.Content)
+			reply.WriteString(frame.Message.Content)
+			if frame.Done {
+				break
+			}
+		}
+		resp.Body.Close()
+		fmt.Println()
+
+		history = append(history, chatMessage{Role: "assistant", Content: reply.String()})
+	}
+}
+
+// models dispatches the models subcommands
+func (c *cliClient) models(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("models requires a subcommand: list or pull")
+	}
+	switch args[0] {
+	case "list":
+		return c.modelsList()
+	case "pull":
+		if len(args) < 2 {
+			return fmt.Errorf("models pull requires a model name")
+		}
+		return c.modelsPull(args[1])
+	default:
+		return fmt.Errorf("unknown models subcommand: %s", args[0])
+	}
+}
+
+// modelsList prints the models available on the Ollama instance
+func (c *cliClient) modelsList() error {
+	resp, err := httpClient.Get(c.server + "/api/v1/ollama/api/tags")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return readAPIError(resp)
+	}
+
+	var decoded struct {
+		Models []struct {
+			Name       string    `json:"name"`
+			Size       int64     `json:"size"`
+			ModifiedAt time.Time `json:"modified_at"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return err
+	}
+
+	if len(decoded.Models) == 0 {
+		fmt.Println("No models installed.")
+		return nil
+	}
+
+	fmt.Printf("%-40s %10s  %s\n", "NAME", "SIZE", "MODIFIED")
+	for _, model := range decoded.Models {
+		fmt.Printf("%-40s %10s  %s\n", model.Name, formatSize(model.Size), model.ModifiedAt.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// modelsPull pulls a model, rendering the streamed progress on one line
+func (c *cliClient) modelsPull(name string) error {
+	body, _ := json.Marshal(map[string]string{"model": name})
+
+	resp, err := httpClient.Post(c.server+"/api/v1/ollama/api/pull", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return readAPIError(resp)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var frame struct {
+			Status    string `json:"status"`
+			Total     int64  `json:"total"`
+			Completed int64  `json:"completed"`
+			Error     string `json:"error"`
+		}
+		if err := decoder.Decode(&frame); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		if frame.Error != "" {
+			fmt.Println()
+			return fmt.Errorf("%s", frame.Error)
+		}
+		if frame.Total > 0 {
+			fmt.Printf("\r%-30s %3d%% (%s / %s)   ",
+				frame.Status, frame.Completed*100/frame.Total,
+				formatSize(frame.Completed), formatSize(frame.Total))
+		} else {
+			fmt.Printf("\r%-60s", frame.Status)
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// export downloads the portable data export zip
+func (c *cliClient) export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	output := fs.String("o", "", "Output file (default vessel-export-<date>.zip)")
+	fs.Parse(args)
+
+	if *output == "" {
+		*output = "vessel-export-" + time.Now().Format("20060102-150405") + ".zip"
+	}
+
+	return c.download("GET", "/api/v1/admin/export", *output)
+}
+
+// backup downloads a database backup
+func (c *cliClient) backup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := fs.String("o", "", "Output file (default vessel-backup-<date>.db)")
+	fs.Parse(args)
+
+	if *output == "" {
+		*output = "vessel-backup-" + time.Now().Format("20060102-150405") + ".db"
+	}
+
+	return c.download("POST", "/api/v1/admin/backup", *output)
+}
+
+// download streams one endpoint's response body to a local file
+func (c *cliClient) download(method, path, output string) error {
+	req, err := http.NewRequest(method, c.server+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return readAPIError(resp)
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	written, err := io.Copy(out, resp.Body)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %s (%s)\n", output, formatSize(written))
+	return nil
+}
+
+// readAPIError turns a non-200 response into an error, preferring the
+// server's {"error": ...} message when present
+func readAPIError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	var decoded struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &decoded) == nil && decoded.Error != "" {
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, decoded.Error)
+	}
+	return fmt.Errorf("server returned %d", resp.StatusCode)
+}
+
+// formatSize renders a byte count in human units
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
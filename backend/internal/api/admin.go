@@ -0,0 +1,90 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serverStartTime anchors the uptime reported by the admin stats endpoint
+var serverStartTime = time.Now()
+
+// activeStreams and activePulls count in-flight streaming generations and
+// model pulls. They are maintained by the Ollama handlers and exposed via
+// /admin/stats so an operator can see live load at a glance.
+var (
+	activeStreams atomic.Int64
+	activePulls   atomic.Int64
+)
+
+// ModelUsage is one entry in the top-models-by-usage list
+type ModelUsage struct {
+	Model    string `json:"model"`
+	ChatsCnt int    `json:"chats"`
+}
+
+// AdminStatsHandler aggregates operational statistics for an admin
+// dashboard: content counts, database size on disk, live stream/pull
+// counts, the most-used models, and process uptime.
+func AdminStatsHandler(db *sql.DB, dbPath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats := gin.H{
+			"uptimeSeconds":     int64(time.Since(serverStartTime).Seconds()),
+			"activeStreams":     activeStreams.Load(),
+			"activePulls":       activePulls.Load(),
+			"streamRejects":     streamRejects.Load(),
+			"peakActiveStreams": streamPeakTotal.Load(),
+		}
+
+		var chatCount, messageCount, attachmentCount int
+		if err := db.QueryRow("SELECT COUNT(*) FROM chats").Scan(&chatCount); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query stats: " + err.Error()})
+			return
+		}
+		if err := db.QueryRow("SELECT COUNT(*) FROM messages").Scan(&messageCount); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query stats: " + err.Error()})
+			return
+		}
+		if err := db.QueryRow("SELECT COUNT(*) FROM attachments").Scan(&attachmentCount); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query stats: " + err.Error()})
+			return
+		}
+		stats["chats"] = chatCount
+		stats["messages"] = messageCount
+		stats["attachments"] = attachmentCount
+
+		// Database size on disk (0 if the file can't be statted)
+		var dbSize int64
+		if info, err := os.Stat(dbPath); err == nil {
+			dbSize = info.Size()
+		}
+		stats["databaseSizeBytes"] = dbSize
+
+		// Top models by number of chats using them
+		topModels := []ModelUsage{}
+		rows, err := db.Query(`
+			SELECT model, COUNT(*) AS cnt
+			FROM chats
+			WHERE model != ''
+			GROUP BY model
+			ORDER BY cnt DESC
+			LIMIT 10
+		`)
+		if err == nil {
+			defer rows.Close()
+			for rows.Next() {
+				var usage ModelUsage
+				if err := rows.Scan(&usage.Model, &usage.ChatsCnt); err == nil {
+					topModels = append(topModels, usage)
+				}
+			}
+		}
+		stats["topModels"] = topModels
+
+		c.JSON(http.StatusOK, stats)
+	}
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
+)
+
+// deniedAlertThreshold is how many rejected requests from one address
+// within the window trigger an alert
+const deniedAlertThreshold = 5
+
+// deniedAlertWindow is the sliding window for counting rejections
+const deniedAlertWindow = 10 * time.Minute
+
+// deniedWindow counts one address's rejected requests in the current
+// window; alerted stops a flood from producing an alert per request
+type deniedWindow struct {
+	count       int
+	windowStart time.Time
+	alerted     bool
+}
+
+var (
+	anomalyMu    sync.Mutex
+	knownOrigins = map[string]bool{}
+	deniedByIP   = map[string]*deniedWindow{}
+)
+
+// AccessAnomalyMiddleware gives self-hosters basic intrusion
+// visibility: the first request from a new public address raises a
+// notification (and webhook event), as do repeated rejected requests
+// from one address. Must run before the filters that do the rejecting
+// so it sees their status codes.
+func AccessAnomalyMiddleware(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		ip := getClientIP(c)
+		if ip == "" {
+			return
+		}
+		status := c.Writer.Status()
+		if status == http.StatusUnauthorized || status == http.StatusForbidden {
+			noteDeniedRequest(ip)
+		}
+		noteAccessOrigin(db, ip)
+	}
+}
+
+// noteDeniedRequest counts a rejected request and alerts once per
+// window when an address keeps hammering
+func noteDeniedRequest(ip string) {
+	anomalyMu.Lock()
+	defer anomalyMu.Unlock()
+
+	window := deniedByIP[ip]
+	now := time.Now()
+	if window == nil || now.Sub(window.windowStart) > deniedAlertWindow {
+		window = &deniedWindow{windowStart: now}
+		deniedByIP[ip] = window
+	}
+	window.count++
+	if window.count >= deniedAlertThreshold && !window.alerted {
+		window.alerted = true
+		EmitEvent(EventAccessDenied, map[string]interface{}{
+			"ip":    ip,
+			"count": window.count,
+		})
+	}
+}
+
+// noteAccessOrigin records first sightings of public addresses. Private
+// and loopback clients are ignored - alerting on every LAN device would
+// be noise. The very first recorded origin is treated as the owner's
+// own address and sets the baseline without alerting.
+func noteAccessOrigin(db *sql.DB, ip string) {
+	if isPrivateIP(ip) {
+		return
+	}
+
+	anomalyMu.Lock()
+	if knownOrigins[ip] {
+		anomalyMu.Unlock()
+		return
+	}
+	knownOrigins[ip] = true
+	anomalyMu.Unlock()
+
+	// Everything from here can block on the database or a geolocation
+	// provider, so it runs off the request path
+	go func() {
+		logger := logging.Module("anomaly")
+		now := time.Now().UTC().Format(time.RFC3339)
+
+		var seen int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM access_origins WHERE ip = ?`, ip).Scan(&seen); err != nil {
+			logger.Warn("Failed to check access origin", "ip", ip, "error", err)
+			return
+		}
+		if seen > 0 {
+			if _, err := db.Exec(`UPDATE access_origins SET last_seen = ? WHERE ip = ?`, now, ip); err != nil {
+				logger.Warn("Failed to touch access origin", "ip", ip, "error", err)
+			}
+			return
+		}
+
+		var total int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM access_origins`).Scan(&total); err != nil {
+			logger.Warn("Failed to count access origins", "ip", ip, "error", err)
+			return
+		}
+
+		country := countryForIP(ip)
+		newCountry := false
+		if country != "" {
+			var sameCountry int
+			if err := db.QueryRow(`SELECT COUNT(*) FROM access_origins WHERE country = ?`, country).Scan(&sameCountry); err == nil {
+				newCountry = sameCountry == 0
+			}
+		}
+
+		_, err := db.Exec(`
+			INSERT OR IGNORE INTO access_origins (ip, country, first_seen, last_seen)
+			VALUES (?, ?, ?, ?)`, ip, country, now, now)
+		if err != nil {
+			logger.Warn("Failed to record access origin", "ip", ip, "error", err)
+			return
+		}
+
+		// The first origin ever is the baseline, not an anomaly
+		if total == 0 {
+			return
+		}
+		EmitEvent(EventAccessNewOrigin, map[string]interface{}{
+			"ip":          ip,
+			"country":     country,
+			"new_country": newCountry,
+		})
+	}()
+}
+
+// countryForIP resolves an address's country with the same fallbacks as
+// the location endpoint: local GeoIP database first, then the online
+// providers unless policy forbids outbound calls
+func countryForIP(ip string) string {
+	if location := lookupGeoIP(ip); location != nil {
+		return location.Country
+	}
+	if offlineMode() || !config.Get().GeoIP.OnlineFallback {
+		return ""
+	}
+	if location := geoCacheGet(ip); location != nil {
+		return location.Country
+	}
+	for _, provider := range geoProviders() {
+		location, err := provider.Lookup(ip)
+		if err != nil {
+			continue
+		}
+		geoCacheSet(ip, location)
+		return location.Country
+	}
+	return ""
+}
+
+// ListAccessOriginsHandler lists every address that has reached the
+// API, newest first, for the security view
+func ListAccessOriginsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := db.Query(`
+			SELECT ip, country, first_seen, last_seen
+			FROM access_origins ORDER BY first_seen DESC`)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		type origin struct {
+			IP        string `json:"ip"`
+			Country   string `json:"country,omitempty"`
+			FirstSeen string `json:"first_seen"`
+			LastSeen  string `json:"last_seen"`
+		}
+		origins := []origin{}
+		for rows.Next() {
+			var o origin
+			if err := rows.Scan(&o.IP, &o.Country, &o.FirstSeen, &o.LastSeen); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			origins = append(origins, o)
+		}
+		c.JSON(http.StatusOK, gin.H{"origins": origins})
+	}
+}
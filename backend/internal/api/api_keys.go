@@ -0,0 +1,132 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/models"
+)
+
+// apiKeyProviders lists the providers the vault accepts and where each
+// key is consumed, so the settings UI can show an audit of what a key
+// unlocks before it is stored
+var apiKeyProviders = map[string]string{
+	"github":       "GitHub API for /version and /changelog release checks (raises the unauthenticated rate limit)",
+	"whisper":      "OpenAI-compatible transcription server configured via whisper.server_url (sent as a Bearer token)",
+	"brave-search": "Reserved for the Brave web search provider; not consumed by any endpoint yet",
+	"elevenlabs":   "Reserved for text-to-speech; not consumed by any endpoint yet",
+	"openai":       "Reserved for image generation; not consumed by any endpoint yet",
+}
+
+// vaultKey returns the stored key for a provider, or empty when the
+// vault has none (or the server hasn't finished starting up)
+func vaultKey(provider string) string {
+	if eventsDB == nil {
+		return ""
+	}
+	key, err := models.GetAPIKey(eventsDB, provider)
+	if err != nil {
+		return ""
+	}
+	return key
+}
+
+// ListAPIKeysHandler returns every known provider with its usage
+// description and, when a key is stored, a hint and last-used time -
+// never the secret itself
+func ListAPIKeysHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stored, err := models.ListAPIKeys(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		byProvider := make(map[string]models.APIKey, len(stored))
+		for _, key := range stored {
+			byProvider[key.Provider] = key
+		}
+
+		type keyStatus struct {
+			Provider   string `json:"provider"`
+			UsedFor    string `json:"used_for"`
+			Configured bool   `json:"configured"`
+			Hint       string `json:"hint,omitempty"`
+			LastUsedAt string `json:"last_used_at,omitempty"`
+			UpdatedAt  string `json:"updated_at,omitempty"`
+		}
+
+		keys := []keyStatus{}
+		for provider, usedFor := range apiKeyProviders {
+			status := keyStatus{Provider: provider, UsedFor: usedFor}
+			if key, ok := byProvider[provider]; ok {
+				status.Configured = true
+				status.Hint = key.Hint
+				status.LastUsedAt = key.LastUsedAt
+				status.UpdatedAt = key.UpdatedAt
+			}
+			keys = append(keys, status)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Provider < keys[j].Provider })
+
+		c.JSON(http.StatusOK, gin.H{"keys": keys})
+	}
+}
+
+// SetAPIKeyRequest carries the secret for one provider
+type SetAPIKeyRequest struct {
+	Key string `json:"key" binding:"required"`
+}
+
+// SetAPIKeyHandler stores a provider key, encrypted at rest. The secret
+// is accepted once and never returned.
+func SetAPIKeyHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider := c.Param("provider")
+		if _, ok := apiKeyProviders[provider]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown provider: " + provider +
+				" (known: " + strings.Join(knownAPIKeyProviders(), ", ") + ")"})
+			return
+		}
+
+		var req SetAPIKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		if err := models.SetAPIKey(db, provider, req.Key); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"provider": provider, "configured": true})
+	}
+}
+
+// DeleteAPIKeyHandler removes a provider's stored key
+func DeleteAPIKeyHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.DeleteAPIKey(db, c.Param("provider")); err != nil {
+			if err.Error() == "key not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "key deleted"})
+	}
+}
+
+// knownAPIKeyProviders returns the accepted provider names sorted
+func knownAPIKeyProviders() []string {
+	providers := make([]string, 0, len(apiKeyProviders))
+	for provider := range apiKeyProviders {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	return providers
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"math/rand"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/models"
+)
+
+// Contestant selection errors, all of them the caller's fault
+var (
+	errModelsIdentical = errors.New("the two models must differ")
+	errModelsCount     = errors.New("models must name exactly two models (or be omitted)")
+	errNotEnoughModels = errors.New("need at least two local models for a random match")
+)
+
+// arenaMatchRequest starts one blind comparison. Models may name the two
+// contestants; otherwise two random local models face off.
+type arenaMatchRequest struct {
+	Prompt string   `json:"prompt" binding:"required"`
+	Models []string `json:"models"`
+}
+
+// ArenaMatchHandler runs a prompt against two models and returns the
+// anonymized responses ("a" and "b"); the models stay hidden until the
+// vote so the comparison stays blind
+func (s *OllamaService) ArenaMatchHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req arenaMatchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		modelA, modelB, err := s.pickContestants(c, req.Models)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Randomize which model answers as "a" so positional bias in the
+		// explicit-models case doesn't skew votes
+		if rand.Intn(2) == 1 {
+			modelA, modelB = modelB, modelA
+		}
+
+		responses := make([]string, 2)
+		for i, model := range []string{modelA, modelB} {
+			stream := false
+			genReq := &ollamaapi.GenerateRequest{Model: model, Prompt: req.Prompt, Stream: &stream}
+			err := s.client.Generate(c.Request.Context(), genReq, func(resp ollamaapi.GenerateResponse) error {
+				responses[i] = resp.Response
+				return nil
+			})
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": "generation failed: " + err.Error()})
+				return
+			}
+		}
+
+		match := &models.ArenaMatch{
+			Prompt:    req.Prompt,
+			ModelA:    modelA,
+			ModelB:    modelB,
+			ResponseA: responses[0],
+			ResponseB: responses[1],
+		}
+		if err := models.CreateArenaMatch(db, match); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"match_id": match.ID,
+			"responses": []gin.H{
+				{"key": "a", "content": match.ResponseA},
+				{"key": "b", "content": match.ResponseB},
+			},
+		})
+	}
+}
+
+// pickContestants resolves the two models for a match: the requested pair,
+// or two random local models
+func (s *OllamaService) pickContestants(c *gin.Context, requested []string) (string, string, error) {
+	if len(requested) == 2 {
+		if requested[0] == requested[1] {
+			return "", "", errModelsIdentical
+		}
+		return requested[0], requested[1], nil
+	}
+	if len(requested) != 0 {
+		return "", "", errModelsCount
+	}
+
+	list, err := s.client.List(c.Request.Context())
+	if err != nil {
+		return "", "", err
+	}
+	if len(list.Models) < 2 {
+		return "", "", errNotEnoughModels
+	}
+
+	i := rand.Intn(len(list.Models))
+	j := rand.Intn(len(list.Models) - 1)
+	if j >= i {
+		j++
+	}
+	return list.Models[i].Name, list.Models[j].Name, nil
+}
+
+// arenaVoteRequest records the user's verdict on a match
+type arenaVoteRequest struct {
+	Winner string `json:"winner" binding:"required,oneof=a b tie"`
+}
+
+// ArenaVoteHandler records a vote, updates the Elo ratings, and reveals
+// which model was which
+func ArenaVoteHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req arenaVoteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "winner must be 'a', 'b', or 'tie'"})
+			return
+		}
+
+		match, err := models.RecordArenaVote(db, c.Param("id"), req.Winner)
+		if err != nil {
+			switch err.Error() {
+			case "match not found":
+				c.JSON(http.StatusNotFound, gin.H{"error": "match not found"})
+			case "match already voted":
+				c.JSON(http.StatusConflict, gin.H{"error": "match already voted"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"winner":  match.Winner,
+			"model_a": match.ModelA,
+			"model_b": match.ModelB,
+		})
+	}
+}
+
+// ArenaLeaderboardHandler returns the local Elo leaderboard
+func ArenaLeaderboardHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		leaderboard, err := models.ArenaLeaderboard(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if leaderboard == nil {
+			leaderboard = []models.ArenaRating{}
+		}
+		c.JSON(http.StatusOK, gin.H{"leaderboard": leaderboard})
+	}
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/models"
+)
+
+// artifactKinds are the accepted artifact types
+var artifactKinds = map[string]bool{"": true, "code": true, "document": true, "svg": true, "html": true}
+
+// maxDiffLines caps how large artifact versions can be before diffing is
+// refused - the line-based LCS is quadratic
+const maxDiffLines = 5000
+
+// artifactRequest is the body for saving an artifact version
+type artifactRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Kind     string `json:"kind"`
+	Language string `json:"language"`
+	Content  string `json:"content" binding:"required"`
+}
+
+// CreateArtifactHandler saves an artifact into a chat; saving an existing
+// name creates the next version
+func CreateArtifactHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chatID := c.Param("id")
+
+		chat, err := models.GetChat(db, chatID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if chat == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+			return
+		}
+
+		var req artifactRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+		if !artifactKinds[req.Kind] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be code, document, svg, or html"})
+			return
+		}
+
+		artifact := &models.Artifact{
+			ChatID:   chatID,
+			Name:     req.Name,
+			Kind:     req.Kind,
+			Language: req.Language,
+			Content:  req.Content,
+		}
+		if err := models.CreateArtifactVersion(db, artifact); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, artifact)
+	}
+}
+
+// ListArtifactsHandler returns the latest version of each artifact in a
+// chat, or the full version history of one with ?name=
+func ListArtifactsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chatID := c.Param("id")
+
+		if name := c.Query("name"); name != "" {
+			versions, err := models.ListArtifactVersions(db, chatID, name)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if versions == nil {
+				versions = []models.Artifact{}
+			}
+			c.JSON(http.StatusOK, gin.H{"versions": versions})
+			return
+		}
+
+		artifacts, err := models.ListChatArtifacts(db, chatID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if artifacts == nil {
+			artifacts = []models.Artifact{}
+		}
+		c.JSON(http.StatusOK, gin.H{"artifacts": artifacts})
+	}
+}
+
+// GetArtifactHandler returns one artifact version with content
+func GetArtifactHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		artifact, err := models.GetArtifact(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if artifact == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "artifact not found"})
+			return
+		}
+		c.JSON(http.StatusOK, artifact)
+	}
+}
+
+// DownloadArtifactHandler serves an artifact version as a file download
+func DownloadArtifactHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		artifact, err := models.GetArtifact(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if artifact == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "artifact not found"})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", artifact.Name))
+		c.Data(http.StatusOK, "application/octet-stream", []byte(artifact.Content))
+	}
+}
+
+// DiffArtifactHandler diffs an artifact version against another version of
+// the same artifact (?against=N, default the previous version)
+func DiffArtifactHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		artifact, err := models.GetArtifact(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if artifact == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "artifact not found"})
+			return
+		}
+
+		against := artifact.Version - 1
+		if againstStr := c.Query("against"); againstStr != "" {
+			if n, err := strconv.Atoi(againstStr); err == nil {
+				against = n
+			}
+		}
+		if against < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no earlier version to diff against"})
+			return
+		}
+
+		other, err := models.GetArtifactVersion(db, artifact.ChatID, artifact.Name, against)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if other == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("version %d not found", against)})
+			return
+		}
+
+		diff, err := diffLines(other.Content, artifact.Content)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"name": artifact.Name,
+			"from": other.Version,
+			"to":   artifact.Version,
+			"diff": diff,
+		})
+	}
+}
+
+// ReopenArtifactHandler inserts an artifact back into its chat as a user
+// message, so the model can pick it up as context again
+func ReopenArtifactHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		artifact, err := models.GetArtifact(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if artifact == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "artifact not found"})
+			return
+		}
+
+		fence := "```" + artifact.Language
+		msg := &models.Message{
+			ChatID: artifact.ChatID,
+			Role:   "user",
+			Content: fmt.Sprintf("Here is %s (version %d):\n\n%s\n%s\n```",
+				artifact.Name, artifact.Version, fence, artifact.Content),
+		}
+		if err := models.CreateMessage(db, msg); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, msg)
+	}
+}
+
+// diffLines produces a minimal unified-style line diff (LCS-based) between
+// two texts: " " context, "-" removed, "+" added
+func diffLines(before, after string) (string, error) {
+	a := strings.Split(before, "\n")
+	b := strings.Split(after, "\n")
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return "", fmt.Errorf("artifact too large to diff (over %d lines)", maxDiffLines)
+	}
+
+	// Longest-common-subsequence table
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out.WriteString("- " + a[i] + "\n")
+	}
+	for ; j < len(b); j++ {
+		out.WriteString("+ " + b[j] + "\n")
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// transcribeTimeout bounds one background transcription run
+const transcribeTimeout = 10 * time.Minute
+
+// isAudioAttachment reports whether an attachment should go through the
+// transcription pipeline
+func isAudioAttachment(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "audio/")
+}
+
+// transcribeAttachmentAsync transcribes an audio attachment in the
+// background and stores the transcript on its row. Failures are logged;
+// the attachment simply stays without a transcript.
+func transcribeAttachmentAsync(db *sql.DB, attachmentID string, data []byte, filename string) {
+	if !transcriptionAvailable() {
+		return
+	}
+
+	go func() {
+		logger := logging.Module("transcribe")
+
+		ctx, cancel := context.WithTimeout(context.Background(), transcribeTimeout)
+		defer cancel()
+
+		transcript, err := transcribeAudioData(ctx, data, filename)
+		if err != nil {
+			logger.Warn("Attachment transcription failed", "attachmentId", attachmentID, "error", err)
+			return
+		}
+		if transcript == "" {
+			return
+		}
+
+		if err := models.SetAttachmentTranscript(db, attachmentID, transcript); err != nil {
+			logger.Warn("Failed to store transcript", "attachmentId", attachmentID, "error", err)
+			return
+		}
+		logger.Info("Transcribed audio attachment", "attachmentId", attachmentID, "chars", len(transcript))
+	}()
+}
+
+// ListChatAttachmentsHandler returns attachment metadata and transcripts
+// for every message in a chat (no file data)
+func ListChatAttachmentsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		attachments, err := models.ListChatAttachments(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+	}
+}
+
+// maxThumbnailSize caps the requested thumbnail edge length
+const maxThumbnailSize = 1024
+
+// DownloadAttachmentHandler serves an attachment's file data on demand,
+// keeping it out of chat payloads. Attachments are immutable, so responses
+// carry long-lived caching headers; ?thumb=N returns images scaled down to
+// fit an N-pixel square.
+func DownloadAttachmentHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		// Immutable content: the ID is the ETag, so a revalidating client
+		// never re-downloads
+		if match := c.GetHeader("If-None-Match"); match == `"`+id+`"` {
+			c.Status(http.StatusNotModified)
+			return
+		}
+
+		attachment, err := models.GetAttachment(db, id)
+		if err != nil {
+			if err.Error() == "attachment not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		data := attachment.Data
+		mimeType := attachment.MimeType
+
+		if thumbStr := c.Query("thumb"); thumbStr != "" && strings.HasPrefix(mimeType, "image/") {
+			size, err := strconv.Atoi(thumbStr)
+			if err != nil || size <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "thumb must be a positive number"})
+				return
+			}
+			if size > maxThumbnailSize {
+				size = maxThumbnailSize
+			}
+			if scaled, scaledType, err := thumbnailImage(data, size); err == nil {
+				data = scaled
+				mimeType = scaledType
+			}
+			// On decode failure the original image is served as-is
+		}
+
+		c.Header("Cache-Control", "private, max-age=31536000, immutable")
+		c.Header("ETag", `"`+id+`"`)
+		if attachment.Filename != "" {
+			c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", attachment.Filename))
+		}
+		c.Data(http.StatusOK, mimeType, data)
+	}
+}
+
+// thumbnailImage decodes an image and scales it down to fit a size-pixel
+// square, preserving aspect ratio; images already small enough come back
+// unchanged. PNG keeps alpha; everything else re-encodes as JPEG.
+func thumbnailImage(data []byte, size int) ([]byte, string, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return data, "image/" + format, nil
+	}
+
+	scale := float64(size) / float64(width)
+	if height > width {
+		scale = float64(size) / float64(height)
+	}
+	newWidth := int(float64(width) * scale)
+	newHeight := int(float64(height) * scale)
+	if newWidth < 1 {
+		newWidth = 1
+	}
+	if newHeight < 1 {
+		newHeight = 1
+	}
+
+	// Nearest-neighbor sampling; fine for previews and avoids an imaging
+	// dependency
+	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		srcY := bounds.Min.Y + y*height/newHeight
+		for x := 0; x < newWidth; x++ {
+			srcX := bounds.Min.X + x*width/newWidth
+			dst.Set(x, y, src.At(srcX, srcY))
+		}
+	}
+
+	var buf bytes.Buffer
+	if format == "png" {
+		if err := png.Encode(&buf, dst); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	}
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/jpeg", nil
+}
+
+// defaultMediaPageSize bounds the global media gallery page when the
+// client doesn't ask for a specific limit
+const defaultMediaPageSize = 50
+
+// ChatMediaHandler lists the image and audio attachments of one chat in
+// conversation order, optionally filtered with ?type=image or
+// ?type=audio, so clients can build a gallery without walking messages
+func ChatMediaHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		media, err := models.ListChatMedia(db, c.Param("id"), c.Query("type"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"media": media})
+	}
+}
+
+// MediaHandler lists image and audio attachments across all chats,
+// newest first, paginated with ?limit= and ?offset= and filtered with
+// ?type= like the per-chat route
+func MediaHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := defaultMediaPageSize
+		if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+			limit = l
+		}
+		offset := 0
+		if o, err := strconv.Atoi(c.Query("offset")); err == nil && o >= 0 {
+			offset = o
+		}
+
+		media, total, err := models.ListMedia(db, c.Query("type"), limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"media":  media,
+			"total":  total,
+			"limit":  limit,
+			"offset": offset,
+		})
+	}
+}
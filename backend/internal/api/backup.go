@@ -0,0 +1,87 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/database"
+)
+
+// BackupHandler produces a consistent snapshot of the database (VACUUM
+// INTO, so it's safe while the server is running) and streams it back as
+// a download. Attachments are included since they live in the database.
+func BackupHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("vessel-backup-%d.db", time.Now().UnixNano()))
+		defer os.Remove(tmpFile)
+
+		if err := database.BackupTo(db, tmpFile); err != nil {
+			notifyEvent("Vessel backup failed", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		filename := "vessel-backup-" + time.Now().Format("20060102-150405") + ".db"
+		c.FileAttachment(tmpFile, filename)
+
+		EmitEvent(EventBackupCompleted, map[string]interface{}{
+			"filename": filename,
+		})
+	}
+}
+
+// RestoreHandler accepts an uploaded backup, validates it, and stages it
+// next to the live database. The swap itself happens on the next startup -
+// the database file can't be replaced under an open connection pool - so
+// the response tells the operator to restart.
+func RestoreHandler(db *sql.DB, dbPath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var src io.Reader
+
+		// Accept either a multipart upload ("file" field) or a raw body
+		if file, err := c.FormFile("file"); err == nil {
+			opened, err := file.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read upload: " + err.Error()})
+				return
+			}
+			defer opened.Close()
+			src = opened
+		} else {
+			src = c.Request.Body
+		}
+
+		staged := database.StagedRestorePath(dbPath)
+		out, err := os.OpenFile(staged, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to stage restore: " + err.Error()})
+			return
+		}
+
+		if _, err := io.Copy(out, src); err != nil {
+			out.Close()
+			os.Remove(staged)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to write restore file: " + err.Error()})
+			return
+		}
+		out.Close()
+
+		if err := database.ValidateBackup(staged); err != nil {
+			os.Remove(staged)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":  "staged",
+			"message": "backup validated; restart the server to complete the restore",
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/models"
+)
+
+// benchmarkPrompts is the standardized prompt set every benchmark run
+// uses, so results stay comparable across models and quantizations
+var benchmarkPrompts = []string{
+	"Write a haiku about the ocean.",
+	"Explain in two sentences why the sky is blue.",
+	"List the first ten prime numbers, separated by commas.",
+}
+
+// BenchmarkModelHandler runs the standardized prompts against a model and
+// reports tokens/sec, time-to-first-token, and memory footprint. The run
+// is stored so repeated benchmarks can be compared over time.
+func (s *ModelRegistryService) BenchmarkModelHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.ollamaClient == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "ollama client not available"})
+			return
+		}
+
+		model := c.Param("name")
+		ctx := c.Request.Context()
+
+		var (
+			firstTokenTotal time.Duration
+			evalDuration    time.Duration
+			promptTokens    int
+			evalTokens      int
+		)
+		stream := true
+
+		for _, prompt := range benchmarkPrompts {
+			start := time.Now()
+			var firstToken time.Duration
+
+			req := &api.GenerateRequest{Model: model, Prompt: prompt, Stream: &stream}
+			err := s.ollamaClient.Generate(ctx, req, func(resp api.GenerateResponse) error {
+				if firstToken == 0 && resp.Response != "" {
+					firstToken = time.Since(start)
+				}
+				if resp.Done {
+					promptTokens += resp.Metrics.PromptEvalCount
+					evalTokens += resp.Metrics.EvalCount
+					evalDuration += resp.Metrics.EvalDuration
+				}
+				return nil
+			})
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": "benchmark failed: " + err.Error()})
+				return
+			}
+			firstTokenTotal += firstToken
+		}
+
+		tokensPerSecond := 0.0
+		if evalDuration > 0 {
+			tokensPerSecond = float64(evalTokens) / evalDuration.Seconds()
+		}
+
+		benchmark := &models.ModelBenchmark{
+			Model:              model,
+			Prompts:            len(benchmarkPrompts),
+			TokensPerSecond:    tokensPerSecond,
+			TimeToFirstTokenMs: (firstTokenTotal / time.Duration(len(benchmarkPrompts))).Milliseconds(),
+			PromptTokens:       promptTokens,
+			CompletionTokens:   evalTokens,
+			MemoryBytes:        s.modelMemoryFootprint(c, model),
+		}
+		if err := models.SaveModelBenchmark(s.db, benchmark); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, benchmark)
+	}
+}
+
+// ListBenchmarksHandler returns stored benchmark runs for a model
+func (s *ModelRegistryService) ListBenchmarksHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		benchmarks, err := models.ListModelBenchmarks(s.db, c.Param("name"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if benchmarks == nil {
+			benchmarks = []models.ModelBenchmark{}
+		}
+		c.JSON(http.StatusOK, gin.H{"benchmarks": benchmarks})
+	}
+}
+
+// modelMemoryFootprint reads the model's resident size from the list of
+// running models; the benchmark has just run it, so it should be loaded
+func (s *ModelRegistryService) modelMemoryFootprint(c *gin.Context, model string) int64 {
+	running, err := s.ollamaClient.ListRunning(c.Request.Context())
+	if err != nil {
+		return 0
+	}
+	for _, m := range running.Models {
+		if m.Name == model || m.Model == model {
+			if m.SizeVRAM > 0 {
+				return m.SizeVRAM
+			}
+			return m.Size
+		}
+	}
+	return 0
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+)
+
+// ReleaseNote is one release in the changelog response
+type ReleaseNote struct {
+	Version     string `json:"version"`
+	Name        string `json:"name,omitempty"`
+	URL         string `json:"url"`
+	PublishedAt string `json:"publishedAt,omitempty"`
+	Prerelease  bool   `json:"prerelease"`
+	Notes       string `json:"notes"`
+}
+
+// githubReleaseFull extends GitHubRelease with the fields the changelog
+// needs from the releases list API
+type githubReleaseFull struct {
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	HTMLURL     string `json:"html_url"`
+	Body        string `json:"body"`
+	Prerelease  bool   `json:"prerelease"`
+	Draft       bool   `json:"draft"`
+	PublishedAt string `json:"published_at"`
+}
+
+// changelogCache avoids refetching the releases list on every request
+var changelogCache = struct {
+	mu        sync.Mutex
+	releases  []githubReleaseFull
+	fetchedAt time.Time
+}{}
+
+const changelogCacheTTL = 1 * time.Hour
+
+// fetchReleaseList returns recent releases from GitHub, cached for an hour
+func fetchReleaseList() ([]githubReleaseFull, error) {
+	changelogCache.mu.Lock()
+	defer changelogCache.mu.Unlock()
+
+	if changelogCache.releases != nil && time.Since(changelogCache.fetchedAt) < changelogCacheTTL {
+		return changelogCache.releases, nil
+	}
+
+	url := "https://api.github.com/repos/" + getGitHubRepo() + "/releases?per_page=20"
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "Vessel-Update-Checker")
+	if key := vaultKey("github"); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	client := newOutboundClient(10 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return []githubReleaseFull{}, nil
+	}
+
+	var releases []githubReleaseFull
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, err
+	}
+
+	changelogCache.releases = releases
+	changelogCache.fetchedAt = time.Now()
+	return releases, nil
+}
+
+// ChangelogHandler returns the release notes for every version newer than
+// the running one, so the update notification can show what actually
+// changed. The updates.channel config ("stable" or "prerelease") decides
+// whether pre-releases count.
+func ChangelogHandler(currentVersion string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if offlineMode() {
+			abortOffline(c)
+			return
+		}
+
+		releases, err := fetchReleaseList()
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "failed to fetch releases: " + err.Error()})
+			return
+		}
+
+		channel := config.Get().Updates.Channel
+		includePrereleases := channel == "prerelease"
+
+		notes := []ReleaseNote{}
+		for _, release := range releases {
+			if release.Draft {
+				continue
+			}
+			if release.Prerelease && !includePrereleases {
+				continue
+			}
+			version := release.TagName
+			if !compareVersions(currentVersion, version) {
+				continue
+			}
+			notes = append(notes, ReleaseNote{
+				Version:     version,
+				Name:        release.Name,
+				URL:         release.HTMLURL,
+				PublishedAt: release.PublishedAt,
+				Prerelease:  release.Prerelease,
+				Notes:       release.Body,
+			})
+		}
+
+		// Newest first
+		sort.Slice(notes, func(i, j int) bool {
+			return compareVersions(notes[j].Version, notes[i].Version)
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"current":  currentVersion,
+			"channel":  channel,
+			"releases": notes,
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"vessel-backend/internal/logging"
 	"vessel-backend/internal/models"
 )
 
@@ -14,8 +15,10 @@ import (
 func ListChatsHandler(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		includeArchived := c.Query("include_archived") == "true"
+		workspace := c.Query("workspace")
+		language := c.Query("language")
 
-		chats, err := models.ListChats(db, includeArchived)
+		chats, err := models.ListChats(db, workspace, language, includeArchived)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -34,6 +37,8 @@ func ListChatsHandler(db *sql.DB) gin.HandlerFunc {
 func ListGroupedChatsHandler(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		search := c.Query("search")
+		workspace := c.Query("workspace")
+		language := c.Query("language")
 		includeArchived := c.Query("include_archived") == "true"
 
 		limit := 0
@@ -50,7 +55,7 @@ func ListGroupedChatsHandler(db *sql.DB) gin.HandlerFunc {
 			}
 		}
 
-		response, err := models.ListChatsGrouped(db, search, includeArchived, limit, offset)
+		response, err := models.ListChatsGrouped(db, search, workspace, language, includeArchived, limit, offset)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -82,8 +87,10 @@ func GetChatHandler(db *sql.DB) gin.HandlerFunc {
 
 // CreateChatRequest represents the request body for creating a chat
 type CreateChatRequest struct {
-	Title string `json:"title"`
-	Model string `json:"model"`
+	Title       string  `json:"title"`
+	Model       string  `json:"model"`
+	PersonaID   *string `json:"persona_id,omitempty"`
+	WorkspaceID *string `json:"workspace_id,omitempty"`
 }
 
 // CreateChatHandler returns a handler for creating a new chat
@@ -96,8 +103,44 @@ func CreateChatHandler(db *sql.DB) gin.HandlerFunc {
 		}
 
 		chat := &models.Chat{
-			Title: req.Title,
-			Model: req.Model,
+			Title:       req.Title,
+			Model:       req.Model,
+			PersonaID:   req.PersonaID,
+			WorkspaceID: req.WorkspaceID,
+		}
+
+		// Workspace chats must exist in a real workspace and respect its
+		// model allowlist
+		if req.WorkspaceID != nil {
+			workspace, err := models.GetWorkspace(db, *req.WorkspaceID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if workspace == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "workspace not found"})
+				return
+			}
+			if chat.Model != "" && !workspace.ModelAllowed(chat.Model) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "model not allowed in this workspace"})
+				return
+			}
+		}
+
+		// Creating a chat "as" a persona inherits its default model
+		if req.PersonaID != nil {
+			persona, err := models.GetPersona(db, *req.PersonaID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if persona == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "persona not found"})
+				return
+			}
+			if chat.Model == "" {
+				chat.Model = persona.Model
+			}
 		}
 
 		if chat.Title == "" {
@@ -109,6 +152,12 @@ func CreateChatHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		EmitEvent(EventChatCreated, map[string]interface{}{
+			"chat_id": chat.ID,
+			"title":   chat.Title,
+			"model":   chat.Model,
+		})
+
 		c.JSON(http.StatusCreated, chat)
 	}
 }
@@ -177,6 +226,10 @@ func DeleteChatHandler(db *sql.DB) gin.HandlerFunc {
 				c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
 				return
 			}
+			if err.Error() == "chat is locked" {
+				c.JSON(http.StatusConflict, gin.H{"error": "chat is locked - unlock it before deleting"})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -185,12 +238,54 @@ func DeleteChatHandler(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
+// LockChatRequest represents the request body for locking or unlocking
+// a chat
+type LockChatRequest struct {
+	Locked bool `json:"locked"`
+}
+
+// LockChatHandler marks a chat as locked (legal hold). Locked chats are
+// refused by deletes and cold storage sweeps until explicitly unlocked
+// with {"locked": false}.
+func LockChatHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		var req LockChatRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		if err := models.SetChatLocked(db, id, req.Locked); err != nil {
+			if err.Error() == "chat not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"id": id, "locked": req.Locked})
+	}
+}
+
+// AttachmentUpload is one attachment in a message creation request; Data
+// is base64-encoded in JSON
+type AttachmentUpload struct {
+	MimeType string `json:"mime_type"`
+	Filename string `json:"filename"`
+	Data     []byte `json:"data"`
+}
+
 // CreateMessageRequest represents the request body for creating a message
 type CreateMessageRequest struct {
-	ParentID     *string `json:"parent_id,omitempty"`
-	Role         string  `json:"role" binding:"required"`
-	Content      string  `json:"content" binding:"required"`
-	SiblingIndex int     `json:"sibling_index"`
+	ParentID     *string            `json:"parent_id,omitempty"`
+	Role         string             `json:"role" binding:"required"`
+	Content      string             `json:"content" binding:"required"`
+	SiblingIndex int                `json:"sibling_index"`
+	Attachments  []AttachmentUpload `json:"attachments,omitempty"`
+	Generation   *GenerationParams  `json:"generation,omitempty"`
 }
 
 // CreateMessageHandler returns a handler for creating a new message
@@ -234,6 +329,52 @@ func CreateMessageHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		// The first user message fixes the chat's primary language
+		if req.Role == "user" && chat.Language == "" {
+			if lang := detectLanguage(req.Content); lang != "" {
+				if err := models.SetChatLanguage(db, chatID, lang); err != nil {
+					logging.Module("chats").Warn("Failed to set chat language", "chatId", chatID, "error", err)
+				}
+			}
+		}
+
+		// Store attachments; audio ones go through the background
+		// transcription pipeline
+		for _, upload := range req.Attachments {
+			attachment := &models.Attachment{
+				MessageID: msg.ID,
+				MimeType:  upload.MimeType,
+				Filename:  upload.Filename,
+				Data:      upload.Data,
+			}
+			if err := models.CreateAttachment(db, attachment); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if isAudioAttachment(upload.MimeType) {
+				transcribeAttachmentAsync(db, attachment.ID, upload.Data, upload.Filename)
+			}
+			attachment.Data = nil
+			msg.Attachments = append(msg.Attachments, *attachment)
+		}
+
+		// Remember the parameters behind assistant messages so they can
+		// be replayed later
+		if msg.Role == "assistant" && req.Generation != nil {
+			if err := models.SetMessageGeneration(db, msg.ID, req.Generation.Model, req.Generation.Options); err != nil {
+				logging.Module("chats").Warn("Failed to record message generation", "messageId", msg.ID, "error", err)
+			}
+		}
+
+		// A stored assistant message marks a finished model turn
+		if msg.Role == "assistant" {
+			EmitEvent(EventMessageCompleted, map[string]interface{}{
+				"chat_id":    msg.ChatID,
+				"message_id": msg.ID,
+				"model":      chat.Model,
+			})
+		}
+
 		c.JSON(http.StatusCreated, msg)
 	}
 }
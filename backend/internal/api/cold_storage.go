@@ -0,0 +1,365 @@
+package api
+
+import (
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/models"
+)
+
+// Cold storage moves old chats out of the live SQLite database into
+// compressed archive files on disk, keeping the hot DB small for sync
+// and listing. Each chat becomes one gzipped JSON document next to the
+// database; the archived_chats table indexes them for search and
+// restore.
+const coldArchiveFormat = "vessel-chat-archive"
+const coldArchiveFormatVersion = 1
+
+// coldArchive is the on-disk document for one archived chat
+type coldArchive struct {
+	Format     string      `json:"format"`
+	Version    int         `json:"version"`
+	ArchivedAt string      `json:"archivedAt"`
+	Chat       models.Chat `json:"chat"`
+}
+
+// archiveDir returns the cold storage directory, creating it on demand
+func archiveDir() (string, error) {
+	dir := filepath.Join(filepath.Dir(config.Get().Database.Path), "archives")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// ArchivedChatEntry is one row of the cold storage index
+type ArchivedChatEntry struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Model      string `json:"model"`
+	Messages   int    `json:"messages"`
+	File       string `json:"file"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+	ArchivedAt string `json:"archived_at"`
+}
+
+// ArchiveChatsRequest selects which chats move to cold storage: an
+// explicit ID list, or every unpinned chat not touched since Before
+// (RFC3339 or 2006-01-02)
+type ArchiveChatsRequest struct {
+	Before string   `json:"before"`
+	Chats  []string `json:"chats"`
+}
+
+// ArchiveChatsHandler moves the selected chats (messages and
+// attachments included) into compressed archive files and deletes them
+// from the live database
+func ArchiveChatsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ArchiveChatsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		ids := req.Chats
+		if len(ids) == 0 {
+			if req.Before == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "either chats or before is required"})
+				return
+			}
+			cutoff, err := parseExportDate(req.Before)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid before date: " + err.Error()})
+				return
+			}
+			rows, err := db.Query(`SELECT id FROM chats WHERE pinned = 0 AND locked = 0 AND updated_at < ?`,
+				cutoff.UTC().Format(time.RFC3339))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			for rows.Next() {
+				var id string
+				if err := rows.Scan(&id); err == nil {
+					ids = append(ids, id)
+				}
+			}
+			rows.Close()
+		}
+
+		archived := 0
+		for _, id := range ids {
+			if err := archiveChatToFile(db, id); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":    "failed to archive chat " + id + ": " + err.Error(),
+					"archived": archived,
+				})
+				return
+			}
+			archived++
+		}
+
+		c.JSON(http.StatusOK, gin.H{"archived": archived})
+	}
+}
+
+// archiveChatToFile writes one chat to cold storage and removes it from
+// the live database once the file is safely on disk
+func archiveChatToFile(db *sql.DB, chatID string) error {
+	chat, err := models.GetChat(db, chatID)
+	if err != nil {
+		return err
+	}
+	if chat == nil {
+		return fmt.Errorf("chat not found")
+	}
+	if chat.Locked {
+		return fmt.Errorf("chat is locked")
+	}
+
+	messages, err := models.GetMessagesByChatID(db, chatID)
+	if err != nil {
+		return err
+	}
+	for i := range messages {
+		rows, err := db.Query(`SELECT id, mime_type, filename, transcript, data FROM attachments WHERE message_id = ?`,
+			messages[i].ID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var att models.Attachment
+			var data []byte
+			if err := rows.Scan(&att.ID, &att.MimeType, &att.Filename, &att.Transcript, &data); err != nil {
+				rows.Close()
+				return err
+			}
+			att.MessageID = messages[i].ID
+			att.Data = models.DecodeAttachmentData(data)
+			messages[i].Attachments = append(messages[i].Attachments, att)
+		}
+		rows.Close()
+	}
+	chat.Messages = messages
+
+	dir, err := archiveDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, chatID+".json.gz")
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(file)
+	doc := coldArchive{
+		Format:     coldArchiveFormat,
+		Version:    coldArchiveFormatVersion,
+		ArchivedAt: time.Now().UTC().Format(time.RFC3339),
+		Chat:       *chat,
+	}
+	if err := json.NewEncoder(gz).Encode(doc); err != nil {
+		gz.Close()
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT OR REPLACE INTO archived_chats (id, title, model, messages, file, created_at, updated_at, archived_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		chat.ID, chat.Title, chat.Model, len(messages), path,
+		chat.CreatedAt.Format(time.RFC3339), chat.UpdatedAt.Format(time.RFC3339),
+		doc.ArchivedAt,
+	)
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	// Cascades to messages and attachments
+	if err := models.DeleteChat(db, chatID); err != nil {
+		db.Exec(`DELETE FROM archived_chats WHERE id = ?`, chatID)
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// ListArchivedChatsHandler searches the cold storage index (?q= matches
+// the title, ?limit= caps results)
+func ListArchivedChatsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 100
+		if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+			limit = l
+		}
+
+		query := `SELECT id, title, model, messages, file, created_at, updated_at, archived_at
+			FROM archived_chats`
+		args := []interface{}{}
+		if q := c.Query("q"); q != "" {
+			query += ` WHERE title LIKE ?`
+			args = append(args, "%"+q+"%")
+		}
+		query += ` ORDER BY archived_at DESC LIMIT ?`
+		args = append(args, limit)
+
+		rows, err := db.Query(query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		entries := []ArchivedChatEntry{}
+		for rows.Next() {
+			var e ArchivedChatEntry
+			if err := rows.Scan(&e.ID, &e.Title, &e.Model, &e.Messages, &e.File,
+				&e.CreatedAt, &e.UpdatedAt, &e.ArchivedAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			entries = append(entries, e)
+		}
+		c.JSON(http.StatusOK, gin.H{"archived": entries})
+	}
+}
+
+// RestoreArchivedChatHandler moves a chat from cold storage back into
+// the live database with its original IDs, then drops the archive file
+// and index row
+func RestoreArchivedChatHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		var path string
+		err := db.QueryRow(`SELECT file FROM archived_chats WHERE id = ?`, id).Scan(&path)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "archived chat not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open archive file: " + err.Error()})
+			return
+		}
+		defer file.Close()
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read archive file: " + err.Error()})
+			return
+		}
+		defer gz.Close()
+
+		var doc coldArchive
+		if err := json.NewDecoder(gz).Decode(&doc); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decode archive file: " + err.Error()})
+			return
+		}
+		if doc.Format != coldArchiveFormat || doc.Version > coldArchiveFormatVersion {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "unsupported archive format"})
+			return
+		}
+
+		if err := restoreArchivedChat(db, &doc.Chat); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		db.Exec(`DELETE FROM archived_chats WHERE id = ?`, id)
+		os.Remove(path)
+
+		c.JSON(http.StatusOK, gin.H{"restored": doc.Chat.ID, "messages": len(doc.Chat.Messages)})
+	}
+}
+
+// restoreArchivedChat inserts the chat tree back with its original IDs;
+// a chat that somehow still exists in the live database is an error
+// rather than a silent overwrite
+func restoreArchivedChat(db *sql.DB, chat *models.Chat) error {
+	var exists int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM chats WHERE id = ?`, chat.ID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists > 0 {
+		return fmt.Errorf("chat %s already exists in the live database", chat.ID)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO chats (id, title, model, pinned, archived, system_prompt_id, persona_id, workspace_id, language, created_at, updated_at, sync_version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1)`,
+		chat.ID, chat.Title, chat.Model, chat.Pinned, chat.Archived,
+		chat.SystemPromptID, chat.PersonaID, chat.WorkspaceID, chat.Language,
+		chat.CreatedAt.Format(time.RFC3339), chat.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore chat: %w", err)
+	}
+
+	for _, msg := range chat.Messages {
+		storedContent, err := models.EncodeMessageContent(msg.Content)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt message content: %w", err)
+		}
+		_, err = tx.Exec(`
+			INSERT INTO messages (id, chat_id, parent_id, role, content, sibling_index, created_at, sync_version)
+			VALUES (?, ?, ?, ?, ?, ?, ?, 1)`,
+			msg.ID, chat.ID, msg.ParentID, msg.Role, storedContent,
+			msg.SiblingIndex, msg.CreatedAt.Format(time.RFC3339),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to restore message: %w", err)
+		}
+
+		for _, att := range msg.Attachments {
+			stored, err := models.EncodeAttachmentData(att.Data)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt attachment: %w", err)
+			}
+			_, err = tx.Exec(`
+				INSERT INTO attachments (id, message_id, mime_type, data, filename, transcript)
+				VALUES (?, ?, ?, ?, ?, ?)`,
+				att.ID, msg.ID, att.MimeType, stored, att.Filename, att.Transcript,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to restore attachment: %w", err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
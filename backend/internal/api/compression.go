@@ -0,0 +1,153 @@
+package api
+
+import (
+	"compress/gzip"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gzipMinSize is the smallest response worth compressing; tiny JSON bodies
+// cost more in gzip framing than they save
+const gzipMinSize = 1024
+
+// compressibleContentTypes lists content type prefixes that benefit from
+// compression. Streaming types (ndjson, SSE) are deliberately absent - they
+// must flush token-by-token and buffering them breaks streaming.
+var compressibleContentTypes = []string{
+	"application/json",
+	"text/html",
+	"text/plain",
+	"text/css",
+	"application/javascript",
+	"text/javascript",
+	"image/svg+xml",
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() any {
+		writer, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return writer
+	},
+}
+
+// CompressionMiddleware gzips JSON and other text responses when the client
+// accepts it. The decision is made on the first write, based on the response
+// content type, so streaming endpoints (chat ndjson, pull progress) pass
+// through untouched. Bodies are buffered up to gzipMinSize first so small
+// responses skip compression entirely.
+func CompressionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+			c.Next()
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = gw
+		defer gw.close()
+
+		c.Next()
+	}
+}
+
+// gzipResponseWriter wraps the gin writer and decides lazily whether to
+// compress, once the content type is known
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	gz      *gzip.Writer
+	buf     []byte
+	decided bool
+	skipped bool
+}
+
+// shouldCompress reports whether the response, as currently headed, is a
+// candidate for gzip
+func (w *gzipResponseWriter) shouldCompress() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	for _, prefix := range compressibleContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *gzipResponseWriter) startCompressing() {
+	header := w.Header()
+	header.Set("Content-Encoding", "gzip")
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+
+	w.gz = gzipWriterPool.Get().(*gzip.Writer)
+	w.gz.Reset(w.ResponseWriter)
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if w.skipped {
+		return w.ResponseWriter.Write(data)
+	}
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+
+	if !w.decided && !w.shouldCompress() {
+		w.decided = true
+		w.skipped = true
+		return w.ResponseWriter.Write(data)
+	}
+	w.decided = true
+
+	// Buffer until we know the body is big enough to be worth compressing
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= gzipMinSize {
+		w.startCompressing()
+		if _, err := w.gz.Write(w.buf); err != nil {
+			return 0, err
+		}
+		w.buf = nil
+	}
+	return len(data), nil
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush is called by streaming handlers between chunks. A handler that
+// flushes wants real-time delivery, so give up on compression and drain
+// whatever was buffered.
+func (w *gzipResponseWriter) Flush() {
+	if w.gz == nil && !w.skipped {
+		w.skipped = true
+		w.decided = true
+		if len(w.buf) > 0 {
+			w.ResponseWriter.Write(w.buf) //nolint:errcheck
+			w.buf = nil
+		}
+	}
+	if w.gz != nil {
+		w.gz.Flush() //nolint:errcheck
+	}
+	w.ResponseWriter.Flush()
+}
+
+// close finishes the response: small buffered bodies go out uncompressed,
+// compressed streams are terminated and the gzip writer recycled
+func (w *gzipResponseWriter) close() {
+	if len(w.buf) > 0 {
+		w.ResponseWriter.Write(w.buf) //nolint:errcheck
+		w.buf = nil
+	}
+	if w.gz != nil {
+		w.gz.Close() //nolint:errcheck
+		gzipWriterPool.Put(w.gz)
+		w.gz = nil
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// contentBudgetSeparator marks where non-adjacent sections were joined,
+// so the model knows material was elided between them
+const contentBudgetSeparator = "\n[…]\n"
+
+// extractRelevantContent trims oversized tool output to the budget by
+// relevance instead of cutting it off mid-sentence: the content is
+// chunked, chunks and question are embedded, and the highest-scoring
+// chunks are kept (in document order) until the budget is spent
+func (s *OllamaService) extractRelevantContent(ctx context.Context, embedModel, question, content string, budget int) (string, error) {
+	if budget <= 0 || len(content) <= budget {
+		return content, nil
+	}
+
+	// No overlap: selected neighbours are concatenated back together,
+	// which has to reproduce the original text exactly
+	chunks := chunkForEval(content, ragEvalDefaultChunkSize, 0)
+	if len(chunks) == 0 {
+		return content, nil
+	}
+
+	inputs := append([]string{question}, chunks...)
+	vectors, err := s.embedInBatches(ctx, embedModel, inputs)
+	if err != nil {
+		return "", fmt.Errorf("failed to embed content for budgeting: %w", err)
+	}
+	questionVector := vectors[0]
+	chunkVectors := vectors[1:]
+
+	// Rank chunks by similarity, then take from the top until the
+	// budget is spent
+	order := make([]int, len(chunks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return cosineSimilarity(chunkVectors[order[a]], questionVector) >
+			cosineSimilarity(chunkVectors[order[b]], questionVector)
+	})
+
+	selected := map[int]bool{}
+	used := 0
+	for _, index := range order {
+		cost := len(chunks[index]) + len(contentBudgetSeparator)
+		if used+cost > budget {
+			continue
+		}
+		selected[index] = true
+		used += cost
+	}
+	if len(selected) == 0 {
+		// Budget smaller than a single chunk: fall back to a plain cut
+		return content[:budget], nil
+	}
+
+	// Reassemble in document order so the extract still reads top to
+	// bottom
+	parts := []string{}
+	previous := -2
+	for index := 0; index < len(chunks); index++ {
+		if !selected[index] {
+			continue
+		}
+		if index == previous+1 && len(parts) > 0 {
+			parts[len(parts)-1] += chunks[index]
+		} else {
+			parts = append(parts, chunks[index])
+		}
+		previous = index
+	}
+	return strings.Join(parts, contentBudgetSeparator), nil
+}
@@ -0,0 +1,346 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// defaultContextLength is assumed when the registry has no context_length
+// for the target model
+const defaultContextLength = 8192
+
+// contextHistoryShare is the fraction of the context window the message
+// history may occupy; the rest is headroom for the response
+const contextHistoryShare = 0.75
+
+// estTokensPerChar approximates tokens from text length (roughly four
+// characters per token for English-like text)
+const estTokensPerChar = 0.25
+
+// summarizePromptPrefix asks the model to compress dropped history
+const summarizePromptPrefix = "Summarize the following conversation concisely, keeping facts, names, " +
+	"decisions, and open questions that later messages might refer back to:\n\n"
+
+// contextStrategies are the selectable per-chat strategies
+var contextStrategies = map[string]bool{
+	"":                true, // off
+	"truncate-oldest": true,
+	"sliding-window":  true,
+	"summarize":       true,
+}
+
+// applyContextStrategy trims the request's message history according to
+// the chat's configured strategy and the target model's context length
+// from the registry. It returns the name of the strategy it applied, or
+// "" when the history already fit (or no strategy is set).
+func (s *OllamaService) applyContextStrategy(ctx context.Context, db *sql.DB, chatID string, req *ollamaapi.ChatRequest) string {
+	if chatID == "" {
+		return ""
+	}
+	strategy, err := models.GetChatContextStrategy(db, chatID)
+	if err != nil || strategy == "" {
+		return ""
+	}
+
+	budget := int(float64(registryContextLength(db, req.Model)) * contextHistoryShare)
+	if estimateMessagesTokens(req.Messages) <= budget {
+		return ""
+	}
+
+	switch strategy {
+	case "truncate-oldest", "sliding-window":
+		req.Messages = trimOldestMessages(req.Messages, budget, strategy == "sliding-window")
+	case "summarize":
+		req.Messages = s.summarizeAndTrim(ctx, req.Model, req.Messages, budget)
+	default:
+		return ""
+	}
+	return strategy
+}
+
+// registryContextLength looks up the model's context window in the local
+// registry, falling back to a conservative default
+func registryContextLength(db *sql.DB, model string) int {
+	slug := model
+	if idx := strings.Index(slug, ":"); idx >= 0 {
+		slug = slug[:idx]
+	}
+
+	var contextLength int64
+	err := db.QueryRow(`SELECT context_length FROM remote_models WHERE slug = ?`, slug).Scan(&contextLength)
+	if err != nil || contextLength <= 0 {
+		return defaultContextLength
+	}
+	return int(contextLength)
+}
+
+// estimateMessagesTokens approximates the token count of a message list
+func estimateMessagesTokens(messages []ollamaapi.Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += estimateTokens(msg.Content)
+	}
+	return total
+}
+
+// estimateTokens approximates the token count of one string
+func estimateTokens(text string) int {
+	return int(float64(len(text)) * estTokensPerChar)
+}
+
+// splitForTrim separates system messages from the rest and finds the
+// index in rest where the kept window starts: the most recent run of
+// messages fitting the budget. With turnAligned, the window additionally
+// starts at a user message so the model never sees an answer without its
+// question.
+func splitForTrim(messages []ollamaapi.Message, budget int, turnAligned bool) (system, rest []ollamaapi.Message, start int) {
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			system = append(system, msg)
+		} else {
+			rest = append(rest, msg)
+		}
+	}
+
+	budget -= estimateMessagesTokens(system)
+
+	// Walk backwards accumulating the most recent messages that fit
+	start = len(rest)
+	used := 0
+	for i := len(rest) - 1; i >= 0; i-- {
+		cost := estimateTokens(rest[i].Content)
+		if used+cost > budget {
+			break
+		}
+		used += cost
+		start = i
+	}
+
+	if turnAligned {
+		for start < len(rest) && rest[start].Role != "user" {
+			start++
+		}
+	}
+
+	// Never trim away the latest message, whatever the budget says
+	if start >= len(rest) && len(rest) > 0 {
+		start = len(rest) - 1
+	}
+	return system, rest, start
+}
+
+// trimOldestMessages drops history from the front until it fits the
+// budget; system messages always survive
+func trimOldestMessages(messages []ollamaapi.Message, budget int, turnAligned bool) []ollamaapi.Message {
+	system, rest, start := splitForTrim(messages, budget, turnAligned)
+	return append(system, rest[start:]...)
+}
+
+// summarizeAndTrim compresses the history that would be dropped into one
+// system message prepended to the kept window. When summarization fails
+// it degrades to plain truncation.
+func (s *OllamaService) summarizeAndTrim(ctx context.Context, model string, messages []ollamaapi.Message, budget int) []ollamaapi.Message {
+	system, rest, start := splitForTrim(messages, budget, true)
+	kept := append(system, rest[start:]...)
+	dropped := rest[:start]
+	if len(dropped) == 0 {
+		return kept
+	}
+
+	var transcript strings.Builder
+	for _, msg := range dropped {
+		transcript.WriteString(msg.Role + ": " + msg.Content + "\n")
+	}
+
+	stream := false
+	var summary string
+	err := s.client.Generate(ctx, &ollamaapi.GenerateRequest{
+		Model:  model,
+		Prompt: summarizePromptPrefix + transcript.String(),
+		Stream: &stream,
+	}, func(resp ollamaapi.GenerateResponse) error {
+		summary = resp.Response
+		return nil
+	})
+	if err != nil || summary == "" {
+		logging.Module("context").Warn("History summarization failed, truncating instead", "error", err)
+		return kept
+	}
+
+	summaryMsg := ollamaapi.Message{
+		Role:    "system",
+		Content: "Summary of earlier conversation: " + summary,
+	}
+
+	// Insert the summary after any real system messages
+	result := make([]ollamaapi.Message, 0, len(kept)+1)
+	inserted := false
+	for _, msg := range kept {
+		if !inserted && msg.Role != "system" {
+			result = append(result, summaryMsg)
+			inserted = true
+		}
+		result = append(result, msg)
+	}
+	if !inserted {
+		result = append(result, summaryMsg)
+	}
+	return result
+}
+
+// SetContextStrategyRequest selects a chat's context window strategy
+type SetContextStrategyRequest struct {
+	Strategy string `json:"strategy"`
+}
+
+// SetContextStrategyHandler stores the strategy the completion endpoint
+// applies when this chat's history outgrows the model's context window
+func SetContextStrategyHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SetContextStrategyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		if !contextStrategies[req.Strategy] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "strategy must be 'truncate-oldest', 'sliding-window', 'summarize', or empty"})
+			return
+		}
+
+		if err := models.SetChatContextStrategy(db, c.Param("id"), req.Strategy); err != nil {
+			if err.Error() == "chat not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"strategy": req.Strategy})
+	}
+}
+
+// appendContextStrategy injects the applied strategy into an
+// already-marshaled response frame, mirroring appendLatency
+func appendContextStrategy(frame []byte, strategy string) []byte {
+	if strategy == "" {
+		return frame
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(frame, &decoded); err != nil {
+		return frame
+	}
+	decoded["context_strategy"] = strategy
+
+	enriched, err := json.Marshal(decoded)
+	if err != nil {
+		return frame
+	}
+	return enriched
+}
+
+// previewMessage is one message of the assembled payload with its
+// estimated token cost
+type previewMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	Tokens  int    `json:"tokens"`
+}
+
+// previewSection sums one part of the payload (system vs. history)
+type previewSection struct {
+	Name     string `json:"name"`
+	Messages int    `json:"messages"`
+	Tokens   int    `json:"tokens"`
+}
+
+// ContextPreviewHandler shows exactly what a server-side completion for
+// this chat would send to the model: persona system prompt, expanded
+// snippets, and the history after the context strategy ran, with token
+// estimates per section. Sections the client injects itself (memories,
+// RAG chunks) are not visible to the server and won't appear here.
+// ?model= overrides the chat's model for the size calculation.
+func (s *OllamaService) ContextPreviewHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chatID := c.Param("id")
+		chat, err := models.GetChat(db, chatID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if chat == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+			return
+		}
+
+		model := c.Query("model")
+		if model == "" {
+			model = chat.Model
+		}
+
+		// Assemble the request the way ChatHandler would
+		req := &ollamaapi.ChatRequest{Model: model}
+		for _, msg := range chat.Messages {
+			req.Messages = append(req.Messages, ollamaapi.Message{Role: msg.Role, Content: msg.Content})
+		}
+		beforeTokens, beforeCount := 0, 0
+		for _, msg := range req.Messages {
+			if msg.Role != "system" {
+				beforeTokens += estimateTokens(msg.Content)
+				beforeCount++
+			}
+		}
+
+		applyChatSamplingOptions(db, chatID, req)
+		if chat.PersonaID != nil {
+			if err := applyPersona(db, req, *chat.PersonaID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply persona: " + err.Error()})
+				return
+			}
+		}
+		expandSystemSnippets(db, req)
+
+		contextLength := registryContextLength(db, req.Model)
+		budget := int(float64(contextLength) * contextHistoryShare)
+		strategy := s.applyContextStrategy(c.Request.Context(), db, chatID, req)
+
+		messages := make([]previewMessage, 0, len(req.Messages))
+		sections := map[string]*previewSection{
+			"system":  {Name: "system"},
+			"history": {Name: "history"},
+		}
+		total := 0
+		for _, msg := range req.Messages {
+			tokens := estimateTokens(msg.Content)
+			total += tokens
+			section := sections["history"]
+			if msg.Role == "system" {
+				section = sections["system"]
+			}
+			section.Messages++
+			section.Tokens += tokens
+			messages = append(messages, previewMessage{Role: msg.Role, Content: msg.Content, Tokens: tokens})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"model":            req.Model,
+			"context_length":   contextLength,
+			"history_budget":   budget,
+			"strategy":         strategy,
+			"sections":         []previewSection{*sections["system"], *sections["history"]},
+			"messages":         messages,
+			"total_tokens":     total,
+			"dropped_messages": beforeCount - sections["history"].Messages,
+			"dropped_tokens":   beforeTokens - sections["history"].Tokens,
+			"options":          req.Options,
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+)
+
+// minOllamaVersion is the oldest Ollama release the backend is tested
+// against; older ones predate the /api/embed and structured options
+// behavior the handlers rely on
+const minOllamaVersion = "0.3.0"
+
+// diagnosticsTimeout bounds the whole diagnostics run, including the
+// test generation (which may need to load a model into memory)
+const diagnosticsTimeout = 60 * time.Second
+
+// minModelsDirFreeBytes is the free-space level below which the models
+// volume is flagged - a mid-pull disk-full error leaves partial layers
+const minModelsDirFreeBytes = 2 << 30
+
+// diagnosticCheck is one named check with an actionable outcome
+type diagnosticCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // "ok", "warning" or "error"
+	Message string `json:"message,omitempty"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// DiagnosticsHandler runs the "why doesn't chat work" checklist against
+// the configured Ollama instance: version compatibility, installed vs
+// loaded models, free space on the models volume, and a one-token test
+// generation. Every failing check carries a message saying what to do
+// about it.
+func (s *OllamaService) DiagnosticsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), diagnosticsTimeout)
+		defer cancel()
+
+		checks := []diagnosticCheck{}
+		healthy := true
+
+		versionCheck, version := s.checkOllamaVersion(ctx)
+		checks = append(checks, versionCheck)
+		if versionCheck.Status == "error" {
+			// Unreachable: nothing else can run
+			c.JSON(http.StatusOK, gin.H{
+				"healthy": false,
+				"version": version,
+				"checks":  checks,
+			})
+			return
+		}
+
+		modelsCheck, firstModel := s.checkModels(ctx)
+		checks = append(checks, modelsCheck)
+		checks = append(checks, checkModelsDiskSpace())
+
+		if firstModel != "" {
+			checks = append(checks, s.checkGeneration(ctx, firstModel))
+		}
+
+		for _, check := range checks {
+			if check.Status == "error" {
+				healthy = false
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"healthy": healthy,
+			"version": version,
+			"checks":  checks,
+		})
+	}
+}
+
+// checkOllamaVersion verifies the instance is reachable and not older
+// than the minimum supported release
+func (s *OllamaService) checkOllamaVersion(ctx context.Context) (diagnosticCheck, string) {
+	check := diagnosticCheck{Name: "ollama_version", Status: "ok"}
+	version, err := s.client.Version(ctx)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Ollama is unreachable - check that it is running and that the configured URL is correct"
+		check.Detail = err.Error()
+		return check, ""
+	}
+	check.Detail = version
+
+	if compareVersions(version, minOllamaVersion) {
+		check.Status = "warning"
+		check.Message = fmt.Sprintf("Ollama %s is older than the tested minimum %s - upgrade to avoid missing API features", version, minOllamaVersion)
+	}
+	return check, version
+}
+
+// checkModels compares installed models against what is loaded in
+// memory; returns the smallest installed model for the test generation
+func (s *OllamaService) checkModels(ctx context.Context) (diagnosticCheck, string) {
+	check := diagnosticCheck{Name: "models", Status: "ok"}
+
+	list, err := s.client.List(ctx)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Failed to list installed models"
+		check.Detail = err.Error()
+		return check, ""
+	}
+	if len(list.Models) == 0 {
+		check.Status = "error"
+		check.Message = "No models installed - pull one (e.g. 'ollama pull llama3.2') before chatting"
+		return check, ""
+	}
+
+	installed := make([]string, 0, len(list.Models))
+	smallest := list.Models[0]
+	for _, model := range list.Models {
+		installed = append(installed, model.Name)
+		if model.Size < smallest.Size {
+			smallest = model
+		}
+	}
+
+	loaded := []string{}
+	if running, err := s.client.ListRunning(ctx); err == nil {
+		for _, model := range running.Models {
+			loaded = append(loaded, model.Name)
+		}
+	}
+
+	check.Detail = fmt.Sprintf("%d installed (%s), %d loaded", len(installed), strings.Join(installed, ", "), len(loaded))
+	if len(loaded) == 0 {
+		check.Message = "No model is loaded in memory - the first request will pay the load time"
+	}
+	return check, smallest.Name
+}
+
+// checkModelsDiskSpace checks free space on the Ollama models volume.
+// The directory is only knowable when Ollama runs on this host
+// (OLLAMA_MODELS or ~/.ollama/models); remote instances are skipped.
+func checkModelsDiskSpace() diagnosticCheck {
+	check := diagnosticCheck{Name: "models_disk", Status: "ok"}
+
+	dir := os.Getenv("OLLAMA_MODELS")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			check.Message = "Models directory unknown - skipped"
+			return check
+		}
+		dir = filepath.Join(home, ".ollama", "models")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		check.Message = "Models directory not found on this host (remote Ollama?) - skipped"
+		return check
+	}
+
+	free, err := freeDiskBytes(dir)
+	if err != nil {
+		check.Status = "warning"
+		check.Message = "Could not check free space on the models volume"
+		check.Detail = err.Error()
+		return check
+	}
+	check.Detail = fmt.Sprintf("%d MB free", free/(1<<20))
+	if free < minModelsDirFreeBytes {
+		check.Status = "warning"
+		check.Message = "Less than 2GB free on the models volume - pulls may fail partway; remove unused models or free space"
+	}
+	return check
+}
+
+// checkGeneration runs a one-token generation to prove the whole path
+// works, model load included
+func (s *OllamaService) checkGeneration(ctx context.Context, model string) diagnosticCheck {
+	check := diagnosticCheck{Name: "generation", Status: "ok"}
+
+	stream := false
+	start := time.Now()
+	err := s.client.Generate(ctx, &ollamaapi.GenerateRequest{
+		Model:   model,
+		Prompt:  "Hi",
+		Stream:  &stream,
+		Options: map[string]interface{}{"num_predict": 1},
+	}, func(resp ollamaapi.GenerateResponse) error { return nil })
+	if err != nil {
+		check.Status = "error"
+		check.Message = fmt.Sprintf("Test generation with %s failed - check Ollama's logs for loader or memory errors", model)
+		check.Detail = err.Error()
+		return check
+	}
+	check.Detail = fmt.Sprintf("%s answered in %s", model, time.Since(start).Round(time.Millisecond))
+	return check
+}
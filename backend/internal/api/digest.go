@@ -0,0 +1,126 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// digestCheckInterval is how often the scheduler checks whether a digest
+// is due; coarse on purpose since sends happen at most once a day
+const digestCheckInterval = 10 * time.Minute
+
+// StartEmailDigest launches the scheduled activity digest when email and a
+// digest cadence ("daily" or "weekly") are configured. The digest goes out
+// once the configured hour passes - on every day for daily, on Mondays for
+// weekly.
+func StartEmailDigest(db *sql.DB) {
+	cfg := config.Get().Email
+	if cfg.Digest == "" || cfg.DigestTo == "" {
+		return
+	}
+
+	logger := logging.Module("digest")
+
+	if cfg.Digest != "daily" && cfg.Digest != "weekly" {
+		logger.Warn("Ignoring unknown digest cadence", "digest", cfg.Digest)
+		return
+	}
+	if !emailConfigured() {
+		logger.Warn("Email digest disabled: smtp_host and from are required")
+		return
+	}
+
+	go func() {
+		lastSent := ""
+
+		ticker := time.NewTicker(digestCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			now := time.Now()
+			today := now.Format("2006-01-02")
+
+			due := now.Hour() >= cfg.DigestHour && lastSent != today
+			if cfg.Digest == "weekly" {
+				due = due && now.Weekday() == time.Monday
+			}
+
+			if due {
+				if err := sendDigest(db, cfg.Digest, cfg.DigestTo); err != nil {
+					logger.Warn("Failed to send digest", "error", err)
+				} else {
+					logger.Info("Sent activity digest", "to", cfg.DigestTo)
+				}
+				// Mark the day even on failure - retrying every ten minutes
+				// against a broken SMTP server helps nobody
+				lastSent = today
+			}
+
+			<-ticker.C
+		}
+	}()
+}
+
+// sendDigest assembles and emails one activity summary
+func sendDigest(db *sql.DB, cadence, to string) error {
+	window := 24 * time.Hour
+	label := "yesterday"
+	if cadence == "weekly" {
+		window = 7 * 24 * time.Hour
+		label = "last week"
+	}
+	since := time.Now().Add(-window)
+
+	body, err := buildDigestBody(db, since, label)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("Vessel %s digest", cadence)
+	return sendEmail(to, subject, body)
+}
+
+// buildDigestBody summarizes activity since the given time: new chats and
+// messages, and per-model usage from the generation metrics
+func buildDigestBody(db *sql.DB, since time.Time, label string) (string, error) {
+	sinceStr := since.UTC().Format(time.RFC3339)
+
+	var chatCount, messageCount int
+	if err := db.QueryRow("SELECT COUNT(*) FROM chats WHERE created_at >= ?", sinceStr).Scan(&chatCount); err != nil {
+		return "", fmt.Errorf("failed to count chats: %w", err)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM messages WHERE created_at >= ?", sinceStr).Scan(&messageCount); err != nil {
+		return "", fmt.Errorf("failed to count messages: %w", err)
+	}
+
+	rollups, err := models.UsageRollupBy(db, "model", since)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Vessel activity for %s:\n\n", label)
+	fmt.Fprintf(&b, "New chats:    %d\n", chatCount)
+	fmt.Fprintf(&b, "New messages: %d\n", messageCount)
+
+	if len(rollups) > 0 {
+		b.WriteString("\nModel usage:\n")
+		var totalCost float64
+		for _, rollup := range rollups {
+			fmt.Fprintf(&b, "  %-30s %5d generations, %d tokens\n",
+				rollup.Key, rollup.Generations, rollup.PromptTokens+rollup.CompletionTokens)
+			totalCost += rollup.CostUSD
+		}
+		if totalCost > 0 {
+			fmt.Fprintf(&b, "\nEstimated spend: $%.4f\n", totalCost)
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/models"
+)
+
+// Duplicate detection compares the opening user prompt of every chat:
+// exact matches (after normalization) always group, and with an
+// embedding model the comparison extends to semantically similar
+// openings. The result is cleanup suggestions, not deletions - nothing
+// is modified.
+const (
+	duplicateScanLimit         = 500
+	duplicateDefaultThreshold  = 0.9
+	duplicateOpeningCharLimit  = 512
+	duplicateEmbedBatchSize    = 64
+	duplicateEmbedTimeoutTotal = 2 * time.Minute
+)
+
+// duplicateChat is one chat inside a duplicate group
+type duplicateChat struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	UpdatedAt    string `json:"updated_at"`
+	MessageCount int    `json:"message_count"`
+	Suggestion   string `json:"suggestion"` // "keep", "merge", or "delete"
+}
+
+// duplicateGroup is a cluster of near-duplicate chats with a reason
+type duplicateGroup struct {
+	Reason  string          `json:"reason"`
+	Opening string          `json:"opening"`
+	Chats   []duplicateChat `json:"chats"`
+}
+
+// chatOpening pairs a chat with its normalized first user prompt
+type chatOpening struct {
+	id           string
+	title        string
+	updatedAt    string
+	messageCount int
+	opening      string
+}
+
+// DuplicateChatsHandler scans recent chats for near-duplicates. Without
+// parameters only identical opening prompts group; pass ?model= (an
+// embedding model) and optionally ?threshold= to also cluster openings
+// by cosine similarity.
+func (s *OllamaService) DuplicateChatsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		threshold := duplicateDefaultThreshold
+		if t, err := strconv.ParseFloat(c.Query("threshold"), 64); err == nil && t > 0 && t < 1 {
+			threshold = t
+		}
+
+		openings, err := loadChatOpenings(db, duplicateScanLimit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		groups := groupIdenticalOpenings(openings)
+
+		if model := c.Query("model"); model != "" {
+			similar, err := s.groupSimilarOpenings(c.Request.Context(), openings, model, threshold)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": "embedding failed: " + err.Error()})
+				return
+			}
+			groups = append(groups, similar...)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"groups": groups, "scanned": len(openings)})
+	}
+}
+
+// loadChatOpenings pulls the newest chats with their first user prompt
+// and message count in one query
+func loadChatOpenings(db *sql.DB, limit int) ([]chatOpening, error) {
+	rows, err := db.Query(`
+		SELECT c.id, c.title, c.updated_at,
+			(SELECT COUNT(*) FROM messages m WHERE m.chat_id = c.id),
+			COALESCE((SELECT m.content FROM messages m WHERE m.chat_id = c.id AND m.role = 'user'
+				ORDER BY m.created_at ASC LIMIT 1), '')
+		FROM chats c
+		ORDER BY c.updated_at DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var openings []chatOpening
+	for rows.Next() {
+		var entry chatOpening
+		var content string
+		if err := rows.Scan(&entry.id, &entry.title, &entry.updatedAt, &entry.messageCount, &content); err != nil {
+			return nil, err
+		}
+		entry.opening = normalizeOpening(models.DecodeMessageContent(content))
+		if entry.opening == "" {
+			continue
+		}
+		openings = append(openings, entry)
+	}
+	return openings, rows.Err()
+}
+
+// normalizeOpening lowercases and collapses whitespace so trivial
+// variations of the same prompt still match
+func normalizeOpening(content string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(content)), " ")
+	runes := []rune(normalized)
+	if len(runes) > duplicateOpeningCharLimit {
+		normalized = string(runes[:duplicateOpeningCharLimit])
+	}
+	return normalized
+}
+
+// groupIdenticalOpenings clusters chats whose normalized opening prompts
+// match exactly
+func groupIdenticalOpenings(openings []chatOpening) []duplicateGroup {
+	byOpening := make(map[string][]chatOpening)
+	for _, entry := range openings {
+		byOpening[entry.opening] = append(byOpening[entry.opening], entry)
+	}
+
+	groups := []duplicateGroup{}
+	for opening, members := range byOpening {
+		if len(members) < 2 {
+			continue
+		}
+		groups = append(groups, buildDuplicateGroup("identical opening prompt", opening, members))
+	}
+	return groups
+}
+
+// groupSimilarOpenings embeds distinct openings and clusters those above
+// the cosine similarity threshold (identical openings are left to the
+// exact pass)
+func (s *OllamaService) groupSimilarOpenings(ctx context.Context, openings []chatOpening, model string, threshold float64) ([]duplicateGroup, error) {
+	distinct := []string{}
+	seen := map[string]bool{}
+	for _, entry := range openings {
+		if !seen[entry.opening] {
+			seen[entry.opening] = true
+			distinct = append(distinct, entry.opening)
+		}
+	}
+	if len(distinct) < 2 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duplicateEmbedTimeoutTotal)
+	defer cancel()
+
+	vectors := make([][]float32, 0, len(distinct))
+	for start := 0; start < len(distinct); start += duplicateEmbedBatchSize {
+		end := start + duplicateEmbedBatchSize
+		if end > len(distinct) {
+			end = len(distinct)
+		}
+		resp, err := s.client.Embed(ctx, &ollamaapi.EmbedRequest{Model: model, Input: distinct[start:end]})
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, resp.Embeddings...)
+	}
+	if len(vectors) != len(distinct) {
+		return nil, fmt.Errorf("embedding count mismatch: got %d for %d openings", len(vectors), len(distinct))
+	}
+
+	// Union-find over openings whose vectors clear the threshold
+	parent := make([]int, len(distinct))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for i := 0; i < len(distinct); i++ {
+		for j := i + 1; j < len(distinct); j++ {
+			if cosineSimilarity(vectors[i], vectors[j]) >= threshold {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	index := make(map[string]int, len(distinct))
+	for i, opening := range distinct {
+		index[opening] = i
+	}
+	clusters := make(map[int][]chatOpening)
+	for _, entry := range openings {
+		root := find(index[entry.opening])
+		clusters[root] = append(clusters[root], entry)
+	}
+
+	groups := []duplicateGroup{}
+	for root, members := range clusters {
+		if len(members) < 2 {
+			continue
+		}
+		// Mixed openings only: identical ones are already reported
+		allSame := true
+		for _, member := range members {
+			if member.opening != members[0].opening {
+				allSame = false
+				break
+			}
+		}
+		if allSame {
+			continue
+		}
+		groups = append(groups, buildDuplicateGroup("similar opening prompt", distinct[find(root)], members))
+	}
+	return groups, nil
+}
+
+// buildDuplicateGroup picks the chat worth keeping (most messages, then
+// most recent) and suggests merging the rest - or deleting those with
+// nothing beyond the opening exchange
+func buildDuplicateGroup(reason, opening string, members []chatOpening) duplicateGroup {
+	keep := 0
+	for i := 1; i < len(members); i++ {
+		if members[i].messageCount > members[keep].messageCount ||
+			(members[i].messageCount == members[keep].messageCount &&
+				members[i].updatedAt > members[keep].updatedAt) {
+			keep = i
+		}
+	}
+
+	group := duplicateGroup{Reason: reason, Opening: opening}
+	for i, member := range members {
+		suggestion := "merge"
+		if i == keep {
+			suggestion = "keep"
+		} else if member.messageCount <= 2 {
+			suggestion = "delete"
+		}
+		group.Chats = append(group.Chats, duplicateChat{
+			ID:           member.id,
+			Title:        member.title,
+			UpdatedAt:    member.updatedAt,
+			MessageCount: member.messageCount,
+			Suggestion:   suggestion,
+		})
+	}
+	return group
+}
+
+// cosineSimilarity compares two embedding vectors
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"vessel-backend/internal/config"
+)
+
+// emailConfigured reports whether outbound email can be sent at all
+func emailConfigured() bool {
+	cfg := config.Get().Email
+	return cfg.SMTPHost != "" && cfg.From != ""
+}
+
+// sendEmail delivers one plain-text message over the configured SMTP
+// server. The general transport for digests and any future transactional
+// mail (password resets, invites).
+func sendEmail(to, subject, body string) error {
+	cfg := config.Get().Email
+	if !emailConfigured() {
+		return fmt.Errorf("email not configured: smtp_host and from are required")
+	}
+
+	message := strings.Join([]string{
+		"From: " + cfg.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		body,
+	}, "\r\n")
+
+	addr := fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort)
+
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return smtp.SendMail(addr, auth, cfg.From, []string{to}, []byte(message))
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/models"
+)
+
+// embedBatchConcurrency caps how many embed calls run against Ollama at
+// once; more would just queue inside the runner and starve chat traffic
+const embedBatchConcurrency = 2
+
+// EmbedBatchRequest carries a large embedding workload for the job queue;
+// the single-shot /embed endpoint times out on thousand-chunk ingestions
+type EmbedBatchRequest struct {
+	Model string   `json:"model" binding:"required"`
+	Texts []string `json:"texts" binding:"required,min=1"`
+}
+
+// embedBatchProgress tracks one batch job's counters and result. Vectors
+// are held in memory until fetched - they can be regenerated from the
+// payload, so losing them on restart only costs a retry.
+type embedBatchProgress struct {
+	Total    int         `json:"total"`
+	Embedded int         `json:"embedded"`
+	Vectors  [][]float32 `json:"-"`
+}
+
+var (
+	embedBatchMu sync.Mutex
+	embedBatches = map[string]*embedBatchProgress{}
+)
+
+// StartEmbedBatchHandler enqueues an embed-batch job and returns it; the
+// client polls the job (or GET /embeddings/batch/:id) for progress
+func StartEmbedBatchHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req EmbedBatchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		payload, err := json.Marshal(req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		job, err := EnqueueJob("embed-batch", payload, 1)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		embedBatchMu.Lock()
+		embedBatches[job.ID] = &embedBatchProgress{Total: len(req.Texts)}
+		embedBatchMu.Unlock()
+
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+// GetEmbedBatchHandler returns a batch job's status and progress; once
+// the job succeeded, ?vectors=true includes the embeddings (in input
+// order)
+func GetEmbedBatchHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := models.GetJob(db, c.Param("id"))
+		if err != nil {
+			if err.Error() == "job not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		embedBatchMu.Lock()
+		progress := embedBatches[job.ID]
+		embedBatchMu.Unlock()
+
+		response := gin.H{"job": job}
+		if progress != nil {
+			response["total"] = progress.Total
+			response["embedded"] = progress.Embedded
+			if c.Query("vectors") == "true" && job.Status == "succeeded" {
+				response["vectors"] = progress.Vectors
+			}
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// runEmbedBatch executes one embed-batch job: the texts are windowed
+// into Ollama-sized batches and embedded with bounded concurrency,
+// updating the progress counter as windows complete
+func (s *OllamaService) runEmbedBatch(ctx context.Context, jobID string, req *EmbedBatchRequest) error {
+	embedBatchMu.Lock()
+	progress := embedBatches[jobID]
+	if progress == nil {
+		// Job survived a restart; the tracker didn't
+		progress = &embedBatchProgress{Total: len(req.Texts)}
+		embedBatches[jobID] = progress
+	}
+	embedBatchMu.Unlock()
+
+	vectors := make([][]float32, len(req.Texts))
+	sem := make(chan struct{}, embedBatchConcurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	for start := 0; start < len(req.Texts); start += ragEvalEmbedBatchSize {
+		end := start + ragEvalEmbedBatchSize
+		if end > len(req.Texts) {
+			end = len(req.Texts)
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			mu.Lock()
+			failed := firstErr != nil
+			mu.Unlock()
+			if failed || ctx.Err() != nil {
+				return
+			}
+
+			resp, err := s.client.Embed(ctx, &ollamaapi.EmbedRequest{Model: req.Model, Input: req.Texts[start:end]})
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+
+			mu.Lock()
+			copy(vectors[start:end], resp.Embeddings)
+			mu.Unlock()
+
+			embedBatchMu.Lock()
+			progress.Embedded += end - start
+			embedBatchMu.Unlock()
+		}(start, end)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	for i := range vectors {
+		if vectors[i] == nil {
+			return fmt.Errorf("embedding count mismatch at input %d", i)
+		}
+	}
+
+	embedBatchMu.Lock()
+	progress.Vectors = vectors
+	embedBatchMu.Unlock()
+	return nil
+}
+
+// cleanupEmbedBatches drops result trackers for jobs finished longer
+// than the retention ago, so fetched vectors don't pile up in memory
+func cleanupEmbedBatches(db *sql.DB, retention time.Duration) {
+	embedBatchMu.Lock()
+	ids := make([]string, 0, len(embedBatches))
+	for id := range embedBatches {
+		ids = append(ids, id)
+	}
+	embedBatchMu.Unlock()
+
+	cutoff := time.Now().Add(-retention)
+	for _, id := range ids {
+		job, err := models.GetJob(db, id)
+		if err != nil || job.Status == "queued" || job.Status == "running" {
+			continue
+		}
+		if job.FinishedAt == nil {
+			continue
+		}
+		finished, err := time.Parse(time.RFC3339, *job.FinishedAt)
+		if err == nil && finished.Before(cutoff) {
+			embedBatchMu.Lock()
+			delete(embedBatches, id)
+			embedBatchMu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// embeddingMigrationTimeout bounds one background re-embedding run
+const embeddingMigrationTimeout = 30 * time.Minute
+
+// EmbeddingItem is one chunk to re-embed, identified so the client can
+// swap the new vector into its index
+type EmbeddingItem struct {
+	ID   string `json:"id" binding:"required"`
+	Text string `json:"text" binding:"required"`
+}
+
+// EmbeddingVector pairs a chunk ID with its new embedding
+type EmbeddingVector struct {
+	ID        string    `json:"id"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// RegisterCollectionRequest records which model embedded a collection
+type RegisterCollectionRequest struct {
+	Name       string `json:"name"`
+	EmbedModel string `json:"embed_model" binding:"required"`
+	Vectors    int    `json:"vectors"`
+}
+
+// EmbeddingMigrationRequest re-embeds a collection's chunks with a new
+// model in the background
+type EmbeddingMigrationRequest struct {
+	CollectionID string          `json:"collection_id" binding:"required"`
+	Model        string          `json:"model" binding:"required"`
+	Items        []EmbeddingItem `json:"items" binding:"required,min=1"`
+}
+
+// embeddingMigration tracks one background re-embedding run. Vectors
+// are held in memory until fetched - they can be regenerated from the
+// source texts, so losing them on restart only costs a re-run.
+type embeddingMigration struct {
+	ID           string            `json:"id"`
+	CollectionID string            `json:"collection_id"`
+	Model        string            `json:"model"`
+	Status       string            `json:"status"` // "running", "completed", "failed"
+	Total        int               `json:"total"`
+	Embedded     int               `json:"embedded"`
+	Error        string            `json:"error,omitempty"`
+	StartedAt    time.Time         `json:"started_at"`
+	FinishedAt   *time.Time        `json:"finished_at,omitempty"`
+	Vectors      []EmbeddingVector `json:"-"`
+}
+
+// embeddingMigrations holds active and recent runs by ID
+var (
+	embeddingMigrationsMu sync.Mutex
+	embeddingMigrations   = map[string]*embeddingMigration{}
+)
+
+// ListCollectionEmbeddingsHandler lists registered collections with the
+// model behind each one; ?model= flags which are stale relative to it
+func ListCollectionEmbeddingsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entries, err := models.ListCollectionEmbeddings(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		target := c.Query("model")
+		stale := []string{}
+		if target != "" {
+			for _, entry := range entries {
+				if entry.EmbedModel != target {
+					stale = append(stale, entry.CollectionID)
+				}
+			}
+		}
+
+		response := gin.H{"collections": entries}
+		if target != "" {
+			response["stale"] = stale
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// RegisterCollectionEmbeddingHandler records (or refreshes) which model
+// produced a collection's vectors
+func RegisterCollectionEmbeddingHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RegisterCollectionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		entry := &models.CollectionEmbedding{
+			CollectionID: c.Param("id"),
+			Name:         req.Name,
+			EmbedModel:   req.EmbedModel,
+			Vectors:      req.Vectors,
+		}
+		if err := models.UpsertCollectionEmbedding(db, entry); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, entry)
+	}
+}
+
+// DeleteCollectionEmbeddingHandler removes a collection's registration
+func DeleteCollectionEmbeddingHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.DeleteCollectionEmbedding(db, c.Param("id")); err != nil {
+			if err.Error() == "collection not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "collection deleted"})
+	}
+}
+
+// StartEmbeddingMigrationHandler re-embeds a collection's chunks with a
+// new model in the background. The client polls the migration until it
+// completes, fetches the vectors, and swaps its index in one go; the
+// registry only switches to the new model once everything embedded.
+func (s *OllamaService) StartEmbeddingMigrationHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req EmbeddingMigrationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		migration := &embeddingMigration{
+			ID:           uuid.New().String(),
+			CollectionID: req.CollectionID,
+			Model:        req.Model,
+			Status:       "running",
+			Total:        len(req.Items),
+			StartedAt:    time.Now().UTC(),
+		}
+		embeddingMigrationsMu.Lock()
+		embeddingMigrations[migration.ID] = migration
+		embeddingMigrationsMu.Unlock()
+
+		go s.runEmbeddingMigration(db, migration, req.Items)
+
+		c.JSON(http.StatusAccepted, migration)
+	}
+}
+
+// GetEmbeddingMigrationHandler returns a migration's status; once
+// completed, ?vectors=true includes the new embeddings
+func GetEmbeddingMigrationHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		embeddingMigrationsMu.Lock()
+		migration, ok := embeddingMigrations[c.Param("id")]
+		embeddingMigrationsMu.Unlock()
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "migration not found"})
+			return
+		}
+
+		if c.Query("vectors") == "true" && migration.Status == "completed" {
+			c.JSON(http.StatusOK, gin.H{"migration": migration, "vectors": migration.Vectors})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"migration": migration})
+	}
+}
+
+// runEmbeddingMigration embeds the items batch by batch, updating
+// progress as it goes, and swaps the collection's registry entry to the
+// new model only when every chunk embedded
+func (s *OllamaService) runEmbeddingMigration(db *sql.DB, migration *embeddingMigration, items []EmbeddingItem) {
+	ctx, cancel := context.WithTimeout(context.Background(), embeddingMigrationTimeout)
+	defer cancel()
+
+	logger := logging.Module("embeddings")
+	vectors := make([]EmbeddingVector, 0, len(items))
+	for start := 0; start < len(items); start += ragEvalEmbedBatchSize {
+		end := start + ragEvalEmbedBatchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		texts := make([]string, 0, end-start)
+		for _, item := range items[start:end] {
+			texts = append(texts, item.Text)
+		}
+
+		batch, err := s.embedInBatches(ctx, migration.Model, texts)
+		if err != nil {
+			logger.Warn("Embedding migration failed", "migration", migration.ID, "error", err)
+			finishEmbeddingMigration(migration, nil, err)
+			return
+		}
+		for i, vector := range batch {
+			vectors = append(vectors, EmbeddingVector{ID: items[start+i].ID, Embedding: vector})
+		}
+
+		embeddingMigrationsMu.Lock()
+		migration.Embedded = len(vectors)
+		embeddingMigrationsMu.Unlock()
+	}
+
+	// The swap: only now does the registry say the collection is on the
+	// new model
+	err := models.SetCollectionEmbeddingModel(db, migration.CollectionID, migration.Model, len(vectors))
+	if err != nil {
+		logger.Warn("Failed to update collection registry after migration", "migration", migration.ID, "error", err)
+	}
+	finishEmbeddingMigration(migration, vectors, nil)
+}
+
+// finishEmbeddingMigration marks a run completed or failed
+func finishEmbeddingMigration(migration *embeddingMigration, vectors []EmbeddingVector, err error) {
+	now := time.Now().UTC()
+	embeddingMigrationsMu.Lock()
+	defer embeddingMigrationsMu.Unlock()
+
+	migration.FinishedAt = &now
+	if err != nil {
+		migration.Status = "failed"
+		migration.Error = err.Error()
+		return
+	}
+	migration.Status = "completed"
+	migration.Vectors = vectors
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// Event names emitted to subscribed webhooks
+const (
+	EventChatCreated       = "chat.created"
+	EventMessageCompleted  = "message.completed"
+	EventModelPullFinished = "model.pull.finished"
+	EventSyncPushed        = "sync.pushed"
+	EventBackupCompleted   = "backup.completed"
+	EventAccessNewOrigin   = "access.new_origin"
+	EventAccessDenied      = "access.denied"
+)
+
+// webhookMaxAttempts is how many times one delivery is tried before the
+// failure is logged and given up on
+const webhookMaxAttempts = 3
+
+// webhookRetryDelay is the base wait between attempts; attempt n waits n times this
+const webhookRetryDelay = 2 * time.Second
+
+// eventsDB is set by InitEvents; EmitEvent is a no-op until then
+var eventsDB *sql.DB
+
+// webhookHTTPClient delivers event payloads with a bounded timeout
+var webhookHTTPClient = &http.Client{Transport: sharedRoundTripper{}, Timeout: 10 * time.Second}
+
+// InitEvents wires the event dispatcher to the database so EmitEvent can
+// look up subscribers and log deliveries
+func InitEvents(db *sql.DB) {
+	eventsDB = db
+}
+
+// EmitEvent delivers an event to every enabled webhook subscribed to it.
+// Delivery happens in the background: callers never wait on, or fail
+// because of, a slow subscriber.
+func EmitEvent(event string, data map[string]interface{}) {
+	db := eventsDB
+	if db == nil {
+		return
+	}
+
+	// Some events also land in the in-app notification center, whether or
+	// not any webhook subscribes to them
+	if title, ok := notifiableEvents[event]; ok {
+		body := ""
+		if model, ok := data["model"].(string); ok {
+			body = model
+		} else if filename, ok := data["filename"].(string); ok {
+			body = filename
+		} else if ip, ok := data["ip"].(string); ok {
+			body = ip
+		}
+		addNotification(event, title, body)
+	}
+
+	go func() {
+		logger := logging.Module("events")
+
+		webhooks, err := models.ListEnabledWebhooksFor(db, event)
+		if err != nil {
+			logger.Warn("Failed to load webhooks", "event", event, "error", err)
+			return
+		}
+		if len(webhooks) == 0 {
+			return
+		}
+
+		body, err := json.Marshal(map[string]interface{}{
+			"event":     event,
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"data":      data,
+		})
+		if err != nil {
+			logger.Warn("Failed to encode event payload", "event", event, "error", err)
+			return
+		}
+
+		for _, webhook := range webhooks {
+			go deliverEvent(db, webhook, event, body)
+		}
+	}()
+}
+
+// deliverEvent posts one event payload to one webhook, retrying transient
+// failures, and records the final outcome in the delivery log
+func deliverEvent(db *sql.DB, webhook models.Webhook, event string, body []byte) {
+	logger := logging.Module("events")
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Event:     event,
+	}
+
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		delivery.Attempts = attempt
+
+		statusCode, err := postEvent(webhook, event, body)
+		delivery.StatusCode = statusCode
+		if err != nil {
+			delivery.Error = err.Error()
+		} else if statusCode >= 300 {
+			delivery.Error = http.StatusText(statusCode)
+		} else {
+			delivery.Error = ""
+			break
+		}
+
+		if attempt < webhookMaxAttempts {
+			time.Sleep(time.Duration(attempt) * webhookRetryDelay)
+		}
+	}
+
+	if delivery.Error != "" {
+		logger.Warn("Webhook delivery failed", "url", webhook.URL, "event", event,
+			"attempts", delivery.Attempts, "error", delivery.Error)
+	}
+
+	if err := models.RecordWebhookDelivery(db, delivery); err != nil {
+		logger.Warn("Failed to log webhook delivery", "error", err)
+	}
+}
+
+// postEvent performs a single delivery attempt
+func postEvent(webhook models.Webhook, event string, body []byte) (int, error) {
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vessel-Event", event)
+	if webhook.Secret != "" {
+		req.Header.Set("X-Vessel-Signature", "sha256="+signEventBody(webhook.Secret, body))
+	}
+
+	resp, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// signEventBody computes the hex HMAC-SHA256 of the payload, which
+// receivers verify against the X-Vessel-Signature header
+func signEventBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"vessel-backend/internal/models"
+)
+
+// exportFormat and exportFormatVersion identify the archive layout so
+// import can reject archives it doesn't understand. The archive is a zip:
+//
+//	manifest.json          - format marker, version, counts
+//	chats.json             - all chats with nested messages; attachment
+//	                         metadata references files by id
+//	attachments/<id>       - raw attachment bytes
+const (
+	exportFormat        = "vessel-export"
+	exportFormatVersion = 1
+)
+
+// exportManifest is the manifest.json payload
+type exportManifest struct {
+	Format      string `json:"format"`
+	Version     int    `json:"version"`
+	ExportedAt  string `json:"exportedAt"`
+	Chats       int    `json:"chats"`
+	Messages    int    `json:"messages"`
+	Attachments int    `json:"attachments"`
+}
+
+// ExportHandler writes the full chat history (including archived chats and
+// attachments) as a portable zip archive for migration or offline keeping
+func ExportHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chats, err := models.ListChats(db, "", "", true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		messageCount := 0
+		attachmentCount := 0
+		type attachmentBlob struct {
+			id   string
+			data []byte
+		}
+		var blobs []attachmentBlob
+
+		for i := range chats {
+			messages, err := models.GetMessagesByChatID(db, chats[i].ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			for j := range messages {
+				rows, err := db.Query(`SELECT id, mime_type, filename, data FROM attachments WHERE message_id = ?`, messages[j].ID)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					return
+				}
+				for rows.Next() {
+					var att models.Attachment
+					var data []byte
+					if err := rows.Scan(&att.ID, &att.MimeType, &att.Filename, &data); err != nil {
+						rows.Close()
+						c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+						return
+					}
+					att.MessageID = messages[j].ID
+					messages[j].Attachments = append(messages[j].Attachments, att)
+					blobs = append(blobs, attachmentBlob{id: att.ID, data: models.DecodeAttachmentData(data)})
+					attachmentCount++
+				}
+				rows.Close()
+			}
+
+			chats[i].Messages = messages
+			messageCount += len(messages)
+		}
+
+		filename := "vessel-export-" + time.Now().Format("20060102-150405") + ".zip"
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+		zw := zip.NewWriter(c.Writer)
+		defer zw.Close()
+
+		manifest := exportManifest{
+			Format:      exportFormat,
+			Version:     exportFormatVersion,
+			ExportedAt:  time.Now().UTC().Format(time.RFC3339),
+			Chats:       len(chats),
+			Messages:    messageCount,
+			Attachments: attachmentCount,
+		}
+		if err := writeZipJSON(zw, "manifest.json", manifest); err != nil {
+			return
+		}
+		if err := writeZipJSON(zw, "chats.json", chats); err != nil {
+			return
+		}
+		for _, blob := range blobs {
+			w, err := zw.Create("attachments/" + blob.id)
+			if err != nil {
+				return
+			}
+			if _, err := w.Write(blob.data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeZipJSON adds one pretty-printed JSON file to the archive
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// ImportHandler merges a previously exported archive into the database.
+// All IDs are remapped to fresh UUIDs so an import never collides with or
+// overwrites existing chats - importing the same archive twice simply
+// yields two copies.
+func ImportHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var src io.Reader
+		if file, err := c.FormFile("file"); err == nil {
+			opened, err := file.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read upload: " + err.Error()})
+				return
+			}
+			defer opened.Close()
+			src = opened
+		} else {
+			src = c.Request.Body
+		}
+
+		raw, err := io.ReadAll(src)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read archive: " + err.Error()})
+			return
+		}
+
+		zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "not a valid zip archive: " + err.Error()})
+			return
+		}
+
+		var manifest exportManifest
+		if err := readZipJSON(zr, "manifest.json", &manifest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing or invalid manifest.json: " + err.Error()})
+			return
+		}
+		if manifest.Format != exportFormat || manifest.Version > exportFormatVersion {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported archive format"})
+			return
+		}
+
+		var chats []models.Chat
+		if err := readZipJSON(zr, "chats.json", &chats); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing or invalid chats.json: " + err.Error()})
+			return
+		}
+
+		// Attachment payloads by original ID
+		attachmentData := make(map[string][]byte)
+		for _, f := range zr.File {
+			if len(f.Name) > len("attachments/") && f.Name[:len("attachments/")] == "attachments/" {
+				rc, err := f.Open()
+				if err != nil {
+					continue
+				}
+				data, err := io.ReadAll(rc)
+				rc.Close()
+				if err == nil {
+					attachmentData[f.Name[len("attachments/"):]] = data
+				}
+			}
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer tx.Rollback()
+
+		importedChats, importedMessages, importedAttachments := 0, 0, 0
+
+		for _, chat := range chats {
+			newChatID := uuid.New().String()
+			_, err := tx.Exec(`
+				INSERT INTO chats (id, title, model, pinned, archived, system_prompt_id, created_at, updated_at, sync_version)
+				VALUES (?, ?, ?, ?, ?, NULL, ?, ?, 1)`,
+				newChatID, chat.Title, chat.Model, chat.Pinned, chat.Archived,
+				chat.CreatedAt.Format(time.RFC3339), chat.UpdatedAt.Format(time.RFC3339),
+			)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import chat: " + err.Error()})
+				return
+			}
+			importedChats++
+
+			// Remap message IDs first so parent links can be rewritten
+			idMap := make(map[string]string, len(chat.Messages))
+			for _, msg := range chat.Messages {
+				idMap[msg.ID] = uuid.New().String()
+			}
+
+			for _, msg := range chat.Messages {
+				var parentID *string
+				if msg.ParentID != nil {
+					if mapped, ok := idMap[*msg.ParentID]; ok {
+						parentID = &mapped
+					}
+				}
+
+				storedContent, err := models.EncodeMessageContent(msg.Content)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encrypt message content: " + err.Error()})
+					return
+				}
+
+				_, err = tx.Exec(`
+					INSERT INTO messages (id, chat_id, parent_id, role, content, sibling_index, created_at, sync_version)
+					VALUES (?, ?, ?, ?, ?, ?, ?, 1)`,
+					idMap[msg.ID], newChatID, parentID, msg.Role, storedContent,
+					msg.SiblingIndex, msg.CreatedAt.Format(time.RFC3339),
+				)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import message: " + err.Error()})
+					return
+				}
+				importedMessages++
+
+				for _, att := range msg.Attachments {
+					data, ok := attachmentData[att.ID]
+					if !ok {
+						continue
+					}
+					stored, err := models.EncodeAttachmentData(data)
+					if err != nil {
+						c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encrypt attachment: " + err.Error()})
+						return
+					}
+					_, err = tx.Exec(`
+						INSERT INTO attachments (id, message_id, mime_type, data, filename)
+						VALUES (?, ?, ?, ?, ?)`,
+						uuid.New().String(), idMap[msg.ID], att.MimeType, stored, att.Filename,
+					)
+					if err != nil {
+						c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import attachment: " + err.Error()})
+						return
+					}
+					importedAttachments++
+				}
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"chats":       importedChats,
+			"messages":    importedMessages,
+			"attachments": importedAttachments,
+		})
+	}
+}
+
+// readZipJSON decodes one JSON file from the archive
+func readZipJSON(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewDecoder(f).Decode(v)
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/models"
+)
+
+// finetuneMessage is one turn in the chat-completion fine-tuning format
+type finetuneMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// finetuneExample is one JSONL line: a full conversation
+type finetuneExample struct {
+	Messages []finetuneMessage `json:"messages"`
+}
+
+// RateMessageRequest carries thumbs feedback for a message
+type RateMessageRequest struct {
+	Rating int `json:"rating" binding:"required,oneof=1 -1"`
+}
+
+// RateMessageHandler stores thumbs-up (1) or thumbs-down (-1) feedback
+// on a message
+func RateMessageHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RateMessageRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		if err := models.SetMessageRating(db, c.Param("id"), req.Rating); err != nil {
+			if err.Error() == "message not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"rating": req.Rating})
+	}
+}
+
+// ClearMessageRatingHandler removes feedback from a message
+func ClearMessageRatingHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.ClearMessageRating(db, c.Param("id")); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "cleared"})
+	}
+}
+
+// FinetuneExportHandler exports conversations as JSONL in chat-completion
+// fine-tuning format, one conversation per line. Filters: ?chats= (comma
+// separated IDs), ?model=, ?since=/?until= (RFC3339 or YYYY-MM-DD on
+// chat update time), ?rated=up (only chats containing a thumbs-up
+// assistant message).
+func FinetuneExportHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := `SELECT id FROM chats WHERE 1=1`
+		var args []interface{}
+
+		if chatList := c.Query("chats"); chatList != "" {
+			ids := strings.Split(chatList, ",")
+			query += " AND id IN (?" + strings.Repeat(",?", len(ids)-1) + ")"
+			for _, id := range ids {
+				args = append(args, strings.TrimSpace(id))
+			}
+		}
+		if model := c.Query("model"); model != "" {
+			query += " AND model = ?"
+			args = append(args, model)
+		}
+		if since := c.Query("since"); since != "" {
+			t, err := parseExportDate(since)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since date"})
+				return
+			}
+			query += " AND updated_at >= ?"
+			args = append(args, t.UTC().Format(time.RFC3339))
+		}
+		if until := c.Query("until"); until != "" {
+			t, err := parseExportDate(until)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until date"})
+				return
+			}
+			query += " AND updated_at <= ?"
+			args = append(args, t.UTC().Format(time.RFC3339))
+		}
+		if rated := c.Query("rated"); rated == "up" {
+			query += ` AND EXISTS (
+				SELECT 1 FROM message_ratings r
+				JOIN messages m ON m.id = r.message_id
+				WHERE m.chat_id = chats.id AND r.rating = 1)`
+		} else if rated != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "rated must be 'up'"})
+			return
+		}
+		query += " ORDER BY updated_at ASC"
+
+		rows, err := db.Query(query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		var chatIDs []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			chatIDs = append(chatIDs, id)
+		}
+		rows.Close()
+
+		c.Header("Content-Type", "application/jsonl")
+		c.Header("Content-Disposition", `attachment; filename="vessel-finetune-`+time.Now().Format("20060102")+`.jsonl"`)
+
+		encoder := json.NewEncoder(c.Writer)
+		for _, chatID := range chatIDs {
+			messages, err := models.GetMessagesByChatID(db, chatID)
+			if err != nil {
+				continue
+			}
+
+			example := finetuneExample{}
+			hasAssistant := false
+			for _, msg := range messages {
+				if msg.Content == "" {
+					continue
+				}
+				if msg.Role == "assistant" {
+					hasAssistant = true
+				}
+				example.Messages = append(example.Messages, finetuneMessage{
+					Role:    msg.Role,
+					Content: msg.Content,
+				})
+			}
+
+			// A useful training example needs at least one exchange
+			if !hasAssistant || len(example.Messages) < 2 {
+				continue
+			}
+			if err := encoder.Encode(example); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseExportDate accepts RFC3339 timestamps or plain YYYY-MM-DD dates
+func parseExportDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
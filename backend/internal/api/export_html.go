@@ -0,0 +1,197 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/models"
+)
+
+// htmlExportStyle is the inline stylesheet for exported chats - everything
+// the file needs ships inside it, so it renders anywhere without vessel
+const htmlExportStyle = `
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; max-width: 860px; margin: 0 auto; padding: 24px; background: #f6f7f9; color: #1a1a2e; }
+h1 { font-size: 1.4em; }
+.meta { color: #666; font-size: 0.85em; margin-bottom: 24px; }
+.message { border-radius: 10px; padding: 14px 18px; margin: 12px 0; white-space: pre-wrap; word-wrap: break-word; }
+.message.user { background: #dbe7ff; margin-left: 48px; }
+.message.assistant { background: #ffffff; margin-right: 48px; border: 1px solid #e2e2ea; }
+.message.system { background: #fff6db; font-style: italic; }
+.role { font-weight: 600; font-size: 0.75em; text-transform: uppercase; color: #555; margin-bottom: 6px; }
+.message img { max-width: 100%; border-radius: 6px; margin: 8px 0; }
+pre { background: #21222c; color: #f8f8f2; padding: 12px; border-radius: 6px; overflow-x: auto; white-space: pre; }
+pre code { font-family: "SF Mono", Menlo, Consolas, monospace; font-size: 0.85em; }
+code { background: rgba(0,0,0,0.06); padding: 1px 4px; border-radius: 3px; }
+pre code { background: none; padding: 0; }
+.lang { color: #888; font-size: 0.7em; text-transform: uppercase; margin-bottom: -8px; display: block; }
+.hl-kw { color: #ff79c6; }
+.hl-str { color: #f1fa8c; }
+.hl-com { color: #6272a4; font-style: italic; }
+`
+
+// Highlighting patterns applied to escaped code block text, in order:
+// comments first so keywords inside them stay untouched
+var (
+	hlCommentPattern = regexp.MustCompile(`(?m)(//[^\n]*|#[^\n]*|/\*[\s\S]*?\*/)`)
+	hlStringPattern  = regexp.MustCompile(`(&#34;[^\n]*?&#34;|&#39;[^\n]*?&#39;)`)
+	hlKeywordPattern = regexp.MustCompile(`\b(func|return|if|else|for|while|var|let|const|def|class|import|from|package|type|struct|interface|switch|case|break|continue|nil|null|None|true|false|True|False|new|try|catch|except|finally|async|await|range|map|print|fn|pub|use|match|impl)\b`)
+)
+
+// fencedCodePattern splits message content into text and ``` code blocks
+var fencedCodePattern = regexp.MustCompile("(?s)```([a-zA-Z0-9+-]*)\n?(.*?)```")
+
+// renderChatExportDocument builds the self-contained HTML document for
+// one conversation. With print set, a print stylesheet is appended so
+// the same document paginates cleanly when rendered to PDF.
+func renderChatExportDocument(db *sql.DB, chatID string, print bool) (title, doc string, err error) {
+	chat, err := models.GetChat(db, chatID)
+	if err != nil {
+		return "", "", err
+	}
+	if chat == nil {
+		return "", "", fmt.Errorf("chat not found")
+	}
+
+	messages, err := models.GetMessagesByChatID(db, chatID)
+	if err != nil {
+		return "", "", err
+	}
+
+	attachments, err := models.ListChatAttachmentsWithData(db, chatID)
+	if err != nil {
+		return "", "", err
+	}
+	byMessage := make(map[string][]models.Attachment)
+	for _, attachment := range attachments {
+		byMessage[attachment.MessageID] = append(byMessage[attachment.MessageID], attachment)
+	}
+
+	var b strings.Builder
+	title = chat.Title
+	if title == "" {
+		title = "Untitled chat"
+	}
+	style := htmlExportStyle
+	if print {
+		style += printExportStyle
+	}
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	b.WriteString("<style>" + style + "</style>\n</head>\n<body>\n")
+	b.WriteString("<h1>" + html.EscapeString(title) + "</h1>\n")
+	b.WriteString("<div class=\"meta\">Model: " + html.EscapeString(chat.Model) +
+		" &middot; Exported from vessel &middot; " + chat.UpdatedAt.Format("2006-01-02 15:04") + "</div>\n")
+
+	for _, msg := range messages {
+		role := msg.Role
+		if role != "user" && role != "assistant" && role != "system" {
+			role = "assistant"
+		}
+		b.WriteString("<div class=\"message " + role + "\">\n")
+		b.WriteString("<div class=\"role\">" + html.EscapeString(msg.Role) + "</div>\n")
+		b.WriteString(renderMessageHTML(msg.Content))
+		for _, attachment := range byMessage[msg.ID] {
+			b.WriteString(renderAttachmentHTML(attachment))
+		}
+		b.WriteString("</div>\n")
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	return title, b.String(), nil
+}
+
+// ExportChatHTMLHandler renders a conversation into one self-contained
+// HTML file - inline styles, highlighted code blocks, and image
+// attachments embedded as data URIs - for archiving or sharing
+func ExportChatHTMLHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		title, doc, err := renderChatExportDocument(db, c.Param("id"), false)
+		if err != nil {
+			if err.Error() == "chat not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		filename := sanitizeFilename(title) + ".html"
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(doc))
+	}
+}
+
+// sanitizeFilename turns a chat title into a safe download filename
+func sanitizeFilename(title string) string {
+	name := regexp.MustCompile(`[^a-zA-Z0-9 _-]`).ReplaceAllString(title, "")
+	name = strings.TrimSpace(name)
+	name = strings.ReplaceAll(name, " ", "-")
+	if name == "" {
+		name = "chat"
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// renderMessageHTML escapes message text and converts fenced code blocks
+// into highlighted <pre><code> sections
+func renderMessageHTML(content string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range fencedCodePattern.FindAllStringSubmatchIndex(content, -1) {
+		b.WriteString(html.EscapeString(content[last:loc[0]]))
+
+		lang := content[loc[2]:loc[3]]
+		code := content[loc[4]:loc[5]]
+		b.WriteString("<pre>")
+		if lang != "" {
+			b.WriteString("<span class=\"lang\">" + html.EscapeString(lang) + "</span>")
+		}
+		b.WriteString("<code>" + highlightCode(code) + "</code></pre>")
+
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(content[last:]))
+	return b.String()
+}
+
+// highlightCode applies minimal regex-based syntax highlighting to
+// already-escaped code: comments, string literals, common keywords
+func highlightCode(code string) string {
+	escaped := html.EscapeString(code)
+	escaped = hlCommentPattern.ReplaceAllString(escaped, `<span class="hl-com">$1</span>`)
+	escaped = hlStringPattern.ReplaceAllStringFunc(escaped, func(s string) string {
+		// Skip matches that landed inside a comment span
+		if strings.Contains(s, "hl-com") {
+			return s
+		}
+		return `<span class="hl-str">` + s + `</span>`
+	})
+	escaped = hlKeywordPattern.ReplaceAllString(escaped, `<span class="hl-kw">$1</span>`)
+	return escaped
+}
+
+// renderAttachmentHTML inlines image attachments as data URIs; other
+// attachment types are listed by name (with transcript when present)
+func renderAttachmentHTML(attachment models.Attachment) string {
+	if strings.HasPrefix(attachment.MimeType, "image/") && len(attachment.Data) > 0 {
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		return "<img src=\"data:" + attachment.MimeType + ";base64," + encoded +
+			"\" alt=\"" + html.EscapeString(attachment.Filename) + "\">\n"
+	}
+
+	entry := "<div class=\"meta\">Attachment: " + html.EscapeString(attachment.Filename)
+	if attachment.Transcript != "" {
+		entry += " &mdash; " + html.EscapeString(attachment.Transcript)
+	}
+	return entry + "</div>\n"
+}
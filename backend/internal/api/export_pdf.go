@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"github.com/gin-gonic/gin"
+)
+
+// pdfExportTimeout bounds one Chrome print run; image-heavy chats take
+// a while to lay out
+const pdfExportTimeout = 60 * time.Second
+
+// printExportStyle is appended to the export stylesheet when rendering
+// to PDF: white pages, no chrome, and messages kept whole across page
+// breaks
+const printExportStyle = `
+@page { margin: 18mm 14mm; }
+body { background: #ffffff; max-width: none; padding: 0; }
+.message { break-inside: avoid; }
+.message.assistant { border: 1px solid #ccc; }
+pre { white-space: pre-wrap; word-wrap: break-word; }
+`
+
+// ExportChatHandler dispatches GET /chats/:id/export on ?format=: "pdf"
+// prints the conversation through headless Chrome, anything else falls
+// back to the self-contained HTML export
+func ExportChatHandler(db *sql.DB) gin.HandlerFunc {
+	htmlHandler := ExportChatHTMLHandler(db)
+	return func(c *gin.Context) {
+		if c.Query("format") != "pdf" {
+			htmlHandler(c)
+			return
+		}
+		exportChatPDF(c, db)
+	}
+}
+
+// exportChatPDF renders the chat's export document to PDF with the
+// fetcher's headless Chrome and delivers it as a download
+func exportChatPDF(c *gin.Context, db *sql.DB) {
+	fetcher := GetFetcher()
+	if !fetcher.HasChrome() {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "PDF export requires headless Chrome, which is not installed"})
+		return
+	}
+
+	title, doc, err := renderChatExportDocument(db, c.Param("id"), true)
+	if err != nil {
+		if err.Error() == "chat not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	pdf, err := printHTMLToPDF(fetcher, doc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render PDF: " + err.Error()})
+		return
+	}
+
+	filename := sanitizeFilename(title) + ".pdf"
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// printHTMLToPDF loads an HTML document into a fresh browser context and
+// prints it. The document goes through a temp file - data: URLs choke on
+// chats with large inline attachments.
+func printHTMLToPDF(fetcher *Fetcher, doc string) ([]byte, error) {
+	file, err := os.CreateTemp("", "vessel-export-*.html")
+	if err != nil {
+		return nil, err
+	}
+	path := file.Name()
+	defer os.Remove(path)
+	if _, err := file.WriteString(doc); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	browserCtx, browserCancel := chromedp.NewContext(fetcher.allocCtx)
+	defer browserCancel()
+	browserCtx, timeoutCancel := context.WithTimeout(browserCtx, pdfExportTimeout)
+	defer timeoutCancel()
+
+	var pdf []byte
+	err = chromedp.Run(browserCtx,
+		chromedp.Navigate("file://"+path),
+		chromedp.WaitVisible("body", chromedp.ByQuery),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var printErr error
+			pdf, _, printErr = page.PrintToPDF().
+				WithPrintBackground(true).
+				WithPreferCSSPageSize(true).
+				Do(ctx)
+			return printErr
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("chromedp failed: %w", err)
+	}
+	return pdf, nil
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// FanoutRequest fans one user message out to several models at once
+type FanoutRequest struct {
+	Models  []string               `json:"models" binding:"required,min=2"`
+	Options map[string]interface{} `json:"options"`
+}
+
+// fanoutAnswer is one model's result; exactly one of Message or Error
+// is set
+type fanoutAnswer struct {
+	Model   string          `json:"model"`
+	Message *models.Message `json:"message,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// FanoutMessageHandler sends the conversation up to a user message to
+// every listed model concurrently and stores each answer as a sibling
+// assistant message, so the user can compare and continue from the best
+// one
+func (s *OllamaService) FanoutMessageHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req FanoutRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		if err := validateSamplingOptions(req.Options); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		msg, err := models.GetMessage(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if msg == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "message not found"})
+			return
+		}
+		if msg.Role != "user" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "fan-out starts from a user message"})
+			return
+		}
+
+		messages, err := models.GetMessagesByChatID(db, msg.ChatID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// History up to and including the user message being answered
+		history := historyBeforeMessage(messages, msg)
+		history = append(history, ollamaapi.Message{Role: msg.Role, Content: msg.Content})
+
+		// Pin a seed so every model answers under identical parameters
+		options := ensureSeed(req.Options)
+
+		// Query the models concurrently
+		responses := make([]fanoutAnswer, len(req.Models))
+		var wg sync.WaitGroup
+		for i, model := range req.Models {
+			wg.Add(1)
+			go func(i int, model string) {
+				defer wg.Done()
+
+				chatReq := &ollamaapi.ChatRequest{
+					Model:    model,
+					Messages: history,
+					Stream:   new(bool),
+					Options:  options,
+				}
+				var finalResp ollamaapi.ChatResponse
+				err := s.client.Chat(c.Request.Context(), chatReq, func(resp ollamaapi.ChatResponse) error {
+					finalResp = resp
+					return nil
+				})
+				if err != nil {
+					responses[i] = fanoutAnswer{Model: model, Error: err.Error()}
+					return
+				}
+
+				recordGeneration(db, &models.GenerationMetric{
+					Model:            model,
+					User:             metricUser(c),
+					Kind:             "chat",
+					PromptTokens:     finalResp.Metrics.PromptEvalCount,
+					CompletionTokens: finalResp.Metrics.EvalCount,
+					DurationMs:       finalResp.Metrics.TotalDuration.Milliseconds(),
+					QueueMs:          finalResp.Metrics.LoadDuration.Milliseconds(),
+					ToolCalls:        len(finalResp.Message.ToolCalls),
+				})
+				responses[i] = fanoutAnswer{Model: model, Message: &models.Message{
+					Role:    "assistant",
+					Content: finalResp.Message.Content,
+				}}
+			}(i, model)
+		}
+		wg.Wait()
+
+		// Store the answers sequentially so sibling indices stay stable
+		nextIndex := nextSiblingIndex(messages, &msg.ID)
+		stored := 0
+		for i := range responses {
+			if responses[i].Error != "" {
+				continue
+			}
+			answer := responses[i].Message
+			answer.ChatID = msg.ChatID
+			answer.ParentID = &msg.ID
+			answer.SiblingIndex = nextIndex
+			if err := models.CreateMessage(db, answer); err != nil {
+				responses[i] = fanoutAnswer{Model: responses[i].Model, Error: err.Error()}
+				continue
+			}
+			if err := models.SetMessageGeneration(db, answer.ID, responses[i].Model, options); err != nil {
+				logging.Module("fanout").Warn("Failed to record fan-out generation", "messageId", answer.ID, "error", err)
+			}
+			nextIndex++
+			stored++
+		}
+
+		if stored == 0 {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "all models failed", "answers": responses})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"answers": responses})
+	}
+}
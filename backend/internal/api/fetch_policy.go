@@ -0,0 +1,252 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// fetchPolicySettingKey is the settings-table key the policy is persisted
+// under, alongside the client's other opaque settings
+const fetchPolicySettingKey = "fetchPolicy"
+
+// FetchPolicy overrides the detect-once method ordering. Order is the
+// global preference ("chrome", "curl", "wget", "native", tried in turn);
+// Domains maps a host or suffix (".internal" matches any subdomain) to
+// its own order, e.g. always Chrome for an SPA host or never curl for
+// the intranet. An empty policy keeps the built-in behavior.
+type FetchPolicy struct {
+	Order   []string            `json:"order,omitempty"`
+	Domains map[string][]string `json:"domains,omitempty"`
+}
+
+// validFetchMethods guards policy input; the zero-value map lookup
+// doubles as the membership test
+var validFetchMethods = map[string]bool{
+	string(FetchMethodCurl):   true,
+	string(FetchMethodWget):   true,
+	string(FetchMethodChrome): true,
+	string(FetchMethodNative): true,
+}
+
+// validate rejects unknown method names and empty domain keys
+func (p *FetchPolicy) validate() error {
+	check := func(methods []string, where string) error {
+		for _, m := range methods {
+			if !validFetchMethods[m] {
+				return fmt.Errorf("unknown fetch method %q in %s (valid: curl, wget, chrome, native)", m, where)
+			}
+		}
+		return nil
+	}
+	if err := check(p.Order, "order"); err != nil {
+		return err
+	}
+	for domain, methods := range p.Domains {
+		if strings.TrimSpace(domain) == "" {
+			return fmt.Errorf("domain keys must not be empty")
+		}
+		if err := check(methods, "domains."+domain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderFor returns the method order for a host: the longest matching
+// domain override wins, then the global order, then nil (built-in
+// behavior). A key matches its exact host, any subdomain, or - with a
+// leading dot - subdomains only.
+func (p *FetchPolicy) orderFor(host string) []FetchMethod {
+	if p == nil {
+		return nil
+	}
+	host = strings.ToLower(host)
+
+	best := ""
+	for domain := range p.Domains {
+		d := strings.ToLower(domain)
+		matches := host == strings.TrimPrefix(d, ".") || strings.HasSuffix(host, "."+strings.TrimPrefix(d, "."))
+		if matches && len(d) > len(best) {
+			best = domain
+		}
+	}
+
+	names := p.Order
+	if best != "" {
+		names = p.Domains[best]
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	methods := make([]FetchMethod, 0, len(names))
+	for _, name := range names {
+		methods = append(methods, FetchMethod(name))
+	}
+	return methods
+}
+
+// SetPolicy applies a fetch policy; nil reverts to the built-in ordering
+func (f *Fetcher) SetPolicy(policy *FetchPolicy) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.policy = policy
+}
+
+// Policy returns the active fetch policy, or nil if none is set
+func (f *Fetcher) Policy() *FetchPolicy {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.policy
+}
+
+// methodOrderFor resolves the configured method order for a URL's host;
+// nil means no policy applies and the built-in ordering should run
+func (f *Fetcher) methodOrderFor(rawURL string) []FetchMethod {
+	policy := f.Policy()
+	if policy == nil {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return policy.orderFor("")
+	}
+	return policy.orderFor(parsed.Hostname())
+}
+
+// fetchWithOrder tries the configured methods in turn, skipping ones
+// that aren't installed, and returns the first success
+func (f *Fetcher) fetchWithOrder(ctx context.Context, rawURL string, opts FetchOptions, order []FetchMethod) (*FetchResult, error) {
+	f.mu.RLock()
+	curlPath := f.curlPath
+	wgetPath := f.wgetPath
+	hasChrome := f.hasChrome
+	f.mu.RUnlock()
+
+	var lastErr error
+	tried := 0
+	for _, method := range order {
+		var result *FetchResult
+		var err error
+		switch method {
+		case FetchMethodCurl:
+			if curlPath == "" {
+				continue
+			}
+			result, err = f.fetchWithCurl(ctx, rawURL, curlPath, opts)
+		case FetchMethodWget:
+			if wgetPath == "" {
+				continue
+			}
+			result, err = f.fetchWithWget(ctx, rawURL, wgetPath, opts)
+		case FetchMethodChrome:
+			if !hasChrome {
+				continue
+			}
+			// fetchWithChrome truncates itself, no processContent needed
+			if result, err = f.fetchWithChrome(ctx, rawURL, opts); err == nil {
+				return result, nil
+			}
+		case FetchMethodNative:
+			result, err = f.fetchNative(ctx, rawURL, opts)
+		default:
+			continue
+		}
+		tried++
+		if err == nil {
+			return processContent(result, opts)
+		}
+		logging.Module("fetcher").Warn("Configured fetch method failed, trying next",
+			"method", string(method), "url", rawURL, "error", err)
+		lastErr = fmt.Errorf("%s: %w", method, err)
+	}
+	if tried == 0 {
+		return nil, fmt.Errorf("no configured fetch method is available")
+	}
+	return nil, fmt.Errorf("all configured fetch methods failed: %w", lastErr)
+}
+
+// loadFetchPolicy reads the persisted policy from settings and applies
+// it to the singleton fetcher; called once at startup
+func loadFetchPolicy(db *sql.DB) {
+	settings, err := models.GetAllSettings(db)
+	if err != nil {
+		logging.Module("fetcher").Warn("Failed to load fetch policy", "error", err)
+		return
+	}
+	raw, ok := settings[fetchPolicySettingKey]
+	if !ok {
+		return
+	}
+	var policy FetchPolicy
+	if err := json.Unmarshal(raw, &policy); err != nil {
+		logging.Module("fetcher").Warn("Ignoring malformed fetch policy setting", "error", err)
+		return
+	}
+	if err := policy.validate(); err != nil {
+		logging.Module("fetcher").Warn("Ignoring invalid fetch policy setting", "error", err)
+		return
+	}
+	GetFetcher().SetPolicy(&policy)
+	logging.Module("fetcher").Info("Loaded fetch policy",
+		"order", strings.Join(policy.Order, ","), "domains", len(policy.Domains))
+}
+
+// GetFetchPolicyHandler returns the active fetch policy (empty object
+// when none is configured)
+func GetFetchPolicyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy := GetFetcher().Policy()
+		if policy == nil {
+			policy = &FetchPolicy{}
+		}
+		c.JSON(http.StatusOK, policy)
+	}
+}
+
+// UpdateFetchPolicyHandler validates, persists, and applies a new fetch
+// policy. An empty body ({}) clears the policy back to built-in ordering.
+func UpdateFetchPolicyHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var policy FetchPolicy
+		if err := c.ShouldBindJSON(&policy); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		if err := policy.validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if len(policy.Order) == 0 && len(policy.Domains) == 0 {
+			if err := models.DeleteSetting(db, fetchPolicySettingKey); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save fetch policy: " + err.Error()})
+				return
+			}
+			GetFetcher().SetPolicy(nil)
+			c.JSON(http.StatusOK, gin.H{"cleared": true})
+			return
+		}
+
+		raw, err := json.Marshal(policy)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encode fetch policy: " + err.Error()})
+			return
+		}
+		if err := models.SetSetting(db, fetchPolicySettingKey, raw); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save fetch policy: " + err.Error()})
+			return
+		}
+		GetFetcher().SetPolicy(&policy)
+		c.JSON(http.StatusOK, policy)
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/models"
+)
+
+// CreateFetchProfileRequest represents the request body for creating a fetch profile
+type CreateFetchProfileRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Domain   string `json:"domain" binding:"required"`
+	AuthType string `json:"auth_type" binding:"required,oneof=cookie bearer basic"`
+	Cookies  string `json:"cookies"`
+	Token    string `json:"token"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ListFetchProfilesHandler returns a handler for listing fetch profiles (without secrets)
+func ListFetchProfilesHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		profiles, err := models.ListFetchProfiles(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if profiles == nil {
+			profiles = []models.FetchProfile{}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"profiles": profiles})
+	}
+}
+
+// CreateFetchProfileHandler returns a handler for creating a fetch profile
+func CreateFetchProfileHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CreateFetchProfileRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		// Validate that the credential material matches the auth type
+		switch req.AuthType {
+		case "cookie":
+			if req.Cookies == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "cookies are required for cookie auth"})
+				return
+			}
+		case "bearer":
+			if req.Token == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "token is required for bearer auth"})
+				return
+			}
+		case "basic":
+			if req.Username == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "username is required for basic auth"})
+				return
+			}
+		}
+
+		profile := &models.FetchProfile{
+			Name:     req.Name,
+			Domain:   strings.ToLower(strings.TrimPrefix(req.Domain, ".")),
+			AuthType: req.AuthType,
+		}
+		secret := &models.FetchProfileSecret{
+			Cookies:  req.Cookies,
+			Token:    req.Token,
+			Username: req.Username,
+			Password: req.Password,
+		}
+
+		if err := models.CreateFetchProfile(db, profile, secret); err != nil {
+			if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+				c.JSON(http.StatusConflict, gin.H{"error": "a profile with this name already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, profile)
+	}
+}
+
+// DeleteFetchProfileHandler returns a handler for deleting a fetch profile
+func DeleteFetchProfileHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		if err := models.DeleteFetchProfile(db, id); err != nil {
+			if err.Error() == "fetch profile not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "fetch profile not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "fetch profile deleted"})
+	}
+}
+
+// applyFetchProfile loads a named profile and applies its credentials to the
+// fetch options. The profile is only applied when the target host matches the
+// profile's domain, so credentials never leak to unrelated sites.
+func applyFetchProfile(db *sql.DB, profileName, targetURL string, opts *FetchOptions) error {
+	profile, secret, err := models.GetFetchProfileByName(db, profileName)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return fmt.Errorf("fetch profile %q not found", profileName)
+	}
+
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host != profile.Domain && !strings.HasSuffix(host, "."+profile.Domain) {
+		return fmt.Errorf("fetch profile %q is restricted to domain %s", profileName, profile.Domain)
+	}
+
+	switch profile.AuthType {
+	case "cookie":
+		opts.Headers["Cookie"] = secret.Cookies
+	case "bearer":
+		opts.Headers["Authorization"] = "Bearer " + secret.Token
+	case "basic":
+		credentials := base64.StdEncoding.EncodeToString([]byte(secret.Username + ":" + secret.Password))
+		opts.Headers["Authorization"] = "Basic " + credentials
+	}
+
+	return nil
+}
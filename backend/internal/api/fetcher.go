@@ -5,9 +5,10 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
+	"os"
 	"os/exec"
 	"regexp"
 	"strings"
@@ -15,6 +16,9 @@ import (
 	"time"
 
 	"github.com/chromedp/chromedp"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
 )
 
 // FetchMethod represents the method used to fetch URLs
@@ -55,8 +59,12 @@ type FetchOptions struct {
 
 // DefaultFetchOptions returns sensible defaults
 func DefaultFetchOptions() FetchOptions {
+	maxLength := config.Get().Fetcher.MaxLength
+	if maxLength <= 0 {
+		maxLength = 500000 // 500KB
+	}
 	return FetchOptions{
-		MaxLength:       500000, // 500KB
+		MaxLength:       maxLength,
 		Timeout:         30 * time.Second,
 		UserAgent:       "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
 		Headers:         make(map[string]string),
@@ -74,6 +82,9 @@ type Fetcher struct {
 	httpClient    *http.Client
 	method        FetchMethod
 	hasChrome     bool
+	proxyURL      string // Outbound proxy (http://, https://, or socks5://), empty if none
+	metrics       *fetcherMetrics
+	policy        *FetchPolicy // Optional method-order overrides from settings
 	mu            sync.RWMutex
 
 	// chromedp allocator context (reused for efficiency)
@@ -96,13 +107,40 @@ func GetFetcher() *Fetcher {
 
 // NewFetcher creates a new Fetcher, detecting available tools
 func NewFetcher() *Fetcher {
-	f := &Fetcher{}
+	f := &Fetcher{metrics: newFetcherMetrics()}
+	f.detectProxy()
 	f.detectTools()
 	f.initHTTPClient()
 	f.initChromeDp()
 	return f
 }
 
+// detectProxy reads the outbound proxy from the config (which already layers
+// PROXY_URL over the config file), then the conventional
+// HTTPS_PROXY/HTTP_PROXY/ALL_PROXY variables.
+// Supports http://, https://, and socks5:// proxies.
+func (f *Fetcher) detectProxy() {
+	if value := config.Get().Fetcher.ProxyURL; value != "" {
+		if _, err := url.Parse(value); err == nil {
+			f.proxyURL = value
+			logging.Module("fetcher").Info("Using outbound proxy from config")
+			return
+		}
+	}
+
+	for _, key := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "ALL_PROXY", "all_proxy"} {
+		if value := os.Getenv(key); value != "" {
+			if _, err := url.Parse(value); err != nil {
+				logging.Module("fetcher").Warn("Ignoring invalid proxy URL", "env", key, "error", err)
+				continue
+			}
+			f.proxyURL = value
+			logging.Module("fetcher").Info("Using outbound proxy", "env", key)
+			return
+		}
+	}
+}
+
 // detectTools checks which external tools are available
 func (f *Fetcher) detectTools() {
 	f.mu.Lock()
@@ -122,7 +160,7 @@ func (f *Fetcher) detectTools() {
 		versionOut, _ := versionCmd.CombinedOutput()
 		f.wgetIsBusyBox = strings.Contains(string(versionOut), "BusyBox")
 		if f.wgetIsBusyBox {
-			log.Printf("[Fetcher] Found BusyBox wget (limited options)")
+			logging.Module("fetcher").Info("Found BusyBox wget (limited options)")
 		}
 		if f.method == "" {
 			f.method = FetchMethodWget
@@ -146,7 +184,7 @@ func (f *Fetcher) detectTools() {
 		if path, err := exec.LookPath(p); err == nil {
 			f.chromePath = path
 			f.hasChrome = true
-			log.Printf("[Fetcher] Found Chrome at: %s", path)
+			logging.Module("fetcher").Info("Found Chrome", "path", path)
 			break
 		}
 	}
@@ -161,9 +199,18 @@ func (f *Fetcher) detectTools() {
 func (f *Fetcher) initHTTPClient() {
 	jar, _ := cookiejar.New(nil)
 
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if f.proxyURL != "" {
+		if proxyURL, err := url.Parse(f.proxyURL); err == nil {
+			// http.Transport handles http, https, and socks5 proxy schemes
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
 	f.httpClient = &http.Client{
-		Jar:     jar,
-		Timeout: 30 * time.Second,
+		Jar:       jar,
+		Transport: transport,
+		Timeout:   30 * time.Second,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			if len(via) >= 10 {
 				return fmt.Errorf("too many redirects")
@@ -194,12 +241,16 @@ func (f *Fetcher) initChromeDp() {
 		chromedp.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"),
 	)
 
+	if f.proxyURL != "" {
+		opts = append(opts, chromedp.ProxyServer(f.proxyURL))
+	}
+
 	if f.chromePath != "" {
 		opts = append(opts, chromedp.ExecPath(f.chromePath))
 	}
 
 	f.allocCtx, f.allocCancel = chromedp.NewExecAllocator(context.Background(), opts...)
-	log.Printf("[Fetcher] Chrome headless browser initialized")
+	logging.Module("fetcher").Info("Chrome headless browser initialized")
 }
 
 // Close cleans up resources
@@ -223,6 +274,13 @@ func (f *Fetcher) HasChrome() bool {
 	return f.hasChrome
 }
 
+// ProxyURL returns the configured outbound proxy URL, or empty string if none
+func (f *Fetcher) ProxyURL() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.proxyURL
+}
+
 // Fetch fetches a URL using the best available method
 // For most sites, uses curl/wget. Falls back to headless browser for JS-heavy sites.
 func (f *Fetcher) Fetch(ctx context.Context, url string, opts FetchOptions) (*FetchResult, error) {
@@ -231,26 +289,34 @@ func (f *Fetcher) Fetch(ctx context.Context, url string, opts FetchOptions) (*Fe
 		return f.fetchWithChrome(ctx, url, opts)
 	}
 
+	// A configured policy (global or per-domain method order) replaces the
+	// built-in ordering and the JS-render fallback below
+	if order := f.methodOrderFor(url); len(order) > 0 {
+		return f.fetchWithOrder(ctx, url, opts, order)
+	}
+
 	// Try fast methods first
 	result, err := f.fetchFast(ctx, url, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if content looks like a JS-rendered page that needs headless browser
-	if f.hasChrome && f.isJSRenderedPage(result.Content) {
-		log.Printf("[Fetcher] Content appears to be JS-rendered, trying headless browser for: %s", url)
+	// Check if content looks like a JS-rendered page that needs headless browser.
+	// Only HTML content gets this treatment - JSON/XML/text never need rendering.
+	if f.hasChrome && !config.Get().Fetcher.JSRender.Disabled && isHTMLContent(result.ContentType) && f.isJSRenderedPage(result.Content) {
+		logging.Module("fetcher").Info("Content appears to be JS-rendered, trying headless browser", "url", url)
+		f.metrics.recordFallback()
 		headlessResult, headlessErr := f.fetchWithChrome(ctx, url, opts)
 		if headlessErr == nil && len(headlessResult.Content) > len(result.Content) {
 			return headlessResult, nil
 		}
 		// If headless failed or got less content, return original
 		if headlessErr != nil {
-			log.Printf("[Fetcher] Headless browser failed: %v, using original content", headlessErr)
+			logging.Module("fetcher").Warn("Headless browser failed, using original content", "error", headlessErr)
 		}
 	}
 
-	return result, nil
+	return processContent(result, opts)
 }
 
 // fetchFast tries curl, wget, or native HTTP in order
@@ -271,11 +337,26 @@ func (f *Fetcher) fetchFast(ctx context.Context, url string, opts FetchOptions)
 	}
 }
 
+// defaultJSHeavySites is the built-in list of sites known to need JS
+// rendering; fetcher.js_render.sites in the config replaces it
+var defaultJSHeavySites = []string{
+	"docs.rs",
+	"reactjs.org",
+	"vuejs.org",
+	"angular.io",
+	"nextjs.org",
+	"vercel.com",
+	"netlify.com",
+}
+
 // isJSRenderedPage checks if the content appears to be a JS-rendered page
-// that hasn't actually rendered its content yet
+// that hasn't actually rendered its content yet. Thresholds and the site
+// list come from fetcher.js_render in the config.
 func (f *Fetcher) isJSRenderedPage(content string) bool {
+	cfg := config.Get().Fetcher.JSRender
+
 	// Too short content often indicates JS rendering needed
-	if len(strings.TrimSpace(content)) < 500 {
+	if cfg.MinContentLength > 0 && len(strings.TrimSpace(content)) < cfg.MinContentLength {
 		return true
 	}
 
@@ -294,33 +375,32 @@ func (f *Fetcher) isJSRenderedPage(content string) bool {
 	}
 
 	contentLower := strings.ToLower(content)
-	for _, pattern := range jsPatterns {
-		if strings.Contains(contentLower, strings.ToLower(pattern)) {
-			// Found JS pattern, but also check if there's substantial content
-			// Extract text content (very rough)
-			textContent := stripHTMLTags(content)
-			if len(strings.TrimSpace(textContent)) < 1000 {
-				return true
+	if cfg.MinTextLength > 0 {
+		for _, pattern := range jsPatterns {
+			if strings.Contains(contentLower, strings.ToLower(pattern)) {
+				// Found JS pattern, but also check if there's substantial content
+				// Extract text content (very rough)
+				textContent := stripHTMLTags(content)
+				if len(strings.TrimSpace(textContent)) < cfg.MinTextLength {
+					return true
+				}
 			}
 		}
 	}
 
 	// Check for common documentation sites that need JS
-	jsHeavySites := []string{
-		"docs.rs",
-		"reactjs.org",
-		"vuejs.org",
-		"angular.io",
-		"nextjs.org",
-		"vercel.com",
-		"netlify.com",
-	}
-
-	for _, site := range jsHeavySites {
-		if strings.Contains(content, site) {
-			textContent := stripHTMLTags(content)
-			if len(strings.TrimSpace(textContent)) < 2000 {
-				return true
+	jsHeavySites := cfg.Sites
+	if jsHeavySites == nil {
+		jsHeavySites = defaultJSHeavySites
+	}
+
+	if cfg.MinSiteTextLength > 0 {
+		for _, site := range jsHeavySites {
+			if strings.Contains(content, site) {
+				textContent := stripHTMLTags(content)
+				if len(strings.TrimSpace(textContent)) < cfg.MinSiteTextLength {
+					return true
+				}
 			}
 		}
 	}
@@ -349,11 +429,14 @@ func stripHTMLTags(content string) string {
 }
 
 // fetchWithChrome uses headless Chrome to fetch and render the page
-func (f *Fetcher) fetchWithChrome(ctx context.Context, url string, opts FetchOptions) (*FetchResult, error) {
+func (f *Fetcher) fetchWithChrome(ctx context.Context, url string, opts FetchOptions) (result *FetchResult, err error) {
 	if !f.hasChrome || f.allocCtx == nil {
 		return nil, fmt.Errorf("headless Chrome not available")
 	}
 
+	start := time.Now()
+	defer func() { f.metrics.record(FetchMethodChrome, time.Since(start), err) }()
+
 	// Create a timeout context
 	timeout := opts.Timeout
 	if timeout == 0 {
@@ -422,17 +505,57 @@ func (f *Fetcher) fetchWithChrome(ctx context.Context, url string, opts FetchOpt
 	}, nil
 }
 
+// limitedBuffer is an io.Writer that keeps at most max bytes and counts the
+// rest, so large responses are truncated while streaming instead of being
+// buffered entirely in memory.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	max   int
+	total int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+	if remaining := b.max - b.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	// Always report the full write so the producer keeps streaming without error
+	return len(p), nil
+}
+
 // fetchWithCurl uses curl to fetch the URL
-func (f *Fetcher) fetchWithCurl(ctx context.Context, url string, curlPath string, opts FetchOptions) (*FetchResult, error) {
+func (f *Fetcher) fetchWithCurl(ctx context.Context, url string, curlPath string, opts FetchOptions) (result *FetchResult, err error) {
+	start := time.Now()
+	defer func() { f.metrics.record(FetchMethodCurl, time.Since(start), err) }()
+
 	args := []string{
 		"-sS",                          // Silent but show errors
 		"-L",                           // Follow redirects
 		"--max-time", fmt.Sprintf("%d", int(opts.Timeout.Seconds())),
 		"-A", opts.UserAgent,           // User agent
-		"-w", "\n---CURL_INFO---\n%{content_type}\n%{url_effective}\n%{http_code}", // Output metadata
+		// Output metadata on stderr so truncating stdout can't clip it
+		"-w", "%{stderr}---CURL_INFO---\n%{content_type}\n%{url_effective}\n%{http_code}\n",
 		"--compressed",                 // Automatically decompress responses
 	}
 
+	// Ask the server for only the bytes we can use; servers that ignore the
+	// range return the full body, which the limited buffer truncates below
+	if opts.MaxLength > 0 {
+		args = append(args, "--range", fmt.Sprintf("0-%d", opts.MaxLength-1))
+	}
+
+	// Route through the configured proxy (curl handles http/https/socks5 schemes)
+	f.mu.RLock()
+	proxyURL := f.proxyURL
+	f.mu.RUnlock()
+	if proxyURL != "" {
+		args = append(args, "-x", proxyURL)
+	}
+
 	// Add custom headers
 	for key, value := range opts.Headers {
 		args = append(args, "-H", fmt.Sprintf("%s: %s", key, value))
@@ -443,18 +566,26 @@ func (f *Fetcher) fetchWithCurl(ctx context.Context, url string, curlPath string
 	// This prevents errors when servers return zstd/br that curl may not support
 	args = append(args,
 		"-H", "Accept: text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
-		"-H", "Accept-Language: en-US,en;q=0.5",
 		"-H", "Accept-Encoding: gzip, deflate, identity",
 		"-H", "DNT: 1",
 		"-H", "Connection: keep-alive",
 		"-H", "Upgrade-Insecure-Requests: 1",
 	)
+	if _, ok := opts.Headers["Accept-Language"]; !ok {
+		args = append(args, "-H", "Accept-Language: en-US,en;q=0.5")
+	}
 
 	args = append(args, url)
 
+	maxLength := opts.MaxLength
+	if maxLength <= 0 {
+		maxLength = DefaultFetchOptions().MaxLength
+	}
+
 	cmd := exec.CommandContext(ctx, curlPath, args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
+	stdout := &limitedBuffer{max: maxLength}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
@@ -465,17 +596,13 @@ func (f *Fetcher) fetchWithCurl(ctx context.Context, url string, curlPath string
 		return nil, fmt.Errorf("curl failed: %s - %s", err.Error(), stderr.String())
 	}
 
-	output := stdout.String()
-
-	// Parse the output - content and metadata are separated by ---CURL_INFO---
-	parts := strings.Split(output, "\n---CURL_INFO---\n")
-	if len(parts) != 2 {
+	// Parse metadata from stderr - it follows the ---CURL_INFO--- marker
+	parts := strings.Split(stderr.String(), "---CURL_INFO---\n")
+	if len(parts) < 2 {
 		return nil, fmt.Errorf("unexpected curl output format")
 	}
 
-	content := parts[0]
-	metaLines := strings.Split(strings.TrimSpace(parts[1]), "\n")
-
+	metaLines := strings.Split(strings.TrimSpace(parts[len(parts)-1]), "\n")
 	if len(metaLines) < 3 {
 		return nil, fmt.Errorf("incomplete curl metadata")
 	}
@@ -485,12 +612,16 @@ func (f *Fetcher) fetchWithCurl(ctx context.Context, url string, curlPath string
 	statusCode := 200
 	fmt.Sscanf(metaLines[2], "%d", &statusCode)
 
-	// Truncate content if needed
+	// Servers that honor the range request answer 206 Partial Content
+	if statusCode == http.StatusPartialContent {
+		statusCode = http.StatusOK
+	}
+
+	content := stdout.buf.String()
 	var truncated bool
 	var originalSize int
-	if len(content) > opts.MaxLength {
-		originalSize = len(content)
-		content = content[:opts.MaxLength]
+	if stdout.total > len(content) {
+		originalSize = stdout.total
 		truncated = true
 	}
 
@@ -506,7 +637,10 @@ func (f *Fetcher) fetchWithCurl(ctx context.Context, url string, curlPath string
 }
 
 // fetchWithWget uses wget to fetch the URL
-func (f *Fetcher) fetchWithWget(ctx context.Context, url string, wgetPath string, opts FetchOptions) (*FetchResult, error) {
+func (f *Fetcher) fetchWithWget(ctx context.Context, url string, wgetPath string, opts FetchOptions) (result *FetchResult, err error) {
+	start := time.Now()
+	defer func() { f.metrics.record(FetchMethodWget, time.Since(start), err) }()
+
 	f.mu.RLock()
 	isBusyBox := f.wgetIsBusyBox
 	f.mu.RUnlock()
@@ -531,7 +665,14 @@ func (f *Fetcher) fetchWithWget(ctx context.Context, url string, wgetPath string
 			"--user-agent", opts.UserAgent,
 			"--max-redirect", "10",         // Follow up to 10 redirects
 			"--header", "Accept: text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
-			"--header", "Accept-Language: en-US,en;q=0.5",
+		}
+		if _, ok := opts.Headers["Accept-Language"]; !ok {
+			args = append(args, "--header", "Accept-Language: en-US,en;q=0.5")
+		}
+
+		// Ask for only the bytes we can use (servers may ignore the range)
+		if opts.MaxLength > 0 {
+			args = append(args, "--header", fmt.Sprintf("Range: bytes=0-%d", opts.MaxLength-1))
 		}
 
 		// Add custom headers (GNU wget only)
@@ -543,8 +684,23 @@ func (f *Fetcher) fetchWithWget(ctx context.Context, url string, wgetPath string
 	args = append(args, url)
 
 	cmd := exec.CommandContext(ctx, wgetPath, args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
+
+	// wget only understands HTTP(S) proxies, configured via environment variables
+	f.mu.RLock()
+	proxyURL := f.proxyURL
+	f.mu.RUnlock()
+	if proxyURL != "" && !strings.HasPrefix(proxyURL, "socks") {
+		cmd.Env = append(os.Environ(), "http_proxy="+proxyURL, "https_proxy="+proxyURL)
+	}
+
+	maxLength := opts.MaxLength
+	if maxLength <= 0 {
+		maxLength = DefaultFetchOptions().MaxLength
+	}
+
+	stdout := &limitedBuffer{max: maxLength}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
@@ -554,14 +710,12 @@ func (f *Fetcher) fetchWithWget(ctx context.Context, url string, wgetPath string
 		return nil, fmt.Errorf("wget failed: %s - %s", err.Error(), stderr.String())
 	}
 
-	content := stdout.String()
+	content := stdout.buf.String()
 
-	// Truncate content if needed
 	var truncated bool
 	var originalSize int
-	if len(content) > opts.MaxLength {
-		originalSize = len(content)
-		content = content[:opts.MaxLength]
+	if stdout.total > len(content) {
+		originalSize = stdout.total
 		truncated = true
 	}
 
@@ -578,7 +732,10 @@ func (f *Fetcher) fetchWithWget(ctx context.Context, url string, wgetPath string
 }
 
 // fetchNative uses Go's native http.Client with enhanced capabilities
-func (f *Fetcher) fetchNative(ctx context.Context, url string, opts FetchOptions) (*FetchResult, error) {
+func (f *Fetcher) fetchNative(ctx context.Context, url string, opts FetchOptions) (result *FetchResult, err error) {
+	start := time.Now()
+	defer func() { f.metrics.record(FetchMethodNative, time.Since(start), err) }()
+
 	// Create request with context
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -599,8 +756,10 @@ func (f *Fetcher) fetchNative(ctx context.Context, url string, opts FetchOptions
 		req.Header.Set(key, value)
 	}
 
-	// Create a client with custom timeout
+	// Create a client with custom timeout, reusing the fetcher's pooled
+	// transport
 	client := &http.Client{
+		Transport: f.httpClient.Transport,
 		Jar:     f.httpClient.Jar,
 		Timeout: opts.Timeout,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
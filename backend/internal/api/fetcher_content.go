@@ -0,0 +1,121 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// Content kind detected from the Content-Type header (and content sniffing)
+type contentKind int
+
+const (
+	contentKindHTML contentKind = iota
+	contentKindJSON
+	contentKindXML
+	contentKindText
+	contentKindBinary
+	contentKindUnknown
+)
+
+// binaryContentPrefixes are media type prefixes that are never useful as text
+var binaryContentPrefixes = []string{
+	"image/",
+	"audio/",
+	"video/",
+	"font/",
+}
+
+// binaryContentTypes are specific media types treated as binary
+var binaryContentTypes = map[string]bool{
+	"application/octet-stream":     true,
+	"application/pdf":              true,
+	"application/zip":              true,
+	"application/gzip":             true,
+	"application/x-tar":            true,
+	"application/x-7z-compressed":  true,
+	"application/x-rar-compressed": true,
+	"application/vnd.ms-excel":     true,
+	"application/msword":           true,
+}
+
+// classifyContentType maps a Content-Type header value to a contentKind
+func classifyContentType(contentType string) contentKind {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	switch {
+	case mediaType == "":
+		return contentKindUnknown
+	case mediaType == "text/html", mediaType == "application/xhtml+xml":
+		return contentKindHTML
+	case mediaType == "application/json", strings.HasSuffix(mediaType, "+json"):
+		return contentKindJSON
+	case mediaType == "text/xml", mediaType == "application/xml", strings.HasSuffix(mediaType, "+xml"):
+		return contentKindXML
+	case strings.HasPrefix(mediaType, "text/"):
+		return contentKindText
+	case binaryContentTypes[mediaType]:
+		return contentKindBinary
+	}
+
+	for _, prefix := range binaryContentPrefixes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return contentKindBinary
+		}
+	}
+
+	return contentKindUnknown
+}
+
+// looksBinary sniffs the content for NUL bytes, which never appear in text.
+// Only the first 512 bytes are checked (matching http.DetectContentType).
+func looksBinary(content string) bool {
+	sample := content
+	if len(sample) > 512 {
+		sample = sample[:512]
+	}
+	return strings.ContainsRune(sample, 0)
+}
+
+// processContent post-processes a fetch result based on its content type:
+// binary content is rejected with a clear error, JSON is pretty-printed,
+// and XML/plain text pass through as-is instead of being treated as HTML.
+func processContent(result *FetchResult, opts FetchOptions) (*FetchResult, error) {
+	kind := classifyContentType(result.ContentType)
+
+	// Reject binary content early with a clear error instead of returning garbage
+	if kind == contentKindBinary {
+		return nil, fmt.Errorf("binary content (%s) is not supported - only text-based content can be fetched", result.ContentType)
+	}
+	if (kind == contentKindUnknown || kind == contentKindText) && looksBinary(result.Content) {
+		return nil, fmt.Errorf("content appears to be binary and cannot be returned as text")
+	}
+
+	// Pretty-print JSON so API responses are readable in tool output
+	if kind == contentKindJSON && !result.Truncated {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, []byte(result.Content), "", "  "); err == nil {
+			content := pretty.String()
+			if len(content) > opts.MaxLength && opts.MaxLength > 0 {
+				result.OriginalSize = len(content)
+				content = content[:opts.MaxLength]
+				result.Truncated = true
+			}
+			result.Content = content
+		}
+	}
+
+	return result, nil
+}
+
+// isHTMLContent reports whether the result should get HTML-specific handling
+// (JS-render detection, headless browser fallback)
+func isHTMLContent(contentType string) bool {
+	kind := classifyContentType(contentType)
+	return kind == contentKindHTML || kind == contentKindUnknown
+}
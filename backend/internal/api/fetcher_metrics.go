@@ -0,0 +1,147 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// methodStats tracks success/failure counts and latency per fetch method
+type methodStats struct {
+	Attempts     int64
+	Successes    int64
+	Failures     int64
+	TotalLatency time.Duration
+}
+
+// fetcherMetrics aggregates per-method fetch statistics
+type fetcherMetrics struct {
+	mu        sync.Mutex
+	methods   map[FetchMethod]*methodStats
+	fallbacks int64 // times the Chrome fallback was triggered after a fast fetch
+	startedAt time.Time
+}
+
+func newFetcherMetrics() *fetcherMetrics {
+	return &fetcherMetrics{
+		methods:   make(map[FetchMethod]*methodStats),
+		startedAt: time.Now(),
+	}
+}
+
+// record registers the outcome of a single fetch attempt
+func (m *fetcherMetrics) record(method FetchMethod, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.methods[method]
+	if !ok {
+		stats = &methodStats{}
+		m.methods[method] = stats
+	}
+
+	stats.Attempts++
+	stats.TotalLatency += latency
+	if err != nil {
+		stats.Failures++
+	} else {
+		stats.Successes++
+	}
+}
+
+// recordFallback registers a fast-method result being replaced by headless Chrome
+func (m *fetcherMetrics) recordFallback() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallbacks++
+}
+
+// MethodStatsSnapshot is the per-method view returned by the status endpoint
+type MethodStatsSnapshot struct {
+	Method       string  `json:"method"`
+	Attempts     int64   `json:"attempts"`
+	Successes    int64   `json:"successes"`
+	Failures     int64   `json:"failures"`
+	SuccessRate  float64 `json:"successRate"`
+	AvgLatencyMs int64   `json:"avgLatencyMs"`
+}
+
+// snapshot returns a consistent copy of the current metrics
+func (m *fetcherMetrics) snapshot() ([]MethodStatsSnapshot, int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var snapshots []MethodStatsSnapshot
+	for method, stats := range m.methods {
+		snap := MethodStatsSnapshot{
+			Method:    string(method),
+			Attempts:  stats.Attempts,
+			Successes: stats.Successes,
+			Failures:  stats.Failures,
+		}
+		if stats.Attempts > 0 {
+			snap.SuccessRate = float64(stats.Successes) / float64(stats.Attempts)
+			snap.AvgLatencyMs = stats.TotalLatency.Milliseconds() / stats.Attempts
+		}
+		snapshots = append(snapshots, snap)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Method < snapshots[j].Method
+	})
+
+	return snapshots, m.fallbacks
+}
+
+// FetcherStatusHandler reports per-method fetch health so operators can see
+// when curl is failing or the Chrome fallback dominates.
+// Pass ?format=prometheus for Prometheus text exposition format.
+func FetcherStatusHandler() gin.HandlerFunc {
+	fetcher := GetFetcher()
+
+	return func(c *gin.Context) {
+		snapshots, fallbacks := fetcher.metrics.snapshot()
+
+		if c.Query("format") == "prometheus" {
+			var b strings.Builder
+			b.WriteString("# HELP vessel_fetch_attempts_total Total fetch attempts per method\n")
+			b.WriteString("# TYPE vessel_fetch_attempts_total counter\n")
+			for _, s := range snapshots {
+				fmt.Fprintf(&b, "vessel_fetch_attempts_total{method=%q} %d\n", s.Method, s.Attempts)
+			}
+			b.WriteString("# HELP vessel_fetch_failures_total Total fetch failures per method\n")
+			b.WriteString("# TYPE vessel_fetch_failures_total counter\n")
+			for _, s := range snapshots {
+				fmt.Fprintf(&b, "vessel_fetch_failures_total{method=%q} %d\n", s.Method, s.Failures)
+			}
+			b.WriteString("# HELP vessel_fetch_avg_latency_ms Average fetch latency per method in milliseconds\n")
+			b.WriteString("# TYPE vessel_fetch_avg_latency_ms gauge\n")
+			for _, s := range snapshots {
+				fmt.Fprintf(&b, "vessel_fetch_avg_latency_ms{method=%q} %d\n", s.Method, s.AvgLatencyMs)
+			}
+			b.WriteString("# HELP vessel_fetch_chrome_fallbacks_total Times headless Chrome replaced a fast-method result\n")
+			b.WriteString("# TYPE vessel_fetch_chrome_fallbacks_total counter\n")
+			fmt.Fprintf(&b, "vessel_fetch_chrome_fallbacks_total %d\n", fallbacks)
+
+			c.String(http.StatusOK, b.String())
+			return
+		}
+
+		if snapshots == nil {
+			snapshots = []MethodStatsSnapshot{}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"method":          string(fetcher.Method()),
+			"hasChrome":       fetcher.HasChrome(),
+			"methods":         snapshots,
+			"chromeFallbacks": fallbacks,
+			"since":           fetcher.metrics.startedAt.UTC().Format(time.RFC3339),
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/models"
+)
+
+// generationPresetRequest carries a preset's editable fields; options go
+// through the same validation as chat-level sampling options
+type generationPresetRequest struct {
+	Name        string                 `json:"name" binding:"required"`
+	Description string                 `json:"description"`
+	Options     map[string]interface{} `json:"options" binding:"required"`
+}
+
+// applyGenerationPreset layers the named preset's options onto a request
+// for any option the request leaves unset - explicit request values
+// always win, mirroring applyChatSamplingOptions
+func applyGenerationPreset(db *sql.DB, req *ollamaapi.ChatRequest, idOrName string) error {
+	preset, err := models.GetGenerationPreset(db, idOrName)
+	if err != nil {
+		return err
+	}
+	if preset == nil {
+		return fmt.Errorf("preset not found")
+	}
+
+	if req.Options == nil {
+		req.Options = map[string]interface{}{}
+	}
+	for key, value := range preset.Options {
+		if _, set := req.Options[key]; !set {
+			req.Options[key] = value
+		}
+	}
+	return nil
+}
+
+// ListGenerationPresetsHandler lists every generation preset
+func ListGenerationPresetsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		presets, err := models.ListGenerationPresets(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"presets": presets})
+	}
+}
+
+// CreateGenerationPresetHandler creates a new generation preset
+func CreateGenerationPresetHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req generationPresetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		if err := validateSamplingOptions(req.Options); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		preset := &models.GenerationPreset{
+			Name:        req.Name,
+			Description: req.Description,
+			Options:     req.Options,
+		}
+		if err := models.CreateGenerationPreset(db, preset); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, preset)
+	}
+}
+
+// GetGenerationPresetHandler returns one preset by ID or name
+func GetGenerationPresetHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		preset, err := models.GetGenerationPreset(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if preset == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "preset not found"})
+			return
+		}
+		c.JSON(http.StatusOK, preset)
+	}
+}
+
+// UpdateGenerationPresetHandler updates a preset's name, description,
+// and options
+func UpdateGenerationPresetHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		preset, err := models.GetGenerationPreset(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if preset == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "preset not found"})
+			return
+		}
+
+		var req generationPresetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		if err := validateSamplingOptions(req.Options); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		preset.Name = req.Name
+		preset.Description = req.Description
+		preset.Options = req.Options
+		if err := models.UpdateGenerationPreset(db, preset); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, preset)
+	}
+}
+
+// DeleteGenerationPresetHandler removes a preset
+func DeleteGenerationPresetHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		preset, err := models.GetGenerationPreset(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if preset == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "preset not found"})
+			return
+		}
+		if err := models.DeleteGenerationPreset(db, preset.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "preset deleted"})
+	}
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// geoProvider is one online IP geolocation service. Providers are tried in
+// order until one answers, so a rate-limited or down service degrades
+// gracefully instead of failing the lookup.
+type geoProvider interface {
+	Name() string
+	// Lookup resolves an IP; an empty ip means "the caller's own address"
+	Lookup(ip string) (*LocationResponse, error)
+}
+
+// geoProviders returns the fallback chain in priority order
+func geoProviders() []geoProvider {
+	return []geoProvider{
+		&ipAPIProvider{},
+		&ipWhoProvider{},
+		&ipInfoProvider{},
+	}
+}
+
+var geoHTTPClient = &http.Client{Transport: sharedRoundTripper{}, Timeout: 10 * time.Second}
+
+// --- geolocation cache ---
+
+// geoCacheTTL keeps lookups for the same IP from repeatedly hitting the
+// providers; IP-to-location mappings change rarely
+const geoCacheTTL = time.Hour
+
+type geoCacheEntry struct {
+	location  LocationResponse
+	expiresAt time.Time
+}
+
+var geoCache = struct {
+	mu      sync.Mutex
+	entries map[string]geoCacheEntry
+}{entries: make(map[string]geoCacheEntry)}
+
+func geoCacheGet(key string) *LocationResponse {
+	geoCache.mu.Lock()
+	defer geoCache.mu.Unlock()
+
+	entry, ok := geoCache.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(geoCache.entries, key)
+		return nil
+	}
+	location := entry.location
+	return &location
+}
+
+func geoCacheSet(key string, location *LocationResponse) {
+	geoCache.mu.Lock()
+	defer geoCache.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range geoCache.entries {
+		if now.After(entry.expiresAt) {
+			delete(geoCache.entries, k)
+		}
+	}
+	geoCache.entries[key] = geoCacheEntry{location: *location, expiresAt: now.Add(geoCacheTTL)}
+}
+
+// --- ip-api.com ---
+
+// ipAPIRateLimit stays under ip-api.com's free-tier limit of 45 requests
+// per minute; when the budget is spent the provider steps aside and the
+// next one in the chain takes over
+const ipAPIRateLimit = 40
+
+type ipAPIProvider struct {
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+}
+
+func (p *ipAPIProvider) Name() string { return "ip-api" }
+
+// allow consumes one request from the per-minute budget
+func (p *ipAPIProvider) allow() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(p.windowStart) >= time.Minute {
+		p.windowStart = now
+		p.count = 0
+	}
+	if p.count >= ipAPIRateLimit {
+		return false
+	}
+	p.count++
+	return true
+}
+
+func (p *ipAPIProvider) Lookup(ip string) (*LocationResponse, error) {
+	if !p.allow() {
+		return nil, fmt.Errorf("ip-api rate limit budget exhausted")
+	}
+
+	// Using HTTP because HTTPS requires the paid plan
+	resp, err := geoHTTPClient.Get("http://ip-api.com/json/" + ip)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var geoResp IPGeoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&geoResp); err != nil {
+		return nil, err
+	}
+	if geoResp.Status != "success" {
+		return nil, fmt.Errorf("ip-api: %s", geoResp.Message)
+	}
+
+	return &LocationResponse{
+		Success:     true,
+		City:        geoResp.City,
+		Region:      geoResp.RegionName,
+		Country:     geoResp.Country,
+		CountryCode: geoResp.CountryCode,
+		Latitude:    geoResp.Lat,
+		Longitude:   geoResp.Lon,
+		Timezone:    geoResp.Timezone,
+		IP:          geoResp.Query,
+		Source:      "ip",
+	}, nil
+}
+
+// --- ipwho.is ---
+
+type ipWhoProvider struct{}
+
+func (p *ipWhoProvider) Name() string { return "ipwho.is" }
+
+func (p *ipWhoProvider) Lookup(ip string) (*LocationResponse, error) {
+	resp, err := geoHTTPClient.Get("https://ipwho.is/" + ip)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IP          string  `json:"ip"`
+		Success     bool    `json:"success"`
+		Message     string  `json:"message"`
+		Country     string  `json:"country"`
+		CountryCode string  `json:"country_code"`
+		Region      string  `json:"region"`
+		City        string  `json:"city"`
+		Latitude    float64 `json:"latitude"`
+		Longitude   float64 `json:"longitude"`
+		Timezone    struct {
+			ID string `json:"id"`
+		} `json:"timezone"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if !body.Success {
+		return nil, fmt.Errorf("ipwho.is: %s", body.Message)
+	}
+
+	return &LocationResponse{
+		Success:     true,
+		City:        body.City,
+		Region:      body.Region,
+		Country:     body.Country,
+		CountryCode: body.CountryCode,
+		Latitude:    body.Latitude,
+		Longitude:   body.Longitude,
+		Timezone:    body.Timezone.ID,
+		IP:          body.IP,
+		Source:      "ip",
+	}, nil
+}
+
+// --- ipinfo.io ---
+
+type ipInfoProvider struct{}
+
+func (p *ipInfoProvider) Name() string { return "ipinfo" }
+
+func (p *ipInfoProvider) Lookup(ip string) (*LocationResponse, error) {
+	url := "https://ipinfo.io/json"
+	if ip != "" {
+		url = "https://ipinfo.io/" + ip + "/json"
+	}
+	if token := os.Getenv("IPINFO_TOKEN"); token != "" {
+		url += "?token=" + token
+	}
+
+	resp, err := geoHTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ipinfo returned HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		IP       string `json:"ip"`
+		City     string `json:"city"`
+		Region   string `json:"region"`
+		Country  string `json:"country"`
+		Loc      string `json:"loc"`
+		Timezone string `json:"timezone"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	location := &LocationResponse{
+		Success:     true,
+		City:        body.City,
+		Region:      body.Region,
+		CountryCode: body.Country,
+		Timezone:    body.Timezone,
+		IP:          body.IP,
+		Source:      "ip",
+	}
+	if parts := strings.Split(body.Loc, ","); len(parts) == 2 {
+		fmt.Sscanf(body.Loc, "%f,%f", &location.Latitude, &location.Longitude)
+	}
+	return location, nil
+}
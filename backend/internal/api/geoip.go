@@ -0,0 +1,162 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
+)
+
+// geoLite2City maps the fields we need from a GeoLite2/GeoIP2 City record
+type geoLite2City struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+		TimeZone  string  `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+}
+
+// geoIPDB wraps the mmdb reader; opened lazily on first lookup so config
+// is loaded and a missing file doesn't block startup
+type geoIPDB struct {
+	mu     sync.Mutex
+	opened bool
+	reader *maxminddb.Reader
+}
+
+var globalGeoIP = &geoIPDB{}
+
+// ensureOpen opens (and if configured, downloads/refreshes) the mmdb file.
+// Failures are logged once and leave the database disabled.
+func (g *geoIPDB) ensureOpen() *maxminddb.Reader {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.opened {
+		return g.reader
+	}
+	g.opened = true
+
+	cfg := config.Get().GeoIP
+	if cfg.MMDBPath == "" {
+		return nil
+	}
+
+	if err := maybeDownloadMMDB(cfg); err != nil {
+		logging.Module("geoip").Warn("GeoIP database download failed", "error", err)
+	}
+
+	reader, err := maxminddb.Open(cfg.MMDBPath)
+	if err != nil {
+		logging.Module("geoip").Warn("Failed to open GeoIP database", "path", cfg.MMDBPath, "error", err)
+		return nil
+	}
+
+	logging.Module("geoip").Info("GeoIP database loaded", "path", cfg.MMDBPath)
+	g.reader = reader
+	return g.reader
+}
+
+// maybeDownloadMMDB fetches the mmdb file when it's missing, or stale and
+// refresh_days is set. Never downloads in offline mode.
+func maybeDownloadMMDB(cfg config.GeoIPConfig) error {
+	if cfg.DownloadURL == "" || offlineMode() {
+		return nil
+	}
+
+	info, err := os.Stat(cfg.MMDBPath)
+	if err == nil {
+		if cfg.RefreshDays <= 0 {
+			return nil
+		}
+		if time.Since(info.ModTime()) < time.Duration(cfg.RefreshDays)*24*time.Hour {
+			return nil
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	client := newOutboundClient(2 * time.Minute)
+	resp, err := client.Get(cfg.DownloadURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download returned HTTP %d", resp.StatusCode)
+	}
+
+	// Download to a temp file and rename, so a failed transfer never
+	// clobbers a working database
+	tmp := cfg.MMDBPath + ".download"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	out.Close()
+
+	return os.Rename(tmp, cfg.MMDBPath)
+}
+
+// lookupGeoIP resolves an IP against the local database. Returns nil when
+// the database isn't configured/loaded or has no record for the IP.
+func lookupGeoIP(ipStr string) *LocationResponse {
+	reader := globalGeoIP.ensureOpen()
+	if reader == nil {
+		return nil
+	}
+
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return nil
+	}
+
+	var record geoLite2City
+	if err := reader.Lookup(ip, &record); err != nil {
+		return nil
+	}
+	if record.Country.ISOCode == "" && record.Location.Latitude == 0 && record.Location.Longitude == 0 {
+		return nil
+	}
+
+	region := ""
+	if len(record.Subdivisions) > 0 {
+		region = record.Subdivisions[0].Names["en"]
+	}
+
+	return &LocationResponse{
+		Success:     true,
+		City:        record.City.Names["en"],
+		Region:      region,
+		Country:     record.Country.Names["en"],
+		CountryCode: record.Country.ISOCode,
+		Latitude:    record.Location.Latitude,
+		Longitude:   record.Location.Longitude,
+		Timezone:    record.Location.TimeZone,
+		IP:          ipStr,
+		Source:      "geoip",
+	}
+}
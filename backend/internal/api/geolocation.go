@@ -1,14 +1,14 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
 )
 
 // IPGeoResponse represents the response from ip-api.com
@@ -43,8 +43,18 @@ type LocationResponse struct {
 	Source      string  `json:"source"` // "ip" to indicate this is IP-based
 }
 
-// getClientIP extracts the real client IP, handling proxies
+// getClientIP extracts the real client IP, handling proxies. Forwarding
+// headers are only honored when the direct peer is a trusted proxy -
+// otherwise any client could spoof its IP with an X-Forwarded-For header.
 func getClientIP(c *gin.Context) string {
+	peer, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		peer = c.Request.RemoteAddr
+	}
+	if !isTrustedProxy(peer) {
+		return peer
+	}
+
 	// Check X-Forwarded-For first (for reverse proxies)
 	xff := c.GetHeader("X-Forwarded-For")
 	if xff != "" {
@@ -61,12 +71,8 @@ func getClientIP(c *gin.Context) string {
 		return strings.TrimSpace(xri)
 	}
 
-	// Fallback to RemoteAddr
-	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
-	if err != nil {
-		return c.Request.RemoteAddr
-	}
-	return host
+	// Fallback to the direct peer
+	return peer
 }
 
 // isPrivateIP checks if an IP is private/localhost
@@ -102,70 +108,72 @@ func isPrivateIP(ip string) bool {
 	return false
 }
 
-// IPGeolocationHandler returns location based on client IP
-func IPGeolocationHandler() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		clientIP := getClientIP(c)
-
-		// If running locally, we can't geolocate private IPs
-		// ip-api.com will use the server's public IP in this case
-		ipToLookup := clientIP
-		if isPrivateIP(clientIP) {
-			// Let ip-api.com auto-detect from the request
-			ipToLookup = ""
-		}
+// locateByIP resolves the client's location: local GeoIP database first
+// (no outbound call, no rate limit), then cached online lookups, then the
+// provider fallback chain - unless policy disables online lookups
+func locateByIP(c *gin.Context) (*LocationResponse, error) {
+	clientIP := getClientIP(c)
 
-		// Build the URL - ip-api.com is free for non-commercial use (45 req/min)
-		// Using HTTP because HTTPS requires paid plan
-		url := "http://ip-api.com/json/"
-		if ipToLookup != "" {
-			url += ipToLookup
+	if !isPrivateIP(clientIP) {
+		if location := lookupGeoIP(clientIP); location != nil {
+			return location, nil
 		}
+	}
+
+	if offlineMode() || !config.Get().GeoIP.OnlineFallback {
+		return nil, fmt.Errorf("no local GeoIP match and online lookup is disabled by policy")
+	}
+
+	// If running locally, we can't geolocate private IPs; the online
+	// providers auto-detect from the request in that case
+	ipToLookup := clientIP
+	if isPrivateIP(clientIP) {
+		ipToLookup = ""
+	}
 
-		// Make the request
-		httpClient := &http.Client{Timeout: 10 * time.Second}
-		resp, err := httpClient.Get(url)
+	// Cache lookups per IP so several clients don't burn through the
+	// free-tier rate limits
+	cacheKey := ipToLookup
+	if cacheKey == "" {
+		cacheKey = "self"
+	}
+	if location := geoCacheGet(cacheKey); location != nil {
+		return location, nil
+	}
+
+	// Try each provider in order until one answers
+	var lastErr error
+	for _, provider := range geoProviders() {
+		location, err := provider.Lookup(ipToLookup)
 		if err != nil {
-			c.JSON(http.StatusServiceUnavailable, LocationResponse{
-				Success: false,
-				Error:   "Failed to reach geolocation service",
-				Source:  "ip",
-			})
-			return
+			lastErr = err
+			continue
 		}
-		defer resp.Body.Close()
+		geoCacheSet(cacheKey, location)
+		return location, nil
+	}
 
-		var geoResp IPGeoResponse
-		if err := json.NewDecoder(resp.Body).Decode(&geoResp); err != nil {
-			c.JSON(http.StatusInternalServerError, LocationResponse{
-				Success: false,
-				Error:   "Failed to parse geolocation response",
-				Source:  "ip",
-			})
-			return
-		}
+	if lastErr != nil {
+		return nil, fmt.Errorf("geolocation failed: %w", lastErr)
+	}
+	return nil, fmt.Errorf("failed to reach geolocation service")
+}
 
-		// Check if ip-api returned an error
-		if geoResp.Status != "success" {
-			c.JSON(http.StatusOK, LocationResponse{
+// IPGeolocationHandler returns location based on client IP. A local
+// GeoLite2 database (geoip.mmdb_path) is consulted first; online
+// providers are only used as a fallback when online_fallback allows it
+// and offline mode is off.
+func IPGeolocationHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		location, err := locateByIP(c)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, LocationResponse{
 				Success: false,
-				Error:   fmt.Sprintf("Geolocation failed: %s", geoResp.Message),
+				Error:   err.Error(),
 				Source:  "ip",
 			})
 			return
 		}
-
-		c.JSON(http.StatusOK, LocationResponse{
-			Success:     true,
-			City:        geoResp.City,
-			Region:      geoResp.RegionName,
-			Country:     geoResp.Country,
-			CountryCode: geoResp.CountryCode,
-			Latitude:    geoResp.Lat,
-			Longitude:   geoResp.Lon,
-			Timezone:    geoResp.Timezone,
-			IP:          geoResp.Query,
-			Source:      "ip",
-		})
+		c.JSON(http.StatusOK, location)
 	}
 }
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// guardrailExcerptLength caps how much matched text the review log keeps
+const guardrailExcerptLength = 200
+
+// defaultRedaction replaces matched text when a redact rule has no
+// replacement of its own
+const defaultRedaction = "[redacted]"
+
+// compiledGuardrail pairs a configured rule with its compiled pattern
+type compiledGuardrail struct {
+	rule config.GuardrailRule
+	re   *regexp.Regexp
+}
+
+var (
+	guardrailRules     []compiledGuardrail
+	guardrailRulesOnce sync.Once
+)
+
+// loadGuardrails compiles the configured rules once; invalid patterns are
+// logged and skipped rather than disabling the rest
+func loadGuardrails() []compiledGuardrail {
+	guardrailRulesOnce.Do(func() {
+		logger := logging.Module("guardrails")
+		for _, rule := range config.Get().Guardrails.Rules {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				logger.Warn("Skipping guardrail rule with invalid pattern", "rule", rule.Name, "error", err)
+				continue
+			}
+			if rule.Action != "block" && rule.Action != "flag" && rule.Action != "redact" {
+				logger.Warn("Skipping guardrail rule with unknown action", "rule", rule.Name, "action", rule.Action)
+				continue
+			}
+			guardrailRules = append(guardrailRules, compiledGuardrail{rule: rule, re: re})
+		}
+	})
+	return guardrailRules
+}
+
+// guardrailsEnabled reports whether the pipeline should run at all
+func guardrailsEnabled() bool {
+	return config.Get().Guardrails.Enabled
+}
+
+// screenText runs the rules over one piece of text. It returns the
+// (possibly redacted) text and the name of a blocking rule, if any hit.
+// allowBlock is false on streamed output, where the text has already been
+// sent - block rules downgrade to flag there.
+func screenText(db *sql.DB, model, direction, text string, allowBlock bool) (string, string) {
+	for _, g := range loadGuardrails() {
+		match := g.re.FindString(text)
+		if match == "" {
+			continue
+		}
+
+		action := g.rule.Action
+		if action == "block" && !allowBlock {
+			action = "flag"
+		}
+
+		event := &models.GuardrailEvent{
+			Rule:      g.rule.Name,
+			Action:    action,
+			Direction: direction,
+			Model:     model,
+			Excerpt:   guardrailExcerpt(match),
+		}
+		if err := models.RecordGuardrailEvent(db, event); err != nil {
+			logging.Module("guardrails").Warn("Failed to record guardrail event", "error", err)
+		}
+
+		switch action {
+		case "block":
+			return text, g.rule.Name
+		case "redact":
+			replacement := g.rule.Replacement
+			if replacement == "" {
+				replacement = defaultRedaction
+			}
+			text = g.re.ReplaceAllString(text, replacement)
+		}
+	}
+	return text, ""
+}
+
+// guardrailExcerpt trims matched text for the review log
+func guardrailExcerpt(match string) string {
+	if len(match) > guardrailExcerptLength {
+		return match[:guardrailExcerptLength] + "..."
+	}
+	return match
+}
+
+// moderationCheck asks the configured moderation model whether a prompt
+// should be let through. Conservative on errors: an unreachable moderation
+// model never blocks anything, it just logs.
+func (s *OllamaService) moderationCheck(ctx context.Context, db *sql.DB, model, text string) (blocked bool) {
+	cfg := config.Get().Guardrails
+	if cfg.ModerationModel == "" {
+		return false
+	}
+
+	prompt := "You are a content moderator. Answer with exactly one word: FLAG if the following " +
+		"message requests harmful, dangerous, or clearly policy-violating content, otherwise OK.\n\n" + text
+
+	stream := false
+	req := &ollamaapi.GenerateRequest{Model: cfg.ModerationModel, Prompt: prompt, Stream: &stream}
+
+	var verdict string
+	err := s.client.Generate(ctx, req, func(resp ollamaapi.GenerateResponse) error {
+		verdict = resp.Response
+		return nil
+	})
+	if err != nil {
+		logging.Module("guardrails").Warn("Moderation model check failed", "error", err)
+		return false
+	}
+
+	if !strings.Contains(strings.ToUpper(verdict), "FLAG") {
+		return false
+	}
+
+	action := cfg.ModerationAction
+	if action != "block" {
+		action = "flag"
+	}
+	event := &models.GuardrailEvent{
+		Rule:      "moderation-model",
+		Action:    action,
+		Direction: "input",
+		Model:     model,
+		Excerpt:   guardrailExcerpt(text),
+	}
+	if err := models.RecordGuardrailEvent(db, event); err != nil {
+		logging.Module("guardrails").Warn("Failed to record guardrail event", "error", err)
+	}
+	return action == "block"
+}
+
+// screenChatInput runs the pipeline over the last user message of a chat
+// request, redacting in place. It returns false (after responding 403)
+// when a rule or the moderation model blocks the request.
+func (s *OllamaService) screenChatInput(c *gin.Context, db *sql.DB, req *ollamaapi.ChatRequest) bool {
+	if !guardrailsEnabled() {
+		return true
+	}
+
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role != "user" {
+			continue
+		}
+		sanitized, blockedBy := screenText(db, req.Model, "input", req.Messages[i].Content, true)
+		if blockedBy != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "blocked by guardrail: " + blockedBy})
+			return false
+		}
+		req.Messages[i].Content = sanitized
+
+		if s.moderationCheck(c.Request.Context(), db, req.Model, sanitized) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "blocked by moderation model"})
+			return false
+		}
+		break
+	}
+	return true
+}
+
+// screenGenerateInput is screenChatInput for the generate endpoint
+func (s *OllamaService) screenGenerateInput(c *gin.Context, db *sql.DB, req *ollamaapi.GenerateRequest) bool {
+	if !guardrailsEnabled() {
+		return true
+	}
+
+	sanitized, blockedBy := screenText(db, req.Model, "input", req.Prompt, true)
+	if blockedBy != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "blocked by guardrail: " + blockedBy})
+		return false
+	}
+	req.Prompt = sanitized
+
+	if s.moderationCheck(c.Request.Context(), db, req.Model, sanitized) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "blocked by moderation model"})
+		return false
+	}
+	return true
+}
+
+// screenStreamedOutput runs the rules over a fully streamed response. The
+// text is already on the wire, so hits are only flagged/logged.
+func screenStreamedOutput(db *sql.DB, model, text string) {
+	if !guardrailsEnabled() || text == "" {
+		return
+	}
+	screenText(db, model, "output", text, false)
+}
+
+// ListGuardrailEventsHandler is the admin review endpoint: recent blocks,
+// flags, and redactions (?rule= filters, ?limit= caps)
+func ListGuardrailEventsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+				limit = l
+			}
+		}
+
+		events, err := models.ListGuardrailEvents(db, c.Query("rule"), limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if events == nil {
+			events = []models.GuardrailEvent{}
+		}
+		c.JSON(http.StatusOK, gin.H{"events": events})
+	}
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+)
+
+// GPUInfo describes one detected GPU
+type GPUInfo struct {
+	Name      string `json:"name"`
+	VRAMBytes int64  `json:"vram_bytes"`
+	Vendor    string `json:"vendor"` // "nvidia", "amd", or "apple"
+}
+
+// HardwareInfo is what /system/hardware reports; the fit badges on
+// registry models are derived from it
+type HardwareInfo struct {
+	CPUModel      string    `json:"cpu_model,omitempty"`
+	CPUCores      int       `json:"cpu_cores"`
+	TotalRAMBytes int64     `json:"total_ram_bytes"`
+	FreeDiskBytes int64     `json:"free_disk_bytes"`
+	GPUs          []GPUInfo `json:"gpus"`
+	DetectedAt    time.Time `json:"detected_at"`
+}
+
+// gpuProbeTimeout bounds each vendor tool invocation
+const gpuProbeTimeout = 5 * time.Second
+
+var (
+	detectedHardware     *HardwareInfo
+	detectedHardwareOnce sync.Once
+)
+
+// detectHardware probes the machine once per process - CPU and RAM from
+// the OS, GPUs via nvidia-smi / rocm-smi / system_profiler, free disk on
+// the database filesystem
+func detectHardware() *HardwareInfo {
+	detectedHardwareOnce.Do(func() {
+		info := &HardwareInfo{
+			CPUCores:   runtime.NumCPU(),
+			DetectedAt: time.Now().UTC(),
+		}
+
+		info.CPUModel = detectCPUModel()
+		info.TotalRAMBytes = detectTotalRAM()
+		info.GPUs = detectGPUs()
+		if info.GPUs == nil {
+			info.GPUs = []GPUInfo{}
+		}
+
+		dbDir := filepath.Dir(config.Get().Database.Path)
+		if free, err := freeDiskBytes(dbDir); err == nil {
+			info.FreeDiskBytes = int64(free)
+		}
+
+		detectedHardware = info
+	})
+	return detectedHardware
+}
+
+// detectCPUModel reads the CPU model name where the OS exposes one
+func detectCPUModel() string {
+	if runtime.GOOS == "darwin" {
+		if out, err := probeCommand("sysctl", "-n", "machdep.cpu.brand_string"); err == nil {
+			return strings.TrimSpace(out)
+		}
+		return ""
+	}
+
+	data, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "model name") {
+			if idx := strings.Index(line, ":"); idx >= 0 {
+				return strings.TrimSpace(line[idx+1:])
+			}
+		}
+	}
+	return ""
+}
+
+// detectTotalRAM returns installed memory in bytes, or 0 when unknown
+func detectTotalRAM() int64 {
+	if runtime.GOOS == "darwin" {
+		if out, err := probeCommand("sysctl", "-n", "hw.memsize"); err == nil {
+			if bytes, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64); err == nil {
+				return bytes
+			}
+		}
+		return 0
+	}
+
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "MemTotal:") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 {
+				if kb, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+					return kb * 1024
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// detectGPUs tries each vendor tool in turn; machines without the tools
+// simply report no GPUs
+func detectGPUs() []GPUInfo {
+	if gpus := detectNvidiaGPUs(); len(gpus) > 0 {
+		return gpus
+	}
+	if gpus := detectAMDGPUs(); len(gpus) > 0 {
+		return gpus
+	}
+	return detectAppleGPUs()
+}
+
+// detectNvidiaGPUs parses nvidia-smi CSV output: one "name, memory" line
+// per GPU with memory in MiB
+func detectNvidiaGPUs() []GPUInfo {
+	out, err := probeCommand("nvidia-smi", "--query-gpu=name,memory.total", "--format=csv,noheader,nounits")
+	if err != nil {
+		return nil
+	}
+
+	var gpus []GPUInfo
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		parts := strings.Split(line, ",")
+		if len(parts) != 2 {
+			continue
+		}
+		mib, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil {
+			continue
+		}
+		gpus = append(gpus, GPUInfo{
+			Name:      strings.TrimSpace(parts[0]),
+			VRAMBytes: mib * 1024 * 1024,
+			Vendor:    "nvidia",
+		})
+	}
+	return gpus
+}
+
+// detectAMDGPUs parses rocm-smi VRAM output ("Total Memory (B): N" lines)
+func detectAMDGPUs() []GPUInfo {
+	out, err := probeCommand("rocm-smi", "--showmeminfo", "vram", "--csv")
+	if err != nil {
+		return nil
+	}
+
+	var gpus []GPUInfo
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		// CSV rows look like: card0,17163091968,...
+		if !strings.HasPrefix(line, "card") {
+			continue
+		}
+		parts := strings.Split(line, ",")
+		if len(parts) < 2 {
+			continue
+		}
+		bytes, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil {
+			continue
+		}
+		gpus = append(gpus, GPUInfo{
+			Name:      strings.TrimSpace(parts[0]),
+			VRAMBytes: bytes,
+			Vendor:    "amd",
+		})
+	}
+	return gpus
+}
+
+// detectAppleGPUs reports Apple Silicon's unified memory as VRAM - Metal
+// can address nearly all of it
+func detectAppleGPUs() []GPUInfo {
+	if runtime.GOOS != "darwin" {
+		return nil
+	}
+
+	out, err := probeCommand("sysctl", "-n", "machdep.cpu.brand_string")
+	if err != nil || !strings.Contains(out, "Apple") {
+		return nil
+	}
+	return []GPUInfo{{
+		Name:      strings.TrimSpace(out),
+		VRAMBytes: detectTotalRAM(),
+		Vendor:    "apple",
+	}}
+}
+
+// probeCommand runs a detection tool with a short timeout
+func probeCommand(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gpuProbeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	return string(out), err
+}
+
+// modelFitBadge classifies whether a model of the given size can run on
+// this machine: "fits" comfortably in GPU or system memory, "tight" when
+// it would squeeze in, "wont-fit" otherwise. Unknown sizes return "".
+func modelFitBadge(sizeBytes int64) string {
+	if sizeBytes <= 0 {
+		return ""
+	}
+
+	hw := detectHardware()
+	budget := hw.TotalRAMBytes
+	for _, gpu := range hw.GPUs {
+		if gpu.VRAMBytes > budget {
+			budget = gpu.VRAMBytes
+		}
+	}
+	if budget == 0 {
+		return ""
+	}
+
+	// Leave headroom for the KV cache and the rest of the system
+	switch {
+	case sizeBytes <= budget*7/10:
+		return "fits"
+	case sizeBytes <= budget*9/10:
+		return "tight"
+	default:
+		return "wont-fit"
+	}
+}
+
+// remoteModelSize picks the size to judge a registry model by: its
+// "latest" tag when sized, otherwise the smallest known tag
+func remoteModelSize(m *RemoteModel) int64 {
+	if size, ok := m.TagSizes["latest"]; ok && size > 0 {
+		return size
+	}
+	var smallest int64
+	for _, size := range m.TagSizes {
+		if size > 0 && (smallest == 0 || size < smallest) {
+			smallest = size
+		}
+	}
+	return smallest
+}
+
+// HardwareHandler reports detected hardware capabilities
+func HardwareHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, detectHardware())
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// minFreeDiskBytes is the free-space threshold below which readiness degrades;
+// SQLite writes and model metadata syncs start failing well before zero
+const minFreeDiskBytes = 100 * 1024 * 1024 // 100 MB
+
+// DependencyStatus describes one dependency check in a readiness response
+type DependencyStatus struct {
+	Status  string `json:"status"` // "ok", "degraded" or "down"
+	Message string `json:"message,omitempty"`
+}
+
+// HealthzHandler is a pure liveness probe: it answers 200 as long as the
+// process is up and able to serve requests. Dependency health belongs in
+// /readyz so a slow Ollama doesn't get the pod restarted.
+func HealthzHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// ReadyzHandler checks the dependencies the server needs to do useful work:
+// SQLite connectivity, the Ollama heartbeat, and free disk space. Chrome is
+// reported but never fails readiness since the fetcher degrades gracefully
+// without it. Returns 200 when ready, 503 otherwise.
+func ReadyzHandler(db *sql.DB, ollamaService *OllamaService, dbPath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		checks := gin.H{}
+		ready := true
+
+		// SQLite: a real round-trip, not just a pool check
+		dbStatus := DependencyStatus{Status: "ok"}
+		var one int
+		if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+			dbStatus = DependencyStatus{Status: "down", Message: err.Error()}
+			ready = false
+		}
+		checks["database"] = dbStatus
+
+		// Ollama: heartbeat against the configured instance
+		ollamaStatus := DependencyStatus{Status: "ok"}
+		if ollamaService == nil {
+			ollamaStatus = DependencyStatus{Status: "down", Message: "Ollama service not initialized"}
+			ready = false
+		} else if err := ollamaService.Client().Heartbeat(ctx); err != nil {
+			ollamaStatus = DependencyStatus{Status: "down", Message: err.Error()}
+			ready = false
+		}
+		checks["ollama"] = ollamaStatus
+
+		// Disk space on the database volume
+		diskStatus := DependencyStatus{Status: "ok"}
+		if free, err := freeDiskBytes(filepath.Dir(dbPath)); err != nil {
+			diskStatus = DependencyStatus{Status: "degraded", Message: err.Error()}
+		} else if free < minFreeDiskBytes {
+			diskStatus = DependencyStatus{Status: "down", Message: "less than 100MB free on database volume"}
+			ready = false
+		}
+		checks["disk"] = diskStatus
+
+		// Chrome: informational only - the fetcher falls back to curl/wget/native
+		chromeStatus := DependencyStatus{Status: "ok"}
+		if !GetFetcher().HasChrome() {
+			chromeStatus = DependencyStatus{Status: "degraded", Message: "headless Chrome not available; JS-rendered pages won't fetch"}
+		}
+		checks["chrome"] = chromeStatus
+
+		status := "ready"
+		code := http.StatusOK
+		if !ready {
+			status = "not ready"
+			code = http.StatusServiceUnavailable
+		}
+
+		c.JSON(code, gin.H{
+			"status": status,
+			"checks": checks,
+		})
+	}
+}
+
+// freeDiskBytes returns the free space available to the process on the
+// filesystem containing dir
+func freeDiskBytes(dir string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
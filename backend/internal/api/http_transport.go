@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"vessel-backend/internal/config"
+)
+
+// All outbound HTTP traffic shares one tuned transport so connections
+// are pooled across handlers instead of each call site paying a fresh
+// TCP/TLS handshake (and leaking idle sockets under load).
+var (
+	sharedTransportOnce sync.Once
+	sharedTransportVal  *http.Transport
+)
+
+// sharedTransport returns the process-wide transport, building it on
+// first use so the configured proxy (fetcher.proxy_url) is picked up
+// after the config has loaded
+func sharedTransport() *http.Transport {
+	sharedTransportOnce.Do(func() {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConns = 100
+		transport.MaxIdleConnsPerHost = 10
+		transport.IdleConnTimeout = 90 * time.Second
+
+		if proxy := config.Get().Fetcher.ProxyURL; proxy != "" {
+			if proxyURL, err := url.Parse(proxy); err == nil {
+				transport.Proxy = http.ProxyURL(proxyURL)
+			}
+		}
+		sharedTransportVal = transport
+	})
+	return sharedTransportVal
+}
+
+// sharedRoundTripper defers to sharedTransport on each call, letting
+// package-level clients be declared before the config is loaded
+type sharedRoundTripper struct{}
+
+func (sharedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return sharedTransport().RoundTrip(req)
+}
+
+// newOutboundClient returns a client on the shared transport with the
+// given overall timeout; zero means no timeout (streaming responses)
+func newOutboundClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: sharedTransport(), Timeout: timeout}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"vessel-backend/internal/logging"
+)
+
+// Injection heuristics for fetched outside content. A page that tells
+// the model to ignore its instructions is the classic indirect prompt
+// injection; the chat template markers catch attempts to fake a role
+// boundary. Heuristics, not guarantees - the point is to flag the
+// obvious cases and leave a trail.
+var injectionPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"override-instructions", regexp.MustCompile(`(?i)\b(ignore|disregard|forget)\b.{0,40}\b(previous|prior|above|all|earlier)\b.{0,40}\b(instruction|prompt|rule|context)`)},
+	{"role-reassignment", regexp.MustCompile(`(?i)\byou are (now|no longer)\b.{0,80}\b(assistant|ai|model|chatbot)\b`)},
+	{"new-instructions", regexp.MustCompile(`(?i)\b(new|real|actual|true) (instructions|system prompt|task)\s*:`)},
+	{"system-prompt-probe", regexp.MustCompile(`(?i)\b(reveal|repeat|print|output)\b.{0,40}\b(system prompt|initial instructions)\b`)},
+	{"template-markers", regexp.MustCompile(`<\|im_start\|>|<\|im_end\|>|\[INST\]|\[/INST\]|<<SYS>>`)},
+	{"hidden-html", regexp.MustCompile(`(?i)<[^>]+(display\s*:\s*none|visibility\s*:\s*hidden|font-size\s*:\s*0)[^>]*>[^<]{20,}`)},
+}
+
+// injectionExcerptRunes caps how much of the matched text goes into the
+// audit trail and response
+const injectionExcerptRunes = 120
+
+// InjectionDetection is one flagged pattern in fetched content
+type InjectionDetection struct {
+	Pattern string `json:"pattern"`
+	Excerpt string `json:"excerpt"`
+}
+
+// scanForInjection runs the heuristics over fetched content and returns
+// every pattern that matched, with a short excerpt for the audit trail
+func scanForInjection(content string) []InjectionDetection {
+	detections := []InjectionDetection{}
+	for _, entry := range injectionPatterns {
+		match := entry.pattern.FindString(content)
+		if match == "" {
+			continue
+		}
+		excerpt := match
+		if runes := []rune(excerpt); len(runes) > injectionExcerptRunes {
+			excerpt = string(runes[:injectionExcerptRunes]) + "…"
+		}
+		detections = append(detections, InjectionDetection{Pattern: entry.name, Excerpt: excerpt})
+	}
+	return detections
+}
+
+// stripInjections removes every line a pattern matched on, so flagged
+// instructions never reach the model context
+func stripInjections(content string) string {
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		flagged := false
+		for _, entry := range injectionPatterns {
+			if entry.pattern.MatchString(line) {
+				flagged = true
+				break
+			}
+		}
+		if !flagged {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// recordToolAudit appends one row to the tool-call audit trail
+func recordToolAudit(db *sql.DB, tool, target, detail string) {
+	_, err := db.Exec(`
+		INSERT INTO tool_audit (id, tool, target, detail)
+		VALUES (?, ?, ?, ?)`, uuid.New().String(), tool, target, detail)
+	if err != nil {
+		logging.Module("tools").Warn("Failed to record tool audit entry", "tool", tool, "error", err)
+	}
+}
+
+// ListToolAuditHandler returns the tool-call audit trail, newest first
+// (?tool=, ?limit=)
+func ListToolAuditHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 100
+		if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+			limit = l
+		}
+
+		query := `SELECT id, tool, target, detail, created_at FROM tool_audit`
+		args := []interface{}{}
+		if tool := c.Query("tool"); tool != "" {
+			query += ` WHERE tool = ?`
+			args = append(args, tool)
+		}
+		query += ` ORDER BY created_at DESC LIMIT ?`
+		args = append(args, limit)
+
+		rows, err := db.Query(query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer rows.Close()
+
+		type auditEntry struct {
+			ID        string `json:"id"`
+			Tool      string `json:"tool"`
+			Target    string `json:"target,omitempty"`
+			Detail    string `json:"detail,omitempty"`
+			CreatedAt string `json:"created_at"`
+		}
+		entries := []auditEntry{}
+		for rows.Next() {
+			var e auditEntry
+			if err := rows.Scan(&e.ID, &e.Tool, &e.Target, &e.Detail, &e.CreatedAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			entries = append(entries, e)
+		}
+		c.JSON(http.StatusOK, gin.H{"entries": entries})
+	}
+}
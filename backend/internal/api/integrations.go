@@ -0,0 +1,139 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+)
+
+// slackTimestampSkew is how stale a signed Slack request may be before it
+// is rejected as a possible replay
+const slackTimestampSkew = 5 * time.Minute
+
+// slackSearchResults caps how many chats a slash command response lists
+const slackSearchResults = 5
+
+// SlackCommandHandler handles a Slack slash command (e.g. /vessel <query>)
+// by searching the user's chats and replying with matching chat titles and
+// snippets. Point the slash command's request URL at this endpoint.
+func SlackCommandHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+
+		if secret := config.Get().Integrations.SlackSigningSecret; secret != "" {
+			if !verifySlackSignature(c.Request, secret, body) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid Slack signature"})
+				return
+			}
+		}
+
+		form, err := parseFormBody(string(body))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid form body"})
+			return
+		}
+
+		query := strings.TrimSpace(form["text"])
+		if query == "" {
+			c.JSON(http.StatusOK, gin.H{
+				"response_type": "ephemeral",
+				"text":          "Usage: " + form["command"] + " <search terms>",
+			})
+			return
+		}
+
+		results, err := searchLocal(db, query, slackSearchResults)
+		if err != nil {
+			c.JSON(http.StatusOK, gin.H{
+				"response_type": "ephemeral",
+				"text":          "Search failed: " + err.Error(),
+			})
+			return
+		}
+		if len(results) == 0 {
+			c.JSON(http.StatusOK, gin.H{
+				"response_type": "ephemeral",
+				"text":          "No chats matching \"" + query + "\".",
+			})
+			return
+		}
+
+		var lines []string
+		for _, result := range results {
+			line := "• *" + result.Title + "*"
+			if result.Snippet != "" {
+				line += " — " + result.Snippet
+			}
+			lines = append(lines, line)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"response_type": "ephemeral",
+			"text":          fmt.Sprintf("Chats matching \"%s\":\n%s", query, strings.Join(lines, "\n")),
+		})
+	}
+}
+
+// verifySlackSignature checks Slack's v0 request signature: HMAC-SHA256 of
+// "v0:<timestamp>:<body>" with the signing secret, with a freshness window
+// against replays
+func verifySlackSignature(r *http.Request, secret string, body []byte) bool {
+	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+	signature := r.Header.Get("X-Slack-Signature")
+	if timestamp == "" || signature == "" {
+		return false
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := time.Since(time.Unix(ts, 0))
+	if age > slackTimestampSkew || age < -slackTimestampSkew {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// parseFormBody decodes an application/x-www-form-urlencoded body into a
+// map. Parsed by hand because signature verification already consumed the
+// request body.
+func parseFormBody(body string) (map[string]string, error) {
+	form := make(map[string]string)
+	for _, pair := range strings.Split(body, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		decodedKey, err := url.QueryUnescape(key)
+		if err != nil {
+			return nil, err
+		}
+		decodedValue, err := url.QueryUnescape(value)
+		if err != nil {
+			return nil, err
+		}
+		form[decodedKey] = decodedValue
+	}
+	return form, nil
+}
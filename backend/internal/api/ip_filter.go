@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
+)
+
+// parseCIDRs turns a config list of CIDRs into networks; bare IPs are
+// treated as single-host networks and unparseable entries are logged
+// and skipped so one typo doesn't lock out the whole server
+func parseCIDRs(entries []string) []*net.IPNet {
+	nets := []*net.IPNet{}
+	for _, entry := range entries {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			nets = append(nets, network)
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		logging.Module("ipfilter").Warn("Ignoring unparseable CIDR in config", "entry", entry)
+	}
+	return nets
+}
+
+// ipInNets reports whether ip falls in any of the networks
+func ipInNets(ip string, nets []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range nets {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTrustedProxy reports whether forwarding headers from this peer are
+// believable. With trusted_proxies configured only those CIDRs qualify;
+// without it, private and loopback peers are trusted, matching the
+// common reverse-proxy-on-the-same-box setup.
+func isTrustedProxy(peer string) bool {
+	trusted := config.Get().Server.TrustedProxies
+	if len(trusted) == 0 {
+		return isPrivateIP(peer)
+	}
+	return ipInNets(peer, parseCIDRs(trusted))
+}
+
+// IPFilterMiddleware enforces the configured allow/deny lists against
+// the resolved client IP for every request. Deny wins over allow; an
+// empty allow list admits everyone not denied. A no-op without lists.
+func IPFilterMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.Get().Server
+		if len(cfg.AllowIPs) == 0 && len(cfg.DenyIPs) == 0 {
+			c.Next()
+			return
+		}
+
+		ip := getClientIP(c)
+		if ipInNets(ip, parseCIDRs(cfg.DenyIPs)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+		if len(cfg.AllowIPs) > 0 && !ipInNets(ip, parseCIDRs(cfg.AllowIPs)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+		c.Next()
+	}
+}
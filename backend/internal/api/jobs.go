@@ -0,0 +1,375 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/database"
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// jobPollInterval is how often idle workers check for due jobs
+const jobPollInterval = 3 * time.Second
+
+// jobWorkerCount is how many jobs can run concurrently
+const jobWorkerCount = 2
+
+// jobRetryBackoff is multiplied by the attempt number between retries
+const jobRetryBackoff = 30 * time.Second
+
+// JobHandlerFunc executes one job; a non-nil error marks the attempt as
+// failed and triggers a retry while attempts remain
+type JobHandlerFunc func(ctx context.Context, job *models.Job) error
+
+// deferredJobError is returned by a handler that cannot run yet (for
+// example a pull outside its scheduling window); the job goes back in
+// the queue for the given time without consuming an attempt
+type deferredJobError struct {
+	Until  time.Time
+	Reason string
+}
+
+func (e *deferredJobError) Error() string {
+	return e.Reason
+}
+
+// jobQueue runs the persistent background queue: a handler registry, a
+// small worker pool polling the jobs table, and cancel functions for
+// in-flight jobs
+type jobQueue struct {
+	db       *sql.DB
+	mu       sync.Mutex
+	handlers map[string]JobHandlerFunc
+	running  map[string]context.CancelFunc
+}
+
+var globalJobQueue *jobQueue
+
+// StartJobQueue initializes the queue and launches its workers. Jobs left
+// running by an unclean shutdown are re-queued first.
+func StartJobQueue(db *sql.DB) {
+	globalJobQueue = &jobQueue{
+		db:       db,
+		handlers: make(map[string]JobHandlerFunc),
+		running:  make(map[string]context.CancelFunc),
+	}
+
+	if err := models.ResetRunningJobs(db); err != nil {
+		logging.Module("jobs").Warn("Failed to reset interrupted jobs", "error", err)
+	}
+
+	for i := 0; i < jobWorkerCount; i++ {
+		go globalJobQueue.worker()
+	}
+}
+
+// RegisterJobHandler binds a job kind to the function that executes it
+func RegisterJobHandler(kind string, fn JobHandlerFunc) {
+	if globalJobQueue == nil {
+		return
+	}
+	globalJobQueue.mu.Lock()
+	globalJobQueue.handlers[kind] = fn
+	globalJobQueue.mu.Unlock()
+}
+
+// EnqueueJob adds a job for the workers to pick up
+func EnqueueJob(kind string, payload json.RawMessage, maxAttempts int) (*models.Job, error) {
+	if globalJobQueue == nil {
+		return nil, fmt.Errorf("job queue not started")
+	}
+	return models.CreateJob(globalJobQueue.db, kind, payload, maxAttempts, time.Now())
+}
+
+// ScheduleRecurringJob enqueues a job of the given kind every interval,
+// skipping ticks where one is still queued or running - the cron side of
+// the queue
+func ScheduleRecurringJob(kind string, payload json.RawMessage, interval time.Duration) {
+	if globalJobQueue == nil || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if globalJobQueue.hasPending(kind) {
+				continue
+			}
+			if _, err := EnqueueJob(kind, payload, 1); err != nil {
+				logging.Module("jobs").Warn("Failed to enqueue recurring job", "kind", kind, "error", err)
+			}
+		}
+	}()
+}
+
+// hasPending reports whether a job of the kind is queued or running
+func (q *jobQueue) hasPending(kind string) bool {
+	for _, status := range []string{"queued", "running"} {
+		jobs, err := models.ListJobs(q.db, kind, status, 1)
+		if err == nil && len(jobs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// worker polls for due jobs and runs them one at a time
+func (q *jobQueue) worker() {
+	for {
+		job, err := models.ClaimNextJob(q.db)
+		if err != nil {
+			logging.Module("jobs").Warn("Failed to claim job", "error", err)
+		}
+		if job == nil {
+			time.Sleep(jobPollInterval)
+			continue
+		}
+		q.run(job)
+	}
+}
+
+// run executes one claimed job and records the outcome
+func (q *jobQueue) run(job *models.Job) {
+	logger := logging.Module("jobs")
+
+	q.mu.Lock()
+	handler, ok := q.handlers[job.Kind]
+	q.mu.Unlock()
+	if !ok {
+		logger.Warn("No handler registered for job kind", "kind", job.Kind, "job", job.ID)
+		if err := models.FinishJob(q.db, job.ID, "failed", "no handler registered for kind "+job.Kind); err != nil {
+			logger.Warn("Failed to record job outcome", "job", job.ID, "error", err)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.mu.Lock()
+	q.running[job.ID] = cancel
+	q.mu.Unlock()
+	defer func() {
+		cancel()
+		q.mu.Lock()
+		delete(q.running, job.ID)
+		q.mu.Unlock()
+	}()
+
+	logger.Info("Job started", "kind", job.Kind, "job", job.ID, "attempt", job.Attempts)
+	err := handler(ctx, job)
+
+	var deferred *deferredJobError
+	switch {
+	case errors.As(err, &deferred):
+		logger.Info("Job deferred", "kind", job.Kind, "job", job.ID, "until", deferred.Until, "reason", deferred.Reason)
+		err = models.DeferJob(q.db, job.ID, deferred.Reason, deferred.Until)
+	case err == nil:
+		logger.Info("Job succeeded", "kind", job.Kind, "job", job.ID)
+		err = models.FinishJob(q.db, job.ID, "succeeded", "")
+	case ctx.Err() != nil:
+		logger.Info("Job cancelled", "kind", job.Kind, "job", job.ID)
+		err = models.FinishJob(q.db, job.ID, "cancelled", "")
+	case job.Attempts < job.MaxAttempts:
+		retryAt := time.Now().Add(time.Duration(job.Attempts) * jobRetryBackoff)
+		logger.Warn("Job failed, will retry", "kind", job.Kind, "job", job.ID, "error", err)
+		err = models.RequeueJob(q.db, job.ID, err.Error(), retryAt)
+	default:
+		logger.Warn("Job failed", "kind", job.Kind, "job", job.ID, "error", err)
+		err = models.FinishJob(q.db, job.ID, "failed", err.Error())
+	}
+	if err != nil {
+		logger.Warn("Failed to record job outcome", "job", job.ID, "error", err)
+	}
+}
+
+// cancelRunning cancels a running job's context; returns false when the
+// job is not currently running on this instance
+func (q *jobQueue) cancelRunning(id string) bool {
+	q.mu.Lock()
+	cancel, ok := q.running[id]
+	q.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// registerBuiltinJobs wires the job kinds backed by existing services:
+// registry sync, model pulls, and database backups
+func registerBuiltinJobs(db *sql.DB, ollamaService *OllamaService, modelRegistry *ModelRegistryService) {
+	RegisterJobHandler("registry-sync", func(ctx context.Context, job *models.Job) error {
+		_, err := modelRegistry.SyncModels(ctx, false)
+		return err
+	})
+
+	RegisterJobHandler("backup", func(ctx context.Context, job *models.Job) error {
+		var payload struct {
+			Path string `json:"path"`
+		}
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		if payload.Path == "" {
+			return fmt.Errorf("payload requires a path")
+		}
+		if err := database.BackupTo(db, payload.Path); err != nil {
+			notifyEvent("Vessel backup failed", err.Error())
+			return err
+		}
+		EmitEvent(EventBackupCompleted, map[string]interface{}{"filename": payload.Path})
+		return nil
+	})
+
+	if ollamaService != nil {
+		RegisterJobHandler("model-pull", func(ctx context.Context, job *models.Job) error {
+			var payload struct {
+				Model string `json:"model"`
+			}
+			if err := json.Unmarshal(job.Payload, &payload); err != nil {
+				return fmt.Errorf("invalid payload: %w", err)
+			}
+			if payload.Model == "" {
+				return fmt.Errorf("payload requires a model")
+			}
+			if now := time.Now(); !pullWindowOpen(now) {
+				return &deferredJobError{Until: nextPullWindowOpen(now), Reason: "waiting for pull window"}
+			}
+			err := ollamaService.throttledPull(ctx, payload.Model)
+			if err == nil {
+				EmitEvent(EventModelPullFinished, map[string]interface{}{"model": payload.Model})
+			}
+			return err
+		})
+
+		RegisterJobHandler("embed-batch", func(ctx context.Context, job *models.Job) error {
+			var req EmbedBatchRequest
+			if err := json.Unmarshal(job.Payload, &req); err != nil {
+				return fmt.Errorf("invalid payload: %w", err)
+			}
+			if req.Model == "" || len(req.Texts) == 0 {
+				return fmt.Errorf("payload requires a model and texts")
+			}
+			defer cleanupEmbedBatches(db, time.Hour)
+			return ollamaService.runEmbedBatch(ctx, job.ID, &req)
+		})
+	}
+}
+
+// JobEnqueueRequest is the body for creating a job via the API
+type JobEnqueueRequest struct {
+	Kind        string          `json:"kind" binding:"required"`
+	Payload     json.RawMessage `json:"payload"`
+	MaxAttempts int             `json:"max_attempts"`
+}
+
+// EnqueueJobHandler creates a job of a registered kind
+func EnqueueJobHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req JobEnqueueRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		if globalJobQueue != nil {
+			globalJobQueue.mu.Lock()
+			_, known := globalJobQueue.handlers[req.Kind]
+			globalJobQueue.mu.Unlock()
+			if !known {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unknown job kind: " + req.Kind})
+				return
+			}
+		}
+
+		job, err := EnqueueJob(req.Kind, req.Payload, req.MaxAttempts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, job)
+	}
+}
+
+// ListJobsHandler returns job history, newest first (?kind=, ?status=, ?limit=)
+func ListJobsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+				limit = l
+			}
+		}
+
+		jobs, err := models.ListJobs(db, c.Query("kind"), c.Query("status"), limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if jobs == nil {
+			jobs = []models.Job{}
+		}
+		c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+	}
+}
+
+// GetJobHandler returns one job's status
+func GetJobHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := models.GetJob(db, c.Param("id"))
+		if err != nil {
+			if err.Error() == "job not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+// CancelJobHandler cancels a queued job, or interrupts it if running
+func CancelJobHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		if globalJobQueue != nil && globalJobQueue.cancelRunning(id) {
+			c.JSON(http.StatusOK, gin.H{"status": "cancelling"})
+			return
+		}
+
+		if err := models.CancelQueuedJob(db, id); err != nil {
+			if err.Error() == "job not queued" {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "cancelled"})
+	}
+}
+
+// RetryJobHandler re-queues a failed or cancelled job
+func RetryJobHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.RetryJob(db, c.Param("id")); err != nil {
+			if err.Error() == "job not failed or cancelled" {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "queued"})
+	}
+}
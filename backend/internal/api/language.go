@@ -0,0 +1,125 @@
+package api
+
+import (
+	"strings"
+	"unicode"
+)
+
+// latinStopwords tells apart the Latin-script languages we can
+// reasonably distinguish without a real classifier: a handful of very
+// frequent function words per language, scored by hit count
+var latinStopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "was", "you", "that", "with", "have", "this", "what", "how"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ich", "ein", "eine", "mit", "wie", "für"},
+	"fr": {"le", "la", "les", "est", "et", "je", "une", "que", "pas", "pour", "vous", "dans"},
+	"es": {"el", "la", "los", "es", "y", "que", "una", "por", "para", "como", "con", "pero"},
+	"it": {"il", "la", "che", "è", "e", "di", "una", "per", "non", "sono", "con", "come"},
+	"pt": {"o", "que", "é", "e", "uma", "para", "não", "com", "um", "os", "como", "mais"},
+	"nl": {"de", "het", "een", "en", "is", "niet", "dat", "van", "ik", "voor", "met", "hoe"},
+}
+
+// detectLanguage guesses the primary language of text as an ISO 639-1
+// code, or returns empty when unsure. Languages with distinctive scripts
+// are identified by rune ranges; Latin-script languages by stopword
+// frequency. Good enough for listing filters and translation defaults -
+// not a substitute for a real classifier.
+func detectLanguage(text string) string {
+	var latin, han, kana, hangul, cyrillic, arabic, hebrew, greek, devanagari, thai, letters int
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Arabic, r):
+			arabic++
+		case unicode.Is(unicode.Hebrew, r):
+			hebrew++
+		case unicode.Is(unicode.Greek, r):
+			greek++
+		case unicode.Is(unicode.Devanagari, r):
+			devanagari++
+		case unicode.Is(unicode.Thai, r):
+			thai++
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	// Kana only appears in Japanese, which freely mixes in Han; bare Han
+	// is Chinese
+	switch {
+	case kana > 0 && (kana+han)*2 > letters:
+		return "ja"
+	case han*2 > letters:
+		return "zh"
+	case hangul*2 > letters:
+		return "ko"
+	case cyrillic*2 > letters:
+		return "ru"
+	case arabic*2 > letters:
+		return "ar"
+	case hebrew*2 > letters:
+		return "he"
+	case greek*2 > letters:
+		return "el"
+	case devanagari*2 > letters:
+		return "hi"
+	case thai*2 > letters:
+		return "th"
+	}
+
+	if latin*2 <= letters {
+		return ""
+	}
+	return detectLatinLanguage(text)
+}
+
+// detectLatinLanguage scores the text's words against each language's
+// stopword list and returns the clear winner, or empty on a tie or when
+// too few stopwords matched to call it
+func detectLatinLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	scores := map[string]int{}
+	for lang, stopwords := range latinStopwords {
+		set := make(map[string]bool, len(stopwords))
+		for _, w := range stopwords {
+			set[w] = true
+		}
+		for _, w := range words {
+			if set[w] {
+				scores[lang]++
+			}
+		}
+	}
+
+	best, runnerUp, bestLang := 0, 0, ""
+	for lang, score := range scores {
+		if score > best {
+			best, runnerUp, bestLang = score, best, lang
+		} else if score > runnerUp {
+			runnerUp = score
+		}
+	}
+	if best < 2 || best == runnerUp {
+		return ""
+	}
+	return bestLang
+}
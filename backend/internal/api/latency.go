@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ollama/ollama/api"
+)
+
+// LatencyBreakdown is attached to the final frame of a streamed generation
+// so the client can show "queued 3s, generated at 42 tok/s"
+type LatencyBreakdown struct {
+	QueueMs         int64   `json:"queue_ms"`
+	FirstTokenMs    int64   `json:"first_token_ms"`
+	TotalMs         int64   `json:"total_ms"`
+	TokensPerSecond float64 `json:"tokens_per_second"`
+}
+
+// latencyBreakdown derives the breakdown from wall-clock measurements and
+// the final Ollama metrics. Queue wait is approximated by the load
+// duration Ollama reports: the time spent waiting for the model to be
+// ready before any evaluation started.
+func latencyBreakdown(firstToken, total time.Duration, metrics api.Metrics) LatencyBreakdown {
+	tokensPerSecond := 0.0
+	if metrics.EvalDuration > 0 {
+		tokensPerSecond = float64(metrics.EvalCount) / metrics.EvalDuration.Seconds()
+	}
+	return LatencyBreakdown{
+		QueueMs:         metrics.LoadDuration.Milliseconds(),
+		FirstTokenMs:    firstToken.Milliseconds(),
+		TotalMs:         total.Milliseconds(),
+		TokensPerSecond: tokensPerSecond,
+	}
+}
+
+// appendLatency injects a "latency" object into an already-marshaled
+// response frame. On any decoding trouble the frame passes through
+// unchanged rather than corrupting the stream.
+func appendLatency(frame []byte, latency LatencyBreakdown) []byte {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(frame, &decoded); err != nil {
+		return frame
+	}
+	decoded["latency"] = latency
+
+	enriched, err := json.Marshal(decoded)
+	if err != nil {
+		return frame
+	}
+	return enriched
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Body size caps per route class. Clients shouldn't be trusted to keep
+// payloads reasonable - a runaway sync push or a multi-hundred-MB "message"
+// would otherwise be read fully into memory.
+const (
+	// smallBodyLimit covers plain JSON control requests (search, fetch, tools)
+	smallBodyLimit = 256 * 1024
+	// attachmentBodyLimit covers messages, which may carry base64 attachments
+	attachmentBodyLimit = 32 * 1024 * 1024
+	// syncBodyLimit covers sync pushes, which batch many chats and messages
+	syncBodyLimit = 32 * 1024 * 1024
+	// restoreBodyLimit covers uploaded database backups, which carry the
+	// full history including attachments
+	restoreBodyLimit = 1024 * 1024 * 1024
+)
+
+// crudTimeout bounds quick database-backed routes. Streaming routes (chat
+// generation, model pulls) and outbound fetches manage their own deadlines
+// and must not run under this.
+const crudTimeout = 30 * time.Second
+
+// MaxBodySize rejects requests whose body exceeds limit. Oversized declared
+// lengths get an immediate 413; chunked uploads are cut off by MaxBytesReader
+// once they cross the limit mid-read.
+func MaxBodySize(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "request body too large",
+			})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// RequestTimeout attaches a deadline to the request context so slow database
+// queries and downstream calls are abandoned instead of piling up
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
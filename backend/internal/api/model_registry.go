@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"regexp"
 	"sort"
@@ -17,6 +16,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/logging"
 )
 
 // RemoteModel represents a model from ollama.com with cached details
@@ -36,6 +37,7 @@ type RemoteModel struct {
 	PullCount       int64             `json:"pullCount"`
 	Tags            []string          `json:"tags"`
 	TagSizes        map[string]int64  `json:"tagSizes,omitempty"` // Maps tag name to file size in bytes
+	Fit             string            `json:"fit,omitempty"` // "fits", "tight", or "wont-fit" on this machine
 	OllamaUpdatedAt string            `json:"ollamaUpdatedAt,omitempty"`
 	DetailsFetchedAt string           `json:"detailsFetchedAt,omitempty"`
 	ScrapedAt       string            `json:"scrapedAt"`
@@ -56,6 +58,7 @@ func NewModelRegistryService(db *sql.DB, ollamaClient *api.Client) *ModelRegistr
 		db:          db,
 		ollamaClient: ollamaClient,
 		httpClient: &http.Client{
+			Transport: sharedTransport(),
 			Timeout: 30 * time.Second,
 		},
 	}
@@ -449,7 +452,7 @@ func (s *ModelRegistryService) SyncModels(ctx context.Context, fetchDetails bool
 		return 0, fmt.Errorf("failed to scrape library: %w", err)
 	}
 
-	log.Printf("Scraped %d models from ollama.com", len(scraped))
+	logging.Module("registry").Info("Scraped models from ollama.com", "count", len(scraped))
 
 	// Update database
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -485,7 +488,7 @@ func (s *ModelRegistryService) SyncModels(ctx context.Context, fetchDetails bool
 		`, model.Slug, model.Name, model.Description, modelType, model.URL, model.PullCount, string(tagsJSON), string(capsJSON), model.UpdatedAt, now)
 
 		if err != nil {
-			log.Printf("Failed to upsert model %s: %v", model.Slug, err)
+			logging.Module("registry").Warn("Failed to upsert model", "model", model.Slug, "error", err)
 			continue
 		}
 		count++
@@ -496,9 +499,9 @@ func (s *ModelRegistryService) SyncModels(ctx context.Context, fetchDetails bool
 	if fetchDetails && s.ollamaClient != nil {
 		installedModels, err := s.ollamaClient.List(ctx)
 		if err != nil {
-			log.Printf("Warning: failed to list installed models for capability sync: %v", err)
+			logging.Module("registry").Warn("Failed to list installed models for capability sync", "error", err)
 		} else {
-			log.Printf("Syncing capabilities for %d installed models", len(installedModels.Models))
+			logging.Module("registry").Info("Syncing capabilities for installed models", "count", len(installedModels.Models))
 
 			for _, installed := range installedModels.Models {
 				select {
@@ -514,7 +517,7 @@ func (s *ModelRegistryService) SyncModels(ctx context.Context, fetchDetails bool
 				// Fetch real capabilities from Ollama
 				details, err := s.fetchModelDetails(ctx, modelName)
 				if err != nil {
-					log.Printf("Warning: failed to fetch details for %s: %v", modelName, err)
+					logging.Module("registry").Warn("Failed to fetch model details", "model", modelName, "error", err)
 					continue
 				}
 
@@ -532,9 +535,9 @@ func (s *ModelRegistryService) SyncModels(ctx context.Context, fetchDetails bool
 					UPDATE remote_models SET capabilities = ? WHERE slug = ?
 				`, string(capsJSON), baseName)
 				if err != nil {
-					log.Printf("Warning: failed to update capabilities for %s: %v", baseName, err)
+					logging.Module("registry").Warn("Failed to update capabilities", "model", baseName, "error", err)
 				} else {
-					log.Printf("Updated capabilities for %s: %v", baseName, capabilities)
+					logging.Module("registry").Info("Updated capabilities", "model", baseName, "capabilities", capabilities)
 				}
 			}
 		}
@@ -1114,6 +1117,11 @@ func (s *ModelRegistryService) ListRemoteModelsHandler() gin.HandlerFunc {
 			return
 		}
 
+		// Badge each result with whether it can run on this machine
+		for i := range models {
+			models[i].Fit = modelFitBadge(remoteModelSize(&models[i]))
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"models": models,
 			"total":  total,
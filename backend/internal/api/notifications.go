@@ -0,0 +1,94 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// notifiableEvents maps webhook event names to the title shown for them
+// in the in-app notification center; events not listed here stay
+// webhook-only
+var notifiableEvents = map[string]string{
+	EventModelPullFinished: "Model pull finished",
+	EventBackupCompleted:   "Backup completed",
+	EventAccessNewOrigin:   "Access from a new address",
+	EventAccessDenied:      "Repeated denied requests",
+}
+
+// addNotification stores an in-app notification; a no-op before InitEvents
+// wires the database, mirroring EmitEvent
+func addNotification(notifType, title, body string) {
+	db := eventsDB
+	if db == nil {
+		return
+	}
+	if _, err := models.CreateNotification(db, notifType, title, body); err != nil {
+		logging.Module("events").Warn("Failed to store notification", "error", err)
+	}
+}
+
+// ListNotificationsHandler returns notifications newest first
+// (?unread=true, ?limit=); the UI polls this alongside the unread count
+func ListNotificationsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+				limit = l
+			}
+		}
+
+		notifications, err := models.ListNotifications(db, c.Query("unread") == "true", limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if notifications == nil {
+			notifications = []models.Notification{}
+		}
+
+		unread, err := models.CountUnreadNotifications(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"notifications": notifications,
+			"unread":        unread,
+		})
+	}
+}
+
+// MarkNotificationReadHandler marks one notification as read
+func MarkNotificationReadHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.MarkNotificationRead(db, c.Param("id")); err != nil {
+			if err.Error() == "notification not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "read"})
+	}
+}
+
+// MarkAllNotificationsReadHandler clears the unread count in one call
+func MarkAllNotificationsReadHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		marked, err := models.MarkAllNotificationsRead(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"marked": marked})
+	}
+}
@@ -1,20 +1,23 @@
 package api
 
 import (
-	"io"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-// OllamaProxyHandler returns a handler that proxies requests to Ollama
+// OllamaProxyHandler returns a handler that proxies requests to Ollama.
+// Responses are streamed chunk by chunk with an explicit flush after
+// every read so NDJSON streams reach the client as they are produced
+// instead of arriving in one burst when the generation finishes.
 func OllamaProxyHandler(ollamaURL string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		path := c.Param("path")
 		targetURL := strings.TrimSuffix(ollamaURL, "/") + path
 
-		// Create proxy request
+		// The request context is cancelled when the client disconnects,
+		// which aborts the upstream request and stops the generation
 		req, err := http.NewRequestWithContext(c.Request.Context(), c.Request.Method, targetURL, c.Request.Body)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create proxy request"})
@@ -28,24 +31,52 @@ func OllamaProxyHandler(ollamaURL string) gin.HandlerFunc {
 			}
 		}
 
-		// Execute request
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		// Execute request on the shared transport; no overall timeout
+		// because generations stream for as long as they need
+		resp, err := newOutboundClient(0).Do(req)
 		if err != nil {
 			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to reach Ollama: " + err.Error()})
 			return
 		}
 		defer resp.Body.Close()
 
-		// Copy response headers
+		// Copy response headers, announcing any trailers the upstream
+		// declared so they survive the chunked transfer
 		for key, values := range resp.Header {
 			for _, value := range values {
 				c.Header(key, value)
 			}
 		}
+		for key := range resp.Trailer {
+			c.Writer.Header().Add("Trailer", key)
+		}
 
-		// Stream response body
 		c.Status(resp.StatusCode)
-		io.Copy(c.Writer, resp.Body)
+		flusher, _ := c.Writer.(http.Flusher)
+
+		buf := make([]byte, 32*1024)
+		for {
+			n, readErr := resp.Body.Read(buf)
+			if n > 0 {
+				if _, writeErr := c.Writer.Write(buf[:n]); writeErr != nil {
+					// Client went away; the deferred body close plus the
+					// cancelled context tear down the upstream side
+					return
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+			}
+			if readErr != nil {
+				break
+			}
+		}
+
+		// Trailer values are only populated once the body is consumed
+		for key, values := range resp.Trailer {
+			for _, value := range values {
+				c.Writer.Header().Add(http.TrailerPrefix+key, value)
+			}
+		}
 	}
 }
@@ -2,14 +2,19 @@ package api
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/models"
 )
 
 // OllamaService wraps the official Ollama client
@@ -30,7 +35,10 @@ func NewOllamaService(ollamaURL string) (*OllamaService, error) {
 		return nil, fmt.Errorf("invalid Ollama URL: %w", err)
 	}
 
-	client := api.NewClient(baseURL, http.DefaultClient)
+	// Propagate X-Request-ID on every outbound Ollama call, pooling
+	// connections through the shared transport
+	httpClient := &http.Client{Transport: requestIDTransport{base: sharedTransport()}}
+	client := api.NewClient(baseURL, httpClient)
 
 	return &OllamaService{
 		client:    client,
@@ -68,8 +76,10 @@ func (s *OllamaService) ShowModelHandler() gin.HandlerFunc {
 	}
 }
 
-// ChatHandler handles streaming chat requests
-func (s *OllamaService) ChatHandler() gin.HandlerFunc {
+// ChatHandler handles streaming chat requests. When the request carries a
+// ?persona= ID, that persona's system prompt, default model, and generation
+// parameters are applied before the request reaches Ollama.
+func (s *OllamaService) ChatHandler(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req api.ChatRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -77,19 +87,71 @@ func (s *OllamaService) ChatHandler() gin.HandlerFunc {
 			return
 		}
 
+		// Routing rules classify the opening message of new chats and may
+		// tag the chat, apply a persona, or pick a different model
+		if rule := s.applyRoutingRules(c.Request.Context(), db, c.Query("chat"), &req); rule != "" {
+			c.Header("X-Routing-Rule", rule)
+		}
+
+		// A per-message preset (?preset=) layers in first, then chat-level
+		// options, then the persona: request > preset > chat > persona
+		if preset := c.Query("preset"); preset != "" {
+			if err := applyGenerationPreset(db, &req, preset); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "failed to apply preset: " + err.Error()})
+				return
+			}
+			c.Header("X-Generation-Preset", preset)
+		}
+
+		applyChatSamplingOptions(db, c.Query("chat"), &req)
+
+		if personaID := c.Query("persona"); personaID != "" {
+			if err := applyPersona(db, &req, personaID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply persona: " + err.Error()})
+				return
+			}
+		}
+
+		// Resolve {{snippet:name}} references after the persona prompt
+		// has been layered in, so both compose from the snippet library
+		expandSystemSnippets(db, &req)
+
+		// Pin a seed when none is set so the recorded parameters can
+		// replay this generation exactly
+		req.Options = ensureSeed(req.Options)
+
+		if !s.screenChatInput(c, db, &req) {
+			return
+		}
+
+		// Trim the history to the model's context window when the chat has
+		// a strategy configured (?chat= identifies the chat)
+		strategy := s.applyContextStrategy(c.Request.Context(), db, c.Query("chat"), &req)
+		if strategy != "" {
+			c.Header("X-Context-Strategy", strategy)
+		}
+
 		// Check if streaming is requested (default true for chat)
 		streaming := req.Stream == nil || *req.Stream
 
 		if streaming {
-			s.handleStreamingChat(c, &req)
+			s.handleStreamingChat(c, db, &req, strategy)
 		} else {
-			s.handleNonStreamingChat(c, &req)
+			s.handleNonStreamingChat(c, db, &req, strategy)
 		}
 	}
 }
 
 // handleStreamingChat handles streaming chat responses
-func (s *OllamaService) handleStreamingChat(c *gin.Context, req *api.ChatRequest) {
+func (s *OllamaService) handleStreamingChat(c *gin.Context, db *sql.DB, req *api.ChatRequest, contextStrategy string) {
+	release, ok := acquireStreamSlot(c)
+	if !ok {
+		return
+	}
+	defer release()
+	activeStreams.Add(1)
+	defer activeStreams.Add(-1)
+
 	// Set headers for streaming
 	c.Header("Content-Type", "application/x-ndjson")
 	c.Header("Cache-Control", "no-cache")
@@ -103,6 +165,11 @@ func (s *OllamaService) handleStreamingChat(c *gin.Context, req *api.ChatRequest
 		return
 	}
 
+	start := time.Now()
+	var firstToken time.Duration
+	toolCalls := 0
+	var final *api.ChatResponse
+	var output strings.Builder
 	err := s.client.Chat(ctx, req, func(resp api.ChatResponse) error {
 		// Check if context is cancelled
 		select {
@@ -111,12 +178,30 @@ func (s *OllamaService) handleStreamingChat(c *gin.Context, req *api.ChatRequest
 		default:
 		}
 
+		if firstToken == 0 && (resp.Message.Content != "" || len(resp.Message.ToolCalls) > 0) {
+			firstToken = time.Since(start)
+		}
+		toolCalls += len(resp.Message.ToolCalls)
+		output.WriteString(resp.Message.Content)
+		if resp.Done {
+			done := resp
+			final = &done
+		}
+
 		// Marshal and write response
 		data, err := json.Marshal(resp)
 		if err != nil {
 			return err
 		}
 
+		// Attach the latency breakdown (and the context strategy that was
+		// applied, if any) to the final frame
+		if resp.Done {
+			data = appendLatency(data, latencyBreakdown(firstToken, time.Since(start), resp.Metrics))
+			data = appendContextStrategy(data, contextStrategy)
+			data = appendGenerationParams(data, req.Model, req.Options)
+		}
+
 		_, err = c.Writer.Write(append(data, '\n'))
 		if err != nil {
 			return err
@@ -132,10 +217,27 @@ func (s *OllamaService) handleStreamingChat(c *gin.Context, req *api.ChatRequest
 		c.Writer.Write(append(data, '\n'))
 		flusher.Flush()
 	}
+
+	// The response is already on the wire, so output rules can only flag
+	screenStreamedOutput(db, req.Model, output.String())
+
+	if final != nil {
+		recordGeneration(db, &models.GenerationMetric{
+			Model:            req.Model,
+			User:             metricUser(c),
+			Kind:             "chat",
+			PromptTokens:     final.Metrics.PromptEvalCount,
+			CompletionTokens: final.Metrics.EvalCount,
+			DurationMs:       final.Metrics.TotalDuration.Milliseconds(),
+			FirstTokenMs:     firstToken.Milliseconds(),
+			QueueMs:          final.Metrics.LoadDuration.Milliseconds(),
+			ToolCalls:        toolCalls,
+		})
+	}
 }
 
 // handleNonStreamingChat handles non-streaming chat responses
-func (s *OllamaService) handleNonStreamingChat(c *gin.Context, req *api.ChatRequest) {
+func (s *OllamaService) handleNonStreamingChat(c *gin.Context, db *sql.DB, req *api.ChatRequest, contextStrategy string) {
 	var finalResp api.ChatResponse
 
 	err := s.client.Chat(c.Request.Context(), req, func(resp api.ChatResponse) error {
@@ -148,11 +250,31 @@ func (s *OllamaService) handleNonStreamingChat(c *gin.Context, req *api.ChatRequ
 		return
 	}
 
+	recordGeneration(db, &models.GenerationMetric{
+		Model:            req.Model,
+		User:             metricUser(c),
+		Kind:             "chat",
+		PromptTokens:     finalResp.Metrics.PromptEvalCount,
+		CompletionTokens: finalResp.Metrics.EvalCount,
+		DurationMs:       finalResp.Metrics.TotalDuration.Milliseconds(),
+		QueueMs:          finalResp.Metrics.LoadDuration.Milliseconds(),
+		ToolCalls:        len(finalResp.Message.ToolCalls),
+	})
+
+	if guardrailsEnabled() {
+		sanitized, blockedBy := screenText(db, req.Model, "output", finalResp.Message.Content, true)
+		if blockedBy != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "blocked by guardrail: " + blockedBy})
+			return
+		}
+		finalResp.Message.Content = sanitized
+	}
+
 	c.JSON(http.StatusOK, finalResp)
 }
 
 // GenerateHandler handles streaming generate requests
-func (s *OllamaService) GenerateHandler() gin.HandlerFunc {
+func (s *OllamaService) GenerateHandler(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req api.GenerateRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -160,19 +282,31 @@ func (s *OllamaService) GenerateHandler() gin.HandlerFunc {
 			return
 		}
 
+		if !s.screenGenerateInput(c, db, &req) {
+			return
+		}
+
 		// Check if streaming is requested (default true)
 		streaming := req.Stream == nil || *req.Stream
 
 		if streaming {
-			s.handleStreamingGenerate(c, &req)
+			s.handleStreamingGenerate(c, db, &req)
 		} else {
-			s.handleNonStreamingGenerate(c, &req)
+			s.handleNonStreamingGenerate(c, db, &req)
 		}
 	}
 }
 
 // handleStreamingGenerate handles streaming generate responses
-func (s *OllamaService) handleStreamingGenerate(c *gin.Context, req *api.GenerateRequest) {
+func (s *OllamaService) handleStreamingGenerate(c *gin.Context, db *sql.DB, req *api.GenerateRequest) {
+	release, ok := acquireStreamSlot(c)
+	if !ok {
+		return
+	}
+	defer release()
+	activeStreams.Add(1)
+	defer activeStreams.Add(-1)
+
 	c.Header("Content-Type", "application/x-ndjson")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
@@ -185,6 +319,10 @@ func (s *OllamaService) handleStreamingGenerate(c *gin.Context, req *api.Generat
 		return
 	}
 
+	start := time.Now()
+	var firstToken time.Duration
+	var final *api.GenerateResponse
+	var output strings.Builder
 	err := s.client.Generate(ctx, req, func(resp api.GenerateResponse) error {
 		select {
 		case <-ctx.Done():
@@ -192,11 +330,25 @@ func (s *OllamaService) handleStreamingGenerate(c *gin.Context, req *api.Generat
 		default:
 		}
 
+		if firstToken == 0 && resp.Response != "" {
+			firstToken = time.Since(start)
+		}
+		output.WriteString(resp.Response)
+		if resp.Done {
+			done := resp
+			final = &done
+		}
+
 		data, err := json.Marshal(resp)
 		if err != nil {
 			return err
 		}
 
+		// Attach the latency breakdown to the final frame
+		if resp.Done {
+			data = appendLatency(data, latencyBreakdown(firstToken, time.Since(start), resp.Metrics))
+		}
+
 		_, err = c.Writer.Write(append(data, '\n'))
 		if err != nil {
 			return err
@@ -211,10 +363,34 @@ func (s *OllamaService) handleStreamingGenerate(c *gin.Context, req *api.Generat
 		c.Writer.Write(append(data, '\n'))
 		flusher.Flush()
 	}
+
+	// The response is already on the wire, so output rules can only flag
+	screenStreamedOutput(db, req.Model, output.String())
+
+	if final != nil {
+		recordGeneration(db, &models.GenerationMetric{
+			Model:            req.Model,
+			User:             metricUser(c),
+			Kind:             "generate",
+			PromptTokens:     final.Metrics.PromptEvalCount,
+			CompletionTokens: final.Metrics.EvalCount,
+			DurationMs:       final.Metrics.TotalDuration.Milliseconds(),
+			FirstTokenMs:     firstToken.Milliseconds(),
+			QueueMs:          final.Metrics.LoadDuration.Milliseconds(),
+		})
+	}
 }
 
 // handleNonStreamingGenerate handles non-streaming generate responses
-func (s *OllamaService) handleNonStreamingGenerate(c *gin.Context, req *api.GenerateRequest) {
+func (s *OllamaService) handleNonStreamingGenerate(c *gin.Context, db *sql.DB, req *api.GenerateRequest) {
+	// Repeated identical prompts can be answered from the response cache
+	cacheKey := responseCacheKey("generate", req)
+	if body, ok := ollamaResponseCache.get(cacheKey); ok {
+		c.Header("X-Vessel-Cache", "hit")
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+		return
+	}
+
 	var finalResp api.GenerateResponse
 
 	err := s.client.Generate(c.Request.Context(), req, func(resp api.GenerateResponse) error {
@@ -227,6 +403,33 @@ func (s *OllamaService) handleNonStreamingGenerate(c *gin.Context, req *api.Gene
 		return
 	}
 
+	recordGeneration(db, &models.GenerationMetric{
+		Model:            req.Model,
+		User:             metricUser(c),
+		Kind:             "generate",
+		PromptTokens:     finalResp.Metrics.PromptEvalCount,
+		CompletionTokens: finalResp.Metrics.EvalCount,
+		DurationMs:       finalResp.Metrics.TotalDuration.Milliseconds(),
+		QueueMs:          finalResp.Metrics.LoadDuration.Milliseconds(),
+	})
+
+	if guardrailsEnabled() {
+		sanitized, blockedBy := screenText(db, req.Model, "output", finalResp.Response, true)
+		if blockedBy != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "blocked by guardrail: " + blockedBy})
+			return
+		}
+		finalResp.Response = sanitized
+	}
+
+	// Cache the screened response so hits never bypass guardrails
+	if cacheKey != "" {
+		if body, err := json.Marshal(finalResp); err == nil {
+			ollamaResponseCache.put(cacheKey, body)
+			c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+			return
+		}
+	}
 	c.JSON(http.StatusOK, finalResp)
 }
 
@@ -239,12 +442,27 @@ func (s *OllamaService) EmbedHandler() gin.HandlerFunc {
 			return
 		}
 
+		// Embeddings are deterministic per model, so cache hits are safe
+		cacheKey := responseCacheKey("embed", &req)
+		if body, ok := ollamaResponseCache.get(cacheKey); ok {
+			c.Header("X-Vessel-Cache", "hit")
+			c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+			return
+		}
+
 		resp, err := s.client.Embed(c.Request.Context(), &req)
 		if err != nil {
 			c.JSON(http.StatusBadGateway, gin.H{"error": "embed failed: " + err.Error()})
 			return
 		}
 
+		if cacheKey != "" {
+			if body, err := json.Marshal(resp); err == nil {
+				ollamaResponseCache.put(cacheKey, body)
+				c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+				return
+			}
+		}
 		c.JSON(http.StatusOK, resp)
 	}
 }
@@ -258,6 +476,9 @@ func (s *OllamaService) PullModelHandler() gin.HandlerFunc {
 			return
 		}
 
+		activePulls.Add(1)
+		defer activePulls.Add(-1)
+
 		c.Header("Content-Type", "application/x-ndjson")
 		c.Header("Cache-Control", "no-cache")
 		c.Header("Connection", "keep-alive")
@@ -294,6 +515,13 @@ func (s *OllamaService) PullModelHandler() gin.HandlerFunc {
 			data, _ := json.Marshal(errResp)
 			c.Writer.Write(append(data, '\n'))
 			flusher.Flush()
+			return
+		}
+
+		if err == nil {
+			EmitEvent(EventModelPullFinished, map[string]interface{}{
+				"model": req.Model,
+			})
 		}
 	}
 }
@@ -432,8 +660,7 @@ func (s *OllamaService) ProxyHandler() gin.HandlerFunc {
 			}
 		}
 
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := newOutboundClient(0).Do(req)
 		if err != nil {
 			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to reach Ollama: " + err.Error()})
 			return
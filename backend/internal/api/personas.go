@@ -0,0 +1,172 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/models"
+)
+
+// personaRequest is the create/update payload for a persona
+type personaRequest struct {
+	Name         string                 `json:"name" binding:"required"`
+	Avatar       string                 `json:"avatar"`
+	SystemPrompt string                 `json:"system_prompt"`
+	Model        string                 `json:"model"`
+	Parameters   map[string]interface{} `json:"parameters"`
+	Tools        []string               `json:"tools"`
+	Knowledge    []string               `json:"knowledge"`
+}
+
+// ListPersonasHandler lists all personas
+func ListPersonasHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		personas, err := models.ListPersonas(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if personas == nil {
+			personas = []models.Persona{}
+		}
+		c.JSON(http.StatusOK, gin.H{"personas": personas})
+	}
+}
+
+// CreatePersonaHandler creates a new persona
+func CreatePersonaHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req personaRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+		if err := validateSamplingOptions(req.Parameters); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		persona := personaFromRequest(&req)
+		if err := models.CreatePersona(db, persona); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, persona)
+	}
+}
+
+// GetPersonaHandler returns a single persona by ID
+func GetPersonaHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		persona, err := models.GetPersona(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if persona == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "persona not found"})
+			return
+		}
+		c.JSON(http.StatusOK, persona)
+	}
+}
+
+// UpdatePersonaHandler updates an existing persona
+func UpdatePersonaHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req personaRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+		if err := validateSamplingOptions(req.Parameters); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		persona := personaFromRequest(&req)
+		persona.ID = c.Param("id")
+		if err := models.UpdatePersona(db, persona); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "persona not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, persona)
+	}
+}
+
+// DeletePersonaHandler deletes a persona
+func DeletePersonaHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.DeletePersona(db, c.Param("id")); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "persona not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "persona deleted"})
+	}
+}
+
+// personaFromRequest builds a Persona from the request payload
+func personaFromRequest(req *personaRequest) *models.Persona {
+	return &models.Persona{
+		Name:         strings.TrimSpace(req.Name),
+		Avatar:       req.Avatar,
+		SystemPrompt: req.SystemPrompt,
+		Model:        req.Model,
+		Parameters:   req.Parameters,
+		Tools:        req.Tools,
+		Knowledge:    req.Knowledge,
+	}
+}
+
+// applyPersona layers a persona's configuration onto a chat request:
+// the default model when the request names none, the system prompt when
+// the conversation has none, and generation parameters for any option
+// the request leaves unset. Explicit request values always win.
+func applyPersona(db *sql.DB, req *ollamaapi.ChatRequest, personaID string) error {
+	persona, err := models.GetPersona(db, personaID)
+	if err != nil || persona == nil {
+		return err
+	}
+
+	if req.Model == "" {
+		req.Model = persona.Model
+	}
+
+	if persona.SystemPrompt != "" {
+		hasSystem := false
+		for _, msg := range req.Messages {
+			if msg.Role == "system" {
+				hasSystem = true
+				break
+			}
+		}
+		if !hasSystem {
+			system := ollamaapi.Message{Role: "system", Content: persona.SystemPrompt}
+			req.Messages = append([]ollamaapi.Message{system}, req.Messages...)
+		}
+	}
+
+	if len(persona.Parameters) > 0 {
+		if req.Options == nil {
+			req.Options = map[string]interface{}{}
+		}
+		for key, value := range persona.Parameters {
+			if _, set := req.Options[key]; !set {
+				req.Options[key] = value
+			}
+		}
+	}
+	return nil
+}
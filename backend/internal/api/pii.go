@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
+)
+
+// piiDetector is one named pattern the outbound scrubber looks for
+type piiDetector struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// Built-in detectors for the data most likely to leak through a pasted
+// chat snippet: email addresses, phone numbers, and API key formats.
+// The phone pattern requires a separator or prefix so plain numbers in
+// queries ("top 10 laptops 2024") do not trip it.
+var builtinPIIDetectors = []piiDetector{
+	{"email", regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)},
+	{"phone", regexp.MustCompile(`(?:\+\d{1,3}[\s\-]?)?(?:\(\d{2,4}\)[\s\-]?)?\d{2,4}[\s\-]\d{3,4}[\s\-]\d{3,4}`)},
+	{"api-key", regexp.MustCompile(`\b(?:sk-[a-zA-Z0-9\-_]{16,}|ghp_[a-zA-Z0-9]{36}|gho_[a-zA-Z0-9]{36}|github_pat_[a-zA-Z0-9_]{20,}|AKIA[0-9A-Z]{16}|xox[bpoas]-[a-zA-Z0-9\-]{10,}|eyJ[a-zA-Z0-9_\-]{20,}\.[a-zA-Z0-9_\-]{20,}\.[a-zA-Z0-9_\-]{10,})`)},
+	{"bearer-token", regexp.MustCompile(`(?i)\bbearer\s+[a-zA-Z0-9\-._~+/]{16,}`)},
+}
+
+var (
+	piiDetectors     []piiDetector
+	piiDetectorsOnce sync.Once
+)
+
+// loadPIIDetectors combines the built-in detectors with any extra
+// patterns from the config, compiled once; invalid patterns are logged
+// and skipped
+func loadPIIDetectors() []piiDetector {
+	piiDetectorsOnce.Do(func() {
+		piiDetectors = append(piiDetectors, builtinPIIDetectors...)
+		logger := logging.Module("privacy")
+		for i, pattern := range config.Get().Privacy.PIIRedaction.ExtraPatterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				logger.Warn("Skipping invalid PII pattern", "pattern", pattern, "error", err)
+				continue
+			}
+			piiDetectors = append(piiDetectors, piiDetector{name: fmt.Sprintf("custom-%d", i+1), re: re})
+		}
+	})
+	return piiDetectors
+}
+
+// scrubOutboundText runs the PII detectors over text that is about to be
+// sent to an external service. With action "redact" (the default) matches
+// are replaced with a [type] placeholder; with "warn" the text passes
+// through unchanged. Either way the detector names and match counts are
+// logged - never the matched values themselves.
+func scrubOutboundText(text, destination string) string {
+	cfg := config.Get().Privacy.PIIRedaction
+	if !cfg.Enabled || text == "" {
+		return text
+	}
+
+	redact := cfg.Action != "warn"
+	var stripped []string
+	for _, d := range loadPIIDetectors() {
+		matches := d.re.FindAllString(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		stripped = append(stripped, d.name)
+		if redact {
+			text = d.re.ReplaceAllString(text, "["+d.name+"]")
+		}
+	}
+
+	if len(stripped) > 0 {
+		action := "redacted"
+		if !redact {
+			action = "detected"
+		}
+		logging.Module("privacy").Warn("PII in outbound text",
+			"destination", destination,
+			"action", action,
+			"types", strings.Join(stripped, ","),
+		)
+	}
+	return text
+}
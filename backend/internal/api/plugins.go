@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/plugins"
+)
+
+// pluginManager is the process-wide plugin manager, created on first use
+var (
+	pluginManager     *plugins.Manager
+	pluginManagerOnce sync.Once
+)
+
+// GetPluginManager returns the plugin manager, discovering and starting
+// plugins from the configured dir on first call
+func GetPluginManager() *plugins.Manager {
+	pluginManagerOnce.Do(func() {
+		pluginManager = plugins.NewManager(config.Get().Plugins.Dir)
+		pluginManager.Discover()
+	})
+	return pluginManager
+}
+
+// ListPluginsHandler returns every installed plugin with its status
+func ListPluginsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"plugins": GetPluginManager().List()})
+	}
+}
+
+// RestartPluginHandler restarts one plugin's process
+func RestartPluginHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if err := GetPluginManager().Restart(name); err != nil {
+			if err.Error() == "plugin not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "plugin not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "plugin restarted"})
+	}
+}
+
+// PluginCallRequest is one JSON-RPC call forwarded to a plugin
+type PluginCallRequest struct {
+	Method string      `json:"method" binding:"required"`
+	Params interface{} `json:"params"`
+}
+
+// CallPluginHandler forwards a JSON-RPC call to a plugin - the generic
+// invocation path for plugin tools and for probing plugins from the admin UI
+func CallPluginHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req PluginCallRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		result, err := GetPluginManager().Call(c.Param("name"), req.Method, req.Params)
+		if err != nil {
+			if err.Error() == "plugin not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "plugin not found"})
+				return
+			}
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+)
+
+// offlineMode reports whether the air-gapped / privacy switch is on.
+// Configured via privacy.offline_mode or OFFLINE_MODE=true.
+func offlineMode() bool {
+	return config.Get().Privacy.OfflineMode
+}
+
+// abortOffline answers a request whose feature needs outbound network
+// access with an explicit policy response, so clients can distinguish
+// "disabled on purpose" from a flaky network
+func abortOffline(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":   "disabled by policy: offline mode is enabled",
+		"offline": true,
+	})
+}
+
+// OfflineGuard blocks a route entirely when offline mode is on. Use it for
+// routes whose only purpose is an outbound call (search, geolocation,
+// registry sync).
+func OfflineGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if offlineMode() {
+			abortOffline(c)
+			return
+		}
+		c.Next()
+	}
+}
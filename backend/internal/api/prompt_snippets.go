@@ -0,0 +1,136 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// promptSnippetRequest is the create/update payload for a snippet
+type promptSnippetRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Content     string `json:"content" binding:"required"`
+}
+
+// ListPromptSnippetsHandler lists all prompt snippets
+func ListPromptSnippetsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		snippets, err := models.ListPromptSnippets(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if snippets == nil {
+			snippets = []models.PromptSnippet{}
+		}
+		c.JSON(http.StatusOK, gin.H{"snippets": snippets})
+	}
+}
+
+// CreatePromptSnippetHandler creates a new prompt snippet
+func CreatePromptSnippetHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req promptSnippetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+
+		snippet := &models.PromptSnippet{
+			Name:        strings.TrimSpace(req.Name),
+			Description: req.Description,
+			Content:     req.Content,
+		}
+		if err := models.CreatePromptSnippet(db, snippet); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, snippet)
+	}
+}
+
+// GetPromptSnippetHandler returns a single snippet by ID
+func GetPromptSnippetHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		snippet, err := models.GetPromptSnippet(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if snippet == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "prompt snippet not found"})
+			return
+		}
+		c.JSON(http.StatusOK, snippet)
+	}
+}
+
+// UpdatePromptSnippetHandler updates a snippet and bumps its version;
+// everything referencing the snippet picks up the new content on its
+// next request
+func UpdatePromptSnippetHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req promptSnippetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+
+		snippet := &models.PromptSnippet{
+			ID:          c.Param("id"),
+			Name:        strings.TrimSpace(req.Name),
+			Description: req.Description,
+			Content:     req.Content,
+		}
+		if err := models.UpdatePromptSnippet(db, snippet); err != nil {
+			if err.Error() == "prompt snippet not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, snippet)
+	}
+}
+
+// DeletePromptSnippetHandler deletes a snippet; existing references to
+// it stay in the text unexpanded
+func DeletePromptSnippetHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.DeletePromptSnippet(db, c.Param("id")); err != nil {
+			if err.Error() == "prompt snippet not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "prompt snippet deleted"})
+	}
+}
+
+// expandSystemSnippets resolves {{snippet:name}} references in the
+// system messages of an outgoing chat request. Running after persona
+// application covers both persona prompts and ad-hoc system prompts
+// sent by the client; resolution failures log and leave the text as-is.
+func expandSystemSnippets(db *sql.DB, req *ollamaapi.ChatRequest) {
+	for i := range req.Messages {
+		if req.Messages[i].Role != "system" {
+			continue
+		}
+		expanded, err := models.ExpandPromptSnippets(db, req.Messages[i].Content)
+		if err != nil {
+			logging.Module("snippets").Warn("Failed to expand prompt snippets", "error", err)
+			continue
+		}
+		req.Messages[i].Content = expanded
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/models"
+)
+
+// promptTemplateRequest is the create/update payload for a template
+type promptTemplateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Content     string `json:"content" binding:"required"`
+}
+
+// ListPromptTemplatesHandler lists templates, optionally filtered with
+// ?category=; ?categories=true returns just the distinct category names
+func ListPromptTemplatesHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Query("categories") == "true" {
+			categories, err := models.ListPromptTemplateCategories(db)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"categories": categories})
+			return
+		}
+
+		templates, err := models.ListPromptTemplates(db, c.Query("category"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if templates == nil {
+			templates = []models.PromptTemplate{}
+		}
+		c.JSON(http.StatusOK, gin.H{"templates": templates})
+	}
+}
+
+// CreatePromptTemplateHandler creates a new prompt template
+func CreatePromptTemplateHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req promptTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+
+		template := &models.PromptTemplate{
+			Name:        strings.TrimSpace(req.Name),
+			Description: req.Description,
+			Category:    strings.TrimSpace(req.Category),
+			Content:     req.Content,
+		}
+		if err := models.CreatePromptTemplate(db, template); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, template)
+	}
+}
+
+// GetPromptTemplateHandler returns a single template by ID
+func GetPromptTemplateHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		template, err := models.GetPromptTemplate(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if template == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+			return
+		}
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+// UpdatePromptTemplateHandler updates an existing template
+func UpdatePromptTemplateHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req promptTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+
+		template := &models.PromptTemplate{
+			ID:          c.Param("id"),
+			Name:        strings.TrimSpace(req.Name),
+			Description: req.Description,
+			Category:    strings.TrimSpace(req.Category),
+			Content:     req.Content,
+		}
+		if err := models.UpdatePromptTemplate(db, template); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+// DeletePromptTemplateHandler deletes a template
+func DeletePromptTemplateHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.DeletePromptTemplate(db, c.Param("id")); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "template deleted"})
+	}
+}
+
+// RenderPromptTemplateHandler substitutes the supplied variable values into
+// the template and returns the rendered text. Placeholders without a value
+// are left in place and reported in "missing" so the composer can prompt
+// for them.
+func RenderPromptTemplateHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+
+		template, err := models.GetPromptTemplate(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if template == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+			return
+		}
+
+		rendered, missing := models.RenderTemplate(template.Content, req.Variables)
+		if missing == nil {
+			missing = []string{}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"rendered": rendered,
+			"missing":  missing,
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// provisioningCheckTimeout bounds the startup drift check so a slow or
+// absent Ollama doesn't hold a goroutine forever
+const provisioningCheckTimeout = 30 * time.Second
+
+// provisioningModel is one required model's drift status
+type provisioningModel struct {
+	Name    string `json:"name"`
+	Present bool   `json:"present"`
+}
+
+// provisioningReport compares the required-model list from config
+// against what Ollama actually has locally. Extra lists local models
+// outside the required set; it stays empty when no list is configured.
+type provisioningReport struct {
+	Models      []provisioningModel `json:"models"`
+	Missing     []string            `json:"missing"`
+	Extra       []string            `json:"extra"`
+	InSync      bool                `json:"in_sync"`
+	PullMissing bool                `json:"pull_missing"`
+}
+
+// fullModelName normalizes a config entry to the name Ollama lists,
+// which always carries a tag ("llama3.2" is stored as "llama3.2:latest")
+func fullModelName(name string) string {
+	if !strings.Contains(name, ":") {
+		return name + ":latest"
+	}
+	return name
+}
+
+// buildProvisioningReport computes the drift between config and Ollama
+func (s *OllamaService) buildProvisioningReport(ctx context.Context) (*provisioningReport, error) {
+	cfg := config.Get().Provisioning
+
+	resp, err := s.client.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local models: %w", err)
+	}
+	local := make(map[string]bool, len(resp.Models))
+	for _, m := range resp.Models {
+		local[m.Name] = true
+	}
+
+	report := &provisioningReport{
+		Models:      []provisioningModel{},
+		Missing:     []string{},
+		Extra:       []string{},
+		PullMissing: cfg.PullMissing,
+	}
+	required := make(map[string]bool, len(cfg.Models))
+	for _, name := range cfg.Models {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		full := fullModelName(name)
+		required[full] = true
+		present := local[full]
+		report.Models = append(report.Models, provisioningModel{Name: name, Present: present})
+		if !present {
+			report.Missing = append(report.Missing, name)
+		}
+	}
+
+	// Extra is only meaningful when a required set exists; without one
+	// every local model would count as drift
+	if len(required) > 0 {
+		for _, m := range resp.Models {
+			if !required[m.Name] {
+				report.Extra = append(report.Extra, m.Name)
+			}
+		}
+		sort.Strings(report.Extra)
+	}
+
+	report.InSync = len(report.Missing) == 0 && len(report.Extra) == 0
+	return report, nil
+}
+
+// enqueueProvisioningPulls queues a model-pull job per missing model
+// and returns the created job IDs; failures are logged and skipped so
+// one bad enqueue doesn't block the rest
+func enqueueProvisioningPulls(db *sql.DB, missing []string) []string {
+	logger := logging.Module("provisioning")
+	ids := []string{}
+	for _, name := range missing {
+		payload, _ := json.Marshal(map[string]string{"model": name})
+		job, err := models.CreateJob(db, "model-pull", payload, 1, time.Now())
+		if err != nil {
+			logger.Warn("Failed to enqueue provisioning pull", "model", name, "error", err)
+			continue
+		}
+		ids = append(ids, job.ID)
+	}
+	return ids
+}
+
+// ModelProvisioningHandler reports drift between the provisioning list
+// in config and the models Ollama has locally
+func (s *OllamaService) ModelProvisioningHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := s.buildProvisioningReport(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// ApplyProvisioningHandler pulls whatever the provisioning list
+// requires but Ollama lacks, via the job queue so progress shows up
+// under /jobs like any other pull
+func (s *OllamaService) ApplyProvisioningHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := s.buildProvisioningReport(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"missing": report.Missing,
+			"jobs":    enqueueProvisioningPulls(db, report.Missing),
+		})
+	}
+}
+
+// StartProvisioning checks the required-model list once in the
+// background and, with pull_missing on, queues pulls for whatever is
+// absent. No-op without a configured list.
+func StartProvisioning(db *sql.DB, s *OllamaService) {
+	cfg := config.Get().Provisioning
+	if s == nil || len(cfg.Models) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), provisioningCheckTimeout)
+		defer cancel()
+
+		logger := logging.Module("provisioning")
+		report, err := s.buildProvisioningReport(ctx)
+		if err != nil {
+			logger.Warn("Provisioning drift check failed", "error", err)
+			return
+		}
+		if len(report.Missing) == 0 {
+			return
+		}
+		logger.Info("Provisioning drift detected", "missing", strings.Join(report.Missing, ", "))
+		if cfg.PullMissing {
+			enqueueProvisioningPulls(db, report.Missing)
+		}
+	}()
+}
@@ -1,24 +1,44 @@
 package api
 
 import (
+	"database/sql"
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/logging"
 )
 
 // URLFetchRequest represents a request to fetch a URL
 type URLFetchRequest struct {
 	URL       string `json:"url" binding:"required"`
 	MaxLength int    `json:"maxLength"`
-	Timeout   int    `json:"timeout"` // Timeout in seconds
+	Timeout   int    `json:"timeout"`  // Timeout in seconds
+	Profile   string `json:"profile"`  // Optional saved auth profile name
+	Sanitize  bool   `json:"sanitize"` // Strip lines flagged by the injection scanner
+
+	// With both set, oversized pages are trimmed to maxLength by
+	// relevance to the question (embedding similarity) instead of being
+	// cut off mid-sentence
+	Question   string `json:"question"`
+	EmbedModel string `json:"embedModel"`
+
+	// Headless rendering controls (require Chrome): render forces the
+	// headless browser instead of relying on the JS-render heuristics;
+	// waitForSelector captures once the CSS selector is visible;
+	// waitTime is extra seconds for JS to settle (default 2, max 30)
+	Render          bool   `json:"render"`
+	WaitForSelector string `json:"waitForSelector"`
+	WaitTime        int    `json:"waitTime"`
 }
 
 // URLFetchProxyHandler returns a handler that fetches URLs for the frontend
 // This bypasses CORS restrictions for the fetch_url tool
 // Uses curl/wget when available for better compatibility, falls back to native Go
-func URLFetchProxyHandler() gin.HandlerFunc {
+func URLFetchProxyHandler(db *sql.DB, ollamaService *OllamaService) gin.HandlerFunc {
 	fetcher := GetFetcher()
 
 	return func(c *gin.Context) {
@@ -56,10 +76,40 @@ func URLFetchProxyHandler() gin.HandlerFunc {
 			opts.MaxLength = req.MaxLength
 		}
 
+		// Headless rendering controls
+		if req.Render && !fetcher.HasChrome() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "render requires headless Chrome, which is not available"})
+			return
+		}
+		opts.ForceHeadless = req.Render
+		opts.WaitForSelector = req.WaitForSelector
+		if req.WaitTime > 0 && req.WaitTime <= 30 {
+			opts.WaitTime = time.Duration(req.WaitTime) * time.Second
+		}
+
+		// Relevance budgeting needs the full page to pick sections from;
+		// the requested length becomes the output budget instead
+		budget := opts.MaxLength
+		budgeting := req.Question != "" && req.EmbedModel != ""
+		if budgeting {
+			opts.MaxLength = 2000000
+		}
+
+		// Apply saved auth profile if requested (cookies, bearer, basic auth)
+		if req.Profile != "" {
+			if err := applyFetchProfile(db, req.Profile, req.URL, &opts); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
 		// Fetch the URL
 		result, err := fetcher.Fetch(c.Request.Context(), req.URL, opts)
 		if err != nil {
-			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch URL: " + err.Error()})
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error":     "failed to fetch URL: " + err.Error(),
+				"requestId": RequestID(c),
+			})
 			return
 		}
 
@@ -72,20 +122,59 @@ func URLFetchProxyHandler() gin.HandlerFunc {
 			return
 		}
 
+		// Fetched pages go into model context, so scan them for prompt
+		// injection before handing them over; detections are flagged in
+		// the response and recorded on the tool audit trail
+		content := result.Content
+		detections := scanForInjection(content)
+		if len(detections) > 0 {
+			detail, _ := json.Marshal(detections)
+			recordToolAudit(db, "fetch_url", req.URL, string(detail))
+			logging.Module("fetcher").Warn("Injection patterns in fetched content",
+				"url", req.URL, "patterns", len(detections))
+			if req.Sanitize {
+				content = stripInjections(content)
+			}
+		}
+
+		// Trim to the budget by embedding similarity against the
+		// question; a failed embed falls back to a plain cut
+		budgeted := false
+		if budgeting && len(content) > budget {
+			originalLength := len(content)
+			extracted, err := ollamaService.extractRelevantContent(c.Request.Context(), req.EmbedModel, req.Question, content, budget)
+			if err != nil {
+				logging.Module("fetcher").Warn("Content budgeting failed, truncating instead",
+					"url", req.URL, "error", err)
+				extracted = content[:budget]
+			}
+			content = extracted
+			budgeted = true
+			result.Truncated = true
+			result.OriginalSize = originalLength
+		}
+
 		// Return the content
 		response := gin.H{
-			"content":     result.Content,
+			"content":     content,
 			"contentType": result.ContentType,
 			"url":         result.FinalURL,
 			"status":      result.StatusCode,
 			"fetchMethod": string(result.Method),
 		}
+		if len(detections) > 0 {
+			response["injectionDetections"] = detections
+			response["sanitized"] = req.Sanitize
+		}
 
 		// Include truncation info if content was truncated
 		if result.Truncated {
 			response["truncated"] = true
 			response["originalSize"] = result.OriginalSize
-			response["returnedSize"] = len(result.Content)
+			response["returnedSize"] = len(content)
+		}
+		if budgeted {
+			response["budgeted"] = true
 		}
 
 		c.JSON(http.StatusOK, response)
@@ -100,6 +189,7 @@ func GetFetchMethodHandler() gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{
 			"method":    string(fetcher.Method()),
 			"hasChrome": fetcher.HasChrome(),
+			"proxy":     fetcher.ProxyURL() != "",
 		})
 	}
 }
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
+)
+
+// pullBurstBytes is how far a throttled pull may run ahead of its
+// budget before being paused; pausing on every progress tick would turn
+// the download into a crawl of tiny resumes
+const pullBurstBytes int64 = 32 << 20
+
+// errPullPaused interrupts a pull that has outrun its bandwidth budget;
+// Ollama keeps completed layers, so the resumed pull picks up where it
+// stopped
+var errPullPaused = errors.New("pull paused for throttling")
+
+// pullWindowOpen reports whether scheduled pulls may run right now. An
+// unconfigured window is always open; a window may wrap midnight
+// ("23:00"-"07:00").
+func pullWindowOpen(now time.Time) bool {
+	cfg := config.Get().Ollama.Pull
+	start, errStart := time.Parse("15:04", cfg.WindowStart)
+	end, errEnd := time.Parse("15:04", cfg.WindowEnd)
+	if errStart != nil || errEnd != nil {
+		return true
+	}
+
+	minutes := now.Hour()*60 + now.Minute()
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+	if startMinutes == endMinutes {
+		return true
+	}
+	if startMinutes < endMinutes {
+		return minutes >= startMinutes && minutes < endMinutes
+	}
+	// Wraps midnight
+	return minutes >= startMinutes || minutes < endMinutes
+}
+
+// nextPullWindowOpen returns when the pull window next opens
+func nextPullWindowOpen(now time.Time) time.Time {
+	cfg := config.Get().Ollama.Pull
+	start, err := time.Parse("15:04", cfg.WindowStart)
+	if err != nil {
+		return now
+	}
+	open := time.Date(now.Year(), now.Month(), now.Day(), start.Hour(), start.Minute(), 0, 0, now.Location())
+	if !open.After(now) {
+		open = open.Add(24 * time.Hour)
+	}
+	return open
+}
+
+// throttledPull pulls a model, keeping the average download rate under
+// the configured cap. There is no rate knob on the Ollama API, so the
+// cap is enforced by duty-cycling: when the pull runs ahead of budget
+// it is cancelled, the worker sleeps off the excess, and the pull
+// resumes from the layers already on disk.
+func (s *OllamaService) throttledPull(ctx context.Context, model string) error {
+	maxBPS := config.Get().Ollama.Pull.MaxBytesPerSecond
+	if maxBPS <= 0 {
+		return s.client.Pull(ctx, &ollamaapi.PullRequest{Model: model}, func(resp ollamaapi.ProgressResponse) error {
+			return ctx.Err()
+		})
+	}
+
+	logger := logging.Module("pull")
+	start := time.Now()
+	for {
+		var completed int64
+		pullCtx, cancel := context.WithCancel(ctx)
+		err := s.client.Pull(pullCtx, &ollamaapi.PullRequest{Model: model}, func(resp ollamaapi.ProgressResponse) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			completed = resp.Completed
+			budget := int64(time.Since(start).Seconds()) * maxBPS
+			if completed > budget+pullBurstBytes {
+				return errPullPaused
+			}
+			return nil
+		})
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errPullPaused) {
+			return err
+		}
+
+		// Sleep until the budget catches up with what was downloaded
+		excess := completed - int64(time.Since(start).Seconds())*maxBPS
+		pause := time.Duration(excess/maxBPS) * time.Second
+		if pause < time.Second {
+			pause = time.Second
+		}
+		logger.Info("Pull paused for throttling", "model", model, "pause", pause.String())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pause):
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+)
+
+// Chunking defaults mirror the frontend RAG chunker so an eval run
+// reflects what retrieval actually sees in the app
+const (
+	ragEvalDefaultChunkSize = 512
+	ragEvalDefaultOverlap   = 50
+	ragEvalDefaultTopK      = 5
+	ragEvalEmbedBatchSize   = 64
+	ragEvalTimeoutTotal     = 5 * time.Minute
+)
+
+// ragCitationPattern matches [source:id] citations in generated answers
+var ragCitationPattern = regexp.MustCompile(`\[source:\s*([^\]\s]+)\s*\]`)
+
+// RAGEvalDocument is one source document of the collection under test
+type RAGEvalDocument struct {
+	ID   string `json:"id" binding:"required"`
+	Text string `json:"text" binding:"required"`
+}
+
+// RAGEvalCase is one question with the documents that should answer it
+type RAGEvalCase struct {
+	Question        string   `json:"question" binding:"required"`
+	ExpectedSources []string `json:"expected_sources" binding:"required,min=1"`
+}
+
+// RAGEvalRequest runs retrieval (and, with a model set, generation)
+// against an inline collection. Collections live client-side, so the
+// caller ships the documents; chunking knobs default to the app's own.
+type RAGEvalRequest struct {
+	EmbedModel string            `json:"embed_model" binding:"required"`
+	Model      string            `json:"model"`
+	TopK       int               `json:"top_k"`
+	ChunkSize  int               `json:"chunk_size"`
+	Overlap    int               `json:"overlap"`
+	Documents  []RAGEvalDocument `json:"documents" binding:"required,min=1"`
+	Cases      []RAGEvalCase     `json:"cases" binding:"required,min=1"`
+}
+
+// ragEvalCaseResult is one question's scores
+type ragEvalCaseResult struct {
+	Question         string   `json:"question"`
+	ExpectedSources  []string `json:"expected_sources"`
+	RetrievedSources []string `json:"retrieved_sources"`
+	Recall           float64  `json:"recall"`
+	Answer           string   `json:"answer,omitempty"`
+	CitedSources     []string `json:"cited_sources,omitempty"`
+	CitationAccuracy *float64 `json:"citation_accuracy,omitempty"`
+}
+
+// ragEvalChunk is one embedded chunk tagged with its source document
+type ragEvalChunk struct {
+	docID  string
+	text   string
+	vector []float32
+}
+
+// chunkForEval splits text into overlapping character windows, matching
+// the frontend chunker's defaults
+func chunkForEval(text string, size, overlap int) []string {
+	runes := []rune(text)
+	if len(runes) <= size {
+		return []string{text}
+	}
+	step := size - overlap
+	if step <= 0 {
+		step = size
+	}
+	chunks := []string{}
+	for start := 0; start < len(runes); start += step {
+		end := start + size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		chunks = append(chunks, string(runes[start:end]))
+		if end == len(runes) {
+			break
+		}
+	}
+	return chunks
+}
+
+// embedInBatches embeds the inputs through the configured embedding
+// model, batched so huge collections don't blow a single request
+func (s *OllamaService) embedInBatches(ctx context.Context, model string, inputs []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(inputs))
+	for start := 0; start < len(inputs); start += ragEvalEmbedBatchSize {
+		end := start + ragEvalEmbedBatchSize
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+		resp, err := s.client.Embed(ctx, &ollamaapi.EmbedRequest{Model: model, Input: inputs[start:end]})
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, resp.Embeddings...)
+	}
+	if len(vectors) != len(inputs) {
+		return nil, fmt.Errorf("embedding count mismatch: got %d for %d inputs", len(vectors), len(inputs))
+	}
+	return vectors, nil
+}
+
+// retrieveSources returns the distinct source document IDs of the top-k
+// chunks by cosine similarity, in rank order
+func retrieveSources(chunks []ragEvalChunk, query []float32, topK int) []string {
+	type ranked struct {
+		index      int
+		similarity float64
+	}
+	scores := make([]ranked, len(chunks))
+	for i := range chunks {
+		scores[i] = ranked{index: i, similarity: cosineSimilarity(chunks[i].vector, query)}
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].similarity > scores[j].similarity })
+
+	sources := []string{}
+	seen := map[string]bool{}
+	for i := 0; i < len(scores) && i < topK; i++ {
+		docID := chunks[scores[i].index].docID
+		if !seen[docID] {
+			seen[docID] = true
+			sources = append(sources, docID)
+		}
+	}
+	return sources
+}
+
+// RAGEvalHandler scores retrieval quality for a collection: recall@k
+// over the eval cases and, when a generation model is given, how
+// accurately the model cites the expected sources. Meant for tuning
+// chunking and embedding settings with data instead of vibes.
+func (s *OllamaService) RAGEvalHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RAGEvalRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		if req.TopK <= 0 {
+			req.TopK = ragEvalDefaultTopK
+		}
+		if req.ChunkSize <= 0 {
+			req.ChunkSize = ragEvalDefaultChunkSize
+		}
+		if req.Overlap < 0 || req.Overlap >= req.ChunkSize {
+			req.Overlap = ragEvalDefaultOverlap
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), ragEvalTimeoutTotal)
+		defer cancel()
+
+		// Chunk and embed the collection once
+		chunks := []ragEvalChunk{}
+		texts := []string{}
+		for _, doc := range req.Documents {
+			for _, text := range chunkForEval(doc.Text, req.ChunkSize, req.Overlap) {
+				chunks = append(chunks, ragEvalChunk{docID: doc.ID, text: text})
+				texts = append(texts, text)
+			}
+		}
+		vectors, err := s.embedInBatches(ctx, req.EmbedModel, texts)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to embed collection: " + err.Error()})
+			return
+		}
+		for i := range chunks {
+			chunks[i].vector = vectors[i]
+		}
+
+		questions := make([]string, len(req.Cases))
+		for i, evalCase := range req.Cases {
+			questions[i] = evalCase.Question
+		}
+		queryVectors, err := s.embedInBatches(ctx, req.EmbedModel, questions)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to embed questions: " + err.Error()})
+			return
+		}
+
+		results := make([]ragEvalCaseResult, len(req.Cases))
+		totalRecall := 0.0
+		totalCitation := 0.0
+		citationRuns := 0
+		for i, evalCase := range req.Cases {
+			retrieved := retrieveSources(chunks, queryVectors[i], req.TopK)
+			result := ragEvalCaseResult{
+				Question:         evalCase.Question,
+				ExpectedSources:  evalCase.ExpectedSources,
+				RetrievedSources: retrieved,
+				Recall:           sourceRecall(evalCase.ExpectedSources, retrieved),
+			}
+			totalRecall += result.Recall
+
+			if req.Model != "" {
+				answer, err := s.generateCitedAnswer(ctx, req.Model, evalCase.Question, chunks, queryVectors[i], req.TopK)
+				if err != nil {
+					c.JSON(http.StatusBadGateway, gin.H{"error": "generation failed: " + err.Error()})
+					return
+				}
+				result.Answer = answer
+				result.CitedSources = citedSources(answer)
+				accuracy := citationAccuracy(evalCase.ExpectedSources, result.CitedSources)
+				result.CitationAccuracy = &accuracy
+				totalCitation += accuracy
+				citationRuns++
+			}
+			results[i] = result
+		}
+
+		response := gin.H{
+			"embed_model": req.EmbedModel,
+			"top_k":       req.TopK,
+			"chunk_size":  req.ChunkSize,
+			"overlap":     req.Overlap,
+			"chunks":      len(chunks),
+			"recall_at_k": totalRecall / float64(len(req.Cases)),
+			"cases":       results,
+		}
+		if citationRuns > 0 {
+			response["model"] = req.Model
+			response["citation_accuracy"] = totalCitation / float64(citationRuns)
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// sourceRecall is the fraction of expected sources present in the
+// retrieved set
+func sourceRecall(expected, retrieved []string) float64 {
+	if len(expected) == 0 {
+		return 0
+	}
+	got := map[string]bool{}
+	for _, id := range retrieved {
+		got[id] = true
+	}
+	hits := 0
+	for _, id := range expected {
+		if got[id] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(expected))
+}
+
+// citationAccuracy is the fraction of cited sources that were expected;
+// an answer citing nothing scores zero
+func citationAccuracy(expected, cited []string) float64 {
+	if len(cited) == 0 {
+		return 0
+	}
+	want := map[string]bool{}
+	for _, id := range expected {
+		want[id] = true
+	}
+	correct := 0
+	for _, id := range cited {
+		if want[id] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(cited))
+}
+
+// citedSources extracts the distinct [source:id] citations from an
+// answer, in order of first appearance
+func citedSources(answer string) []string {
+	sources := []string{}
+	seen := map[string]bool{}
+	for _, match := range ragCitationPattern.FindAllStringSubmatch(answer, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			sources = append(sources, match[1])
+		}
+	}
+	return sources
+}
+
+// generateCitedAnswer answers the question from the top-k retrieved
+// chunks, instructing the model to cite sources as [source:id]
+func (s *OllamaService) generateCitedAnswer(ctx context.Context, model, question string, chunks []ragEvalChunk, query []float32, topK int) (string, error) {
+	type ranked struct {
+		index      int
+		similarity float64
+	}
+	scores := make([]ranked, len(chunks))
+	for i := range chunks {
+		scores[i] = ranked{index: i, similarity: cosineSimilarity(chunks[i].vector, query)}
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].similarity > scores[j].similarity })
+
+	var contextText strings.Builder
+	for i := 0; i < len(scores) && i < topK; i++ {
+		chunk := chunks[scores[i].index]
+		fmt.Fprintf(&contextText, "[source:%s]\n%s\n\n", chunk.docID, chunk.text)
+	}
+
+	prompt := fmt.Sprintf(
+		"Answer the question using only the sources below. Cite every source you use inline as [source:id]. If the sources don't answer the question, say so.\n\nSources:\n%s\nQuestion: %s",
+		contextText.String(), question,
+	)
+
+	var answer strings.Builder
+	err := s.client.Generate(ctx, &ollamaapi.GenerateRequest{
+		Model:  model,
+		Prompt: prompt,
+		Stream: new(bool),
+	}, func(resp ollamaapi.GenerateResponse) error {
+		answer.WriteString(resp.Response)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return answer.String(), nil
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/models"
+)
+
+// defaultDeviceID stands in when a client doesn't identify itself, so
+// single-device setups get read tracking without any extra plumbing
+const defaultDeviceID = "default"
+
+// readStateDeviceID resolves the calling device: the X-Vessel-Device
+// header, the ?device= query, or the shared default
+func readStateDeviceID(c *gin.Context) string {
+	if device := c.GetHeader("X-Vessel-Device"); device != "" {
+		return device
+	}
+	if device := c.Query("device"); device != "" {
+		return device
+	}
+	return defaultDeviceID
+}
+
+// MarkChatReadRequest represents the request body for updating a read
+// position; an empty message_id marks the whole chat as read
+type MarkChatReadRequest struct {
+	MessageID string `json:"message_id"`
+}
+
+// MarkChatReadHandler records the calling device's last-read message in
+// a chat (PUT /chats/:id/read)
+func MarkChatReadHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chatID := c.Param("id")
+		device := readStateDeviceID(c)
+
+		var req MarkChatReadRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		chat, err := models.GetChat(db, chatID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if chat == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+			return
+		}
+
+		messageID := req.MessageID
+		var messageAt string
+		if messageID == "" {
+			// No explicit position: everything currently in the chat
+			// counts as read
+			if len(chat.Messages) == 0 {
+				c.JSON(http.StatusOK, gin.H{"chat_id": chatID, "device_id": device, "unread": 0})
+				return
+			}
+			last := chat.Messages[len(chat.Messages)-1]
+			messageID = last.ID
+			messageAt = last.CreatedAt.Format(time.RFC3339)
+		} else {
+			msg, err := models.GetMessage(db, messageID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if msg == nil || msg.ChatID != chatID {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "message does not belong to this chat"})
+				return
+			}
+			messageAt = msg.CreatedAt.Format(time.RFC3339)
+		}
+
+		if err := models.SetChatReadState(db, chatID, device, messageID, messageAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		unread, err := models.CountUnreadMessages(db, chatID, device)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"chat_id":              chatID,
+			"device_id":            device,
+			"last_read_message_id": messageID,
+			"unread":               unread,
+		})
+	}
+}
+
+// GetChatReadStateHandler returns the calling device's read position
+// and unread count for one chat (GET /chats/:id/read)
+func GetChatReadStateHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chatID := c.Param("id")
+		device := readStateDeviceID(c)
+
+		state, err := models.GetChatReadState(db, chatID, device)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		unread, err := models.CountUnreadMessages(db, chatID, device)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := gin.H{"chat_id": chatID, "device_id": device, "unread": unread}
+		if state != nil {
+			response["last_read_message_id"] = state.LastReadMessageID
+			response["updated_at"] = state.UpdatedAt
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// UnreadCountsHandler returns the calling device's unread counts for
+// every chat with new messages (GET /chats/unread)
+func UnreadCountsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		device := readStateDeviceID(c)
+
+		counts, err := models.ListUnreadCounts(db, device)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"device_id": device, "unread": counts})
+	}
+}
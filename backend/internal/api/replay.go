@@ -0,0 +1,281 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// GenerationParams identifies the model and full option set behind one
+// generation; stored per assistant message so it can be replayed exactly
+type GenerationParams struct {
+	Model   string                 `json:"model"`
+	Options map[string]interface{} `json:"options"`
+}
+
+// ensureSeed pins a random seed on requests that don't set one, so the
+// recorded parameters are enough to reproduce the generation
+func ensureSeed(options map[string]interface{}) map[string]interface{} {
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	if _, set := options["seed"]; !set {
+		options["seed"] = rand.Int31()
+	}
+	return options
+}
+
+// appendGenerationParams injects the effective model and options into an
+// already-marshaled final frame so the client can store them verbatim
+// alongside the assistant message. Pass-through on decode trouble.
+func appendGenerationParams(frame []byte, model string, options map[string]interface{}) []byte {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(frame, &decoded); err != nil {
+		return frame
+	}
+	decoded["generation"] = GenerationParams{Model: model, Options: options}
+
+	enriched, err := json.Marshal(decoded)
+	if err != nil {
+		return frame
+	}
+	return enriched
+}
+
+// GetMessageGenerationHandler returns the recorded generation parameters
+// for a message
+func GetMessageGenerationHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gen, err := models.GetMessageGeneration(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if gen == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no generation parameters recorded for message"})
+			return
+		}
+		c.JSON(http.StatusOK, gen)
+	}
+}
+
+// ReplayMessageRequest optionally redirects a replay to another model;
+// everything else is taken from the recorded parameters
+type ReplayMessageRequest struct {
+	Model string `json:"model"`
+}
+
+// ReplayMessageHandler re-runs an assistant message with the exact
+// parameters it was generated with (optionally against a different
+// model) and stores the result as a sibling for side-by-side comparison
+func (s *OllamaService) ReplayMessageHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ReplayMessageRequest
+		if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		msg, err := models.GetMessage(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if msg == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "message not found"})
+			return
+		}
+		if msg.Role != "assistant" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "only assistant messages can be replayed"})
+			return
+		}
+
+		gen, err := models.GetMessageGeneration(db, msg.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if gen == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "no generation parameters recorded for message"})
+			return
+		}
+
+		model := gen.Model
+		if req.Model != "" {
+			model = req.Model
+		}
+
+		messages, err := models.GetMessagesByChatID(db, msg.ChatID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		history := historyBeforeMessage(messages, msg)
+		if len(history) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "message has no conversation history to replay"})
+			return
+		}
+
+		chatReq := &ollamaapi.ChatRequest{
+			Model:    model,
+			Messages: history,
+			Stream:   new(bool),
+			Options:  gen.Options,
+		}
+
+		var finalResp ollamaapi.ChatResponse
+		err = s.client.Chat(c.Request.Context(), chatReq, func(resp ollamaapi.ChatResponse) error {
+			finalResp = resp
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "replay failed: " + err.Error()})
+			return
+		}
+
+		recordGeneration(db, &models.GenerationMetric{
+			Model:            model,
+			User:             metricUser(c),
+			Kind:             "chat",
+			PromptTokens:     finalResp.Metrics.PromptEvalCount,
+			CompletionTokens: finalResp.Metrics.EvalCount,
+			DurationMs:       finalResp.Metrics.TotalDuration.Milliseconds(),
+			QueueMs:          finalResp.Metrics.LoadDuration.Milliseconds(),
+			ToolCalls:        len(finalResp.Message.ToolCalls),
+		})
+
+		sibling := &models.Message{
+			ChatID:       msg.ChatID,
+			ParentID:     msg.ParentID,
+			Role:         "assistant",
+			Content:      finalResp.Message.Content,
+			SiblingIndex: nextSiblingIndex(messages, msg.ParentID),
+		}
+		if err := models.CreateMessage(db, sibling); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := models.SetMessageGeneration(db, sibling.ID, model, gen.Options); err != nil {
+			logging.Module("replay").Warn("Failed to record replay generation", "messageId", sibling.ID, "error", err)
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message":    sibling,
+			"generation": GenerationParams{Model: model, Options: gen.Options},
+		})
+	}
+}
+
+// historyBeforeMessage rebuilds the conversation the model saw when the
+// given assistant message was generated, by walking its parent chain
+// back to the root
+func historyBeforeMessage(messages []models.Message, msg *models.Message) []ollamaapi.Message {
+	byID := make(map[string]*models.Message, len(messages))
+	for i := range messages {
+		byID[messages[i].ID] = &messages[i]
+	}
+
+	var chain []ollamaapi.Message
+	for id := msg.ParentID; id != nil; {
+		parent, ok := byID[*id]
+		if !ok {
+			break
+		}
+		chain = append(chain, ollamaapi.Message{Role: parent.Role, Content: parent.Content})
+		id = parent.ParentID
+	}
+
+	// The chain was collected leaf-first; reverse into chronological order
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+// nextSiblingIndex returns one past the highest sibling index under the
+// given parent (nil for root-level messages)
+func nextSiblingIndex(messages []models.Message, parentID *string) int {
+	next := 0
+	for i := range messages {
+		m := &messages[i]
+		sameParent := (m.ParentID == nil && parentID == nil) ||
+			(m.ParentID != nil && parentID != nil && *m.ParentID == *parentID)
+		if sameParent && m.SiblingIndex >= next {
+			next = m.SiblingIndex + 1
+		}
+	}
+	return next
+}
+
+// siblingEntry pairs one sibling response with its recorded generation
+// parameters, so a compare view can show the answers side by side with
+// what produced them
+type siblingEntry struct {
+	Message    *models.Message           `json:"message"`
+	Generation *models.MessageGeneration `json:"generation,omitempty"`
+}
+
+// sameParent compares two optional parent IDs; two nils mean both
+// messages hang off the chat root
+func sameParent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// SiblingMessagesHandler returns a message's siblings - the alternative
+// responses sharing its parent (regenerations, replays, fan-outs) - each
+// with its recorded generation metadata, ordered by sibling index
+func SiblingMessagesHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		msg, err := models.GetMessage(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if msg == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "message not found"})
+			return
+		}
+
+		messages, err := models.GetMessagesByChatID(db, msg.ChatID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		siblings := []siblingEntry{}
+		for i := range messages {
+			m := &messages[i]
+			if !sameParent(m.ParentID, msg.ParentID) {
+				continue
+			}
+			generation, err := models.GetMessageGeneration(db, m.ID)
+			if err != nil {
+				logging.Module("replay").Warn("Failed to load generation for sibling", "messageId", m.ID, "error", err)
+				generation = nil
+			}
+			siblings = append(siblings, siblingEntry{Message: m, Generation: generation})
+		}
+		sort.Slice(siblings, func(i, j int) bool {
+			return siblings[i].Message.SiblingIndex < siblings[j].Message.SiblingIndex
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"chat_id":   msg.ChatID,
+			"parent_id": msg.ParentID,
+			"siblings":  siblings,
+		})
+	}
+}
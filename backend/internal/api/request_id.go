@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the header used to accept and return request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the gin context key holding the request ID
+const requestIDKey = "requestID"
+
+// requestIDContextKey is the type-safe key for the standard context, so the
+// ID travels with c.Request.Context() into downstream HTTP calls
+type requestIDContextKey struct{}
+
+// RequestIDMiddleware assigns every request a correlation ID. An incoming
+// X-Request-ID is honored (truncated to a sane length) so IDs survive a
+// reverse proxy; otherwise a fresh UUID is generated. The ID is echoed in
+// the response header, attached to the gin context for logging, and stored
+// in the request context so outbound calls (Ollama, fetches) can carry it.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = uuid.New().String()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		ctx := context.WithValue(c.Request.Context(), requestIDContextKey{}, id)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// RequestID returns the correlation ID for the current request, or "" when
+// the middleware isn't installed
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// requestIDFromContext extracts the correlation ID from a standard context
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// requestIDTransport injects the correlation ID from the request context
+// into outbound requests, so Ollama logs can be matched to ours
+type requestIDTransport struct {
+	base http.RoundTripper
+}
+
+func (t requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := requestIDFromContext(req.Context()); id != "" && req.Header.Get(RequestIDHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(RequestIDHeader, id)
+	}
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
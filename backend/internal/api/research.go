@@ -0,0 +1,161 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ollama/ollama/api"
+)
+
+// ResearchRequest represents a combined search-fetch-summarize request
+type ResearchRequest struct {
+	Query      string `json:"query" binding:"required"`
+	MaxSources int    `json:"maxSources"` // Number of results to fetch (default 3, max 5)
+	Freshness  string `json:"freshness"`  // Date filter: "day", "week", "month", "year"
+	Region     string `json:"region"`     // Region code (e.g., "us-en", "de-de")
+	Summarize  bool   `json:"summarize"`  // Summarize each source with a local model
+	Model      string `json:"model"`      // Model to use for summarization
+}
+
+// ResearchSource represents one fetched and processed source
+type ResearchSource struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+	Content string `json:"content,omitempty"`
+	Summary string `json:"summary,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// maxResearchContentLength caps the readable text extracted per source (in bytes)
+const maxResearchContentLength = 8000
+
+// ResearchHandler returns a handler that searches, fetches the top results,
+// extracts readable text, and optionally summarizes each source with a local
+// model - the building block for a web research chat mode.
+func ResearchHandler(ollamaService *OllamaService) gin.HandlerFunc {
+	fetcher := GetFetcher()
+
+	return func(c *gin.Context) {
+		var req ResearchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		maxSources := req.MaxSources
+		if maxSources <= 0 {
+			maxSources = 3
+		}
+		if maxSources > 5 {
+			maxSources = 5
+		}
+
+		if req.Summarize {
+			if ollamaService == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "summarization unavailable: Ollama service not initialized"})
+				return
+			}
+			if req.Model == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "model is required when summarize is true"})
+				return
+			}
+		}
+
+		// The query leaves the machine, so scrub any PII first
+		query := scrubOutboundText(req.Query, "research")
+
+		results, _, err := searchDuckDuckGo(c.Request.Context(), fetcher, query, maxSources, req.Freshness, req.Region, 20*time.Second)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Fetch sources concurrently - they are independent of each other
+		sources := make([]ResearchSource, len(results))
+		var wg sync.WaitGroup
+		for i, result := range results {
+			sources[i] = ResearchSource{
+				Title:   result.Title,
+				URL:     result.URL,
+				Snippet: result.Snippet,
+			}
+
+			wg.Add(1)
+			go func(i int, url string) {
+				defer wg.Done()
+
+				opts := DefaultFetchOptions()
+				opts.Timeout = 20 * time.Second
+
+				fetched, err := fetcher.Fetch(c.Request.Context(), url, opts)
+				if err != nil {
+					sources[i].Error = err.Error()
+					return
+				}
+				if fetched.StatusCode >= 400 {
+					sources[i].Error = fmt.Sprintf("HTTP %d", fetched.StatusCode)
+					return
+				}
+
+				// Extract readable text and cap it so summarization stays fast
+				content := stripHTMLTags(fetched.Content)
+				if len(content) > maxResearchContentLength {
+					content = content[:maxResearchContentLength]
+				}
+				sources[i].Content = content
+			}(i, result.URL)
+		}
+		wg.Wait()
+
+		// Summarize sequentially - local models generally can't serve
+		// parallel requests any faster than serial ones
+		if req.Summarize {
+			for i := range sources {
+				if sources[i].Content == "" {
+					continue
+				}
+				summary, err := summarizeSource(c, ollamaService, req.Model, req.Query, sources[i].Content)
+				if err != nil {
+					sources[i].Error = "summarization failed: " + err.Error()
+					continue
+				}
+				sources[i].Summary = summary
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"query":   req.Query,
+			"sources": sources,
+			"count":   len(sources),
+		})
+	}
+}
+
+// summarizeSource asks a local model for a short summary of one source
+func summarizeSource(c *gin.Context, ollamaService *OllamaService, model, query, content string) (string, error) {
+	prompt := fmt.Sprintf(
+		"Summarize the following web page content in 2-4 sentences, focusing on information relevant to the question: %q\n\n%s",
+		query, content,
+	)
+
+	stream := false
+	genReq := &api.GenerateRequest{
+		Model:  model,
+		Prompt: prompt,
+		Stream: &stream,
+	}
+
+	var summary string
+	err := ollamaService.Client().Generate(c.Request.Context(), genReq, func(resp api.GenerateResponse) error {
+		summary = resp.Response
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return summary, nil
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"vessel-backend/internal/config"
+)
+
+// Fallbacks when the cache is enabled without explicit limits
+const (
+	responseCacheDefaultTTL        = 5 * time.Minute
+	responseCacheDefaultMaxEntries = 256
+)
+
+// responseCacheEntry is one cached response body with its expiry
+type responseCacheEntry struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+// responseCache is a small in-memory TTL cache for non-streaming
+// generate and embed responses, keyed by a hash of the full request.
+// Streamed responses are never cached - their value is in the streaming.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]responseCacheEntry
+}
+
+// ollamaResponseCache backs the generate and embed handlers
+var ollamaResponseCache = &responseCache{entries: map[string]responseCacheEntry{}}
+
+// responseCacheTTL returns the configured TTL, falling back to the
+// default when unset
+func responseCacheTTL() time.Duration {
+	if secs := config.Get().Ollama.Cache.TTLSeconds; secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return responseCacheDefaultTTL
+}
+
+// responseCacheKey hashes the request so that any difference in model,
+// prompt, or options produces a distinct key. Returns empty (meaning
+// "don't cache") when the cache is disabled or the request won't marshal.
+func responseCacheKey(kind string, req interface{}) string {
+	if !config.Get().Ollama.Cache.Enabled {
+		return ""
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(append([]byte(kind+"\n"), payload...))
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns the cached body for key, dropping it when expired
+func (rc *responseCache) get(key string) ([]byte, bool) {
+	if key == "" {
+		return nil, false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(rc.entries, key)
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// put stores a response body under key, evicting expired entries first
+// and then the soonest-to-expire one while over the size limit
+func (rc *responseCache) put(key string, body []byte) {
+	if key == "" {
+		return
+	}
+	maxEntries := config.Get().Ollama.Cache.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = responseCacheDefaultMaxEntries
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range rc.entries {
+		if now.After(entry.expiresAt) {
+			delete(rc.entries, k)
+		}
+	}
+	for len(rc.entries) >= maxEntries {
+		oldest := ""
+		for k, entry := range rc.entries {
+			if oldest == "" || entry.expiresAt.Before(rc.entries[oldest].expiresAt) {
+				oldest = k
+			}
+		}
+		delete(rc.entries, oldest)
+	}
+
+	rc.entries[key] = responseCacheEntry{body: body, expiresAt: now.Add(responseCacheTTL())}
+}
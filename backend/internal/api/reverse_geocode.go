@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// nominatimResponse maps the fields we need from a Nominatim reverse
+// geocoding answer (jsonv2 format)
+type nominatimResponse struct {
+	DisplayName string `json:"display_name"`
+	Address     struct {
+		City        string `json:"city"`
+		Town        string `json:"town"`
+		Village     string `json:"village"`
+		State       string `json:"state"`
+		Country     string `json:"country"`
+		CountryCode string `json:"country_code"`
+	} `json:"address"`
+}
+
+// ReverseGeocodeHandler turns coordinates (usually from the browser's
+// geolocation API) into a city/region/country via Nominatim, so the
+// weather and location tools can show a human-readable place name.
+// Results are cached by rounded coordinates - ~100m precision is plenty
+// for place names and keeps us well within Nominatim's usage policy.
+func ReverseGeocodeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+		lon, lonErr := strconv.ParseFloat(c.Query("lon"), 64)
+		if latErr != nil || lonErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lon query parameters are required"})
+			return
+		}
+		if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "coordinates out of range"})
+			return
+		}
+
+		cacheKey := fmt.Sprintf("rev:%.3f,%.3f", lat, lon)
+		if location := geoCacheGet(cacheKey); location != nil {
+			c.JSON(http.StatusOK, location)
+			return
+		}
+
+		url := fmt.Sprintf("https://nominatim.openstreetmap.org/reverse?format=jsonv2&lat=%.6f&lon=%.6f&zoom=10", lat, lon)
+		req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, url, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		// Nominatim's usage policy requires an identifying User-Agent
+		req.Header.Set("User-Agent", "Vessel-Backend")
+
+		resp, err := geoHTTPClient.Do(req)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "failed to reach geocoding service: " + err.Error()})
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("geocoding service returned HTTP %d", resp.StatusCode)})
+			return
+		}
+
+		var nominatim nominatimResponse
+		if err := json.NewDecoder(resp.Body).Decode(&nominatim); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse geocoding response"})
+			return
+		}
+
+		// Nominatim uses city/town/village depending on settlement size
+		city := nominatim.Address.City
+		if city == "" {
+			city = nominatim.Address.Town
+		}
+		if city == "" {
+			city = nominatim.Address.Village
+		}
+
+		location := &LocationResponse{
+			Success:     true,
+			City:        city,
+			Region:      nominatim.Address.State,
+			Country:     nominatim.Address.Country,
+			CountryCode: nominatim.Address.CountryCode,
+			Latitude:    lat,
+			Longitude:   lon,
+			Source:      "reverse",
+		}
+		geoCacheSet(cacheKey, location)
+		c.JSON(http.StatusOK, location)
+	}
+}
@@ -2,17 +2,23 @@ package api
 
 import (
 	"database/sql"
-	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
 )
 
 // SetupRoutes configures all API routes
 func SetupRoutes(r *gin.Engine, db *sql.DB, ollamaURL string, appVersion string) {
+	// Wire the webhook event dispatcher before any handler can emit
+	InitEvents(db)
+
 	// Initialize Ollama service with official client
 	ollamaService, err := NewOllamaService(ollamaURL)
 	if err != nil {
-		log.Printf("Warning: Failed to initialize Ollama service: %v", err)
+		logging.Module("api").Warn("Failed to initialize Ollama service", "error", err)
 	}
 
 	// Initialize model registry service
@@ -23,51 +29,362 @@ func SetupRoutes(r *gin.Engine, db *sql.DB, ollamaURL string, appVersion string)
 		modelRegistry = NewModelRegistryService(db, nil)
 	}
 
-	// Health check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// Persistent background job queue and its built-in job kinds
+	StartJobQueue(db)
+	registerBuiltinJobs(db, ollamaService, modelRegistry)
+
+	// Optional Telegram bot bridge (no-op without a configured token)
+	StartTelegramBridge(db, ollamaService)
+
+	// Scheduled email digest (no-op without SMTP and a cadence configured)
+	StartEmailDigest(db)
 
-	// Version endpoint (for update notifications)
+	// Startup drift check for declaratively provisioned models (no-op
+	// without a configured list)
+	StartProvisioning(db, ollamaService)
+
+	// Health check (legacy) plus Kubernetes-style liveness and readiness
+	r.GET("/health", HealthzHandler())
+	r.GET("/healthz", HealthzHandler())
+	r.GET("/readyz", ReadyzHandler(db, ollamaService, config.Get().Database.Path))
+
+	// Version endpoint (for update notifications) and release notes
 	r.GET("/api/v1/version", VersionHandler(appVersion))
+	r.GET("/api/v1/version/changelog", ChangelogHandler(appVersion))
 
 	// API v1 routes
 	v1 := r.Group("/api/v1")
 	{
-		// Chat routes
-		chats := v1.Group("/chats")
+		// Chat routes - quick CRUD, so bounded by a timeout; the message
+		// route allows larger bodies for base64 attachments
+		chats := v1.Group("/chats", RequestTimeout(crudTimeout), MaxBodySize(attachmentBodyLimit))
 		{
 			chats.GET("", ListChatsHandler(db))
 			chats.GET("/grouped", ListGroupedChatsHandler(db))
+			chats.GET("/unread", UnreadCountsHandler(db))
 			chats.POST("", CreateChatHandler(db))
 			chats.GET("/:id", GetChatHandler(db))
 			chats.PUT("/:id", UpdateChatHandler(db))
 			chats.DELETE("/:id", DeleteChatHandler(db))
 
+			// Retention lock: a locked chat survives deletes and cold
+			// storage sweeps until unlocked
+			chats.PUT("/:id/lock", MaxBodySize(smallBodyLimit), LockChatHandler(db))
+
+			// Per-device read positions and unread counts
+			chats.GET("/:id/read", GetChatReadStateHandler(db))
+			chats.PUT("/:id/read", MaxBodySize(smallBodyLimit), MarkChatReadHandler(db))
+
 			// Message routes (nested under chats)
 			chats.POST("/:id/messages", CreateMessageHandler(db))
+
+			// Attachment metadata and transcripts for a chat
+			chats.GET("/:id/attachments", ListChatAttachmentsHandler(db))
+
+			// Image/audio gallery view of the chat's attachments
+			chats.GET("/:id/media", ChatMediaHandler(db))
+
+			// Context window strategy applied by the completion endpoint
+			chats.PUT("/:id/context-strategy", SetContextStrategyHandler(db))
+			if ollamaService != nil {
+				chats.GET("/:id/context-preview", ollamaService.ContextPreviewHandler(db))
+			}
+			chats.GET("/:id/tags", GetChatTagsHandler(db))
+			chats.GET("/:id/options", GetChatOptionsHandler(db))
+			chats.PUT("/:id/options", MaxBodySize(smallBodyLimit), SetChatOptionsHandler(db))
+
+			// Self-contained HTML export of one conversation;
+			// ?format=pdf prints it through headless Chrome instead
+			chats.GET("/:id/export", ExportChatHandler(db))
+			chats.GET("/:id/export/html", ExportChatHTMLHandler(db))
+
+			// Versioned artifacts produced by the model in this chat
+			chats.GET("/:id/artifacts", ListArtifactsHandler(db))
+			chats.POST("/:id/artifacts", CreateArtifactHandler(db))
+		}
+
+		// Attachment file data, served lazily with caching headers and
+		// optional thumbnails instead of inlined into chat payloads
+		v1.GET("/attachments/:id", DownloadAttachmentHandler(db))
+		v1.GET("/media", MediaHandler(db))
+
+		// RAG retrieval quality eval: recall@k and citation accuracy over
+		// an inline collection (collections themselves live client-side)
+		if ollamaService != nil {
+			v1.POST("/rag/eval", MaxBodySize(attachmentBodyLimit), ollamaService.RAGEvalHandler())
+		}
+
+		// Embedding model registry per knowledge collection, plus
+		// background re-embedding migrations for model switches
+		embeddings := v1.Group("/embeddings")
+		{
+			embeddings.GET("/collections", ListCollectionEmbeddingsHandler(db))
+			embeddings.PUT("/collections/:id", MaxBodySize(smallBodyLimit), RegisterCollectionEmbeddingHandler(db))
+			embeddings.DELETE("/collections/:id", DeleteCollectionEmbeddingHandler(db))
+			if ollamaService != nil {
+				embeddings.POST("/migrations", MaxBodySize(attachmentBodyLimit), ollamaService.StartEmbeddingMigrationHandler(db))
+			}
+			embeddings.GET("/migrations/:id", GetEmbeddingMigrationHandler())
+
+			// Bulk embedding through the job queue, for ingestions too
+			// large for the single-shot /embed endpoint
+			embeddings.POST("/batch", MaxBodySize(attachmentBodyLimit), StartEmbedBatchHandler(db))
+			embeddings.GET("/batch/:id", GetEmbedBatchHandler(db))
+		}
+
+		// Thumbs-up/down feedback on individual messages
+		v1.POST("/messages/:id/rating", MaxBodySize(smallBodyLimit), RateMessageHandler(db))
+		v1.DELETE("/messages/:id/rating", ClearMessageRatingHandler(db))
+		v1.GET("/messages/:id/generation", GetMessageGenerationHandler(db))
+		v1.GET("/messages/:id/siblings", SiblingMessagesHandler(db))
+		if ollamaService != nil {
+			v1.POST("/messages/:id/replay", MaxBodySize(smallBodyLimit), ollamaService.ReplayMessageHandler(db))
+			v1.POST("/messages/:id/fanout", MaxBodySize(smallBodyLimit), ollamaService.FanoutMessageHandler(db))
 		}
 
-		// Sync routes
-		sync := v1.Group("/sync")
+		// Sync routes - pushes batch many records, so a bigger cap and a
+		// longer deadline than plain CRUD
+		sync := v1.Group("/sync", RequestTimeout(2*time.Minute), MaxBodySize(syncBodyLimit))
 		{
 			sync.POST("/push", PushChangesHandler(db))
 			sync.GET("/pull", PullChangesHandler(db))
 		}
 
+		// Server-side user settings (shared across browsers/devices)
+		v1.GET("/settings", GetSettingsHandler(db))
+		v1.PATCH("/settings", MaxBodySize(smallBodyLimit), PatchSettingsHandler(db))
+
+		// Artifact versions (content, download, diff, reopen into context)
+		artifacts := v1.Group("/artifacts", MaxBodySize(smallBodyLimit))
+		{
+			artifacts.GET("/:id", GetArtifactHandler(db))
+			artifacts.GET("/:id/download", DownloadArtifactHandler(db))
+			artifacts.GET("/:id/diff", DiffArtifactHandler(db))
+			artifacts.POST("/:id/reopen", ReopenArtifactHandler(db))
+		}
+
+		// Workspaces (chat namespaces with model allowlists and membership)
+		workspaces := v1.Group("/workspaces", MaxBodySize(smallBodyLimit))
+		{
+			workspaces.GET("", ListWorkspacesHandler(db))
+			workspaces.POST("", CreateWorkspaceHandler(db))
+			workspaces.GET("/:id", GetWorkspaceHandler(db))
+			workspaces.PUT("/:id", UpdateWorkspaceHandler(db))
+			workspaces.DELETE("/:id", DeleteWorkspaceHandler(db))
+			workspaces.POST("/:id/members", AddWorkspaceMemberHandler(db))
+			workspaces.DELETE("/:id/members/:user", RemoveWorkspaceMemberHandler(db))
+		}
+
+		// Assistant personas (named system prompt + model + parameter bundles)
+		personas := v1.Group("/personas", MaxBodySize(smallBodyLimit))
+		{
+			personas.GET("", ListPersonasHandler(db))
+			personas.POST("", CreatePersonaHandler(db))
+			personas.GET("/:id", GetPersonaHandler(db))
+			personas.PUT("/:id", UpdatePersonaHandler(db))
+			personas.DELETE("/:id", DeletePersonaHandler(db))
+		}
+
+		// Named generation option bundles, selectable per completion via
+		// ?preset= on the chat endpoint
+		presets := v1.Group("/presets", MaxBodySize(smallBodyLimit))
+		{
+			presets.GET("", ListGenerationPresetsHandler(db))
+			presets.POST("", CreateGenerationPresetHandler(db))
+			presets.GET("/:id", GetGenerationPresetHandler(db))
+			presets.PUT("/:id", UpdateGenerationPresetHandler(db))
+			presets.DELETE("/:id", DeleteGenerationPresetHandler(db))
+		}
+
+		// Prompt template library ({{variable}} substitution, categories)
+		templates := v1.Group("/templates", MaxBodySize(smallBodyLimit))
+		{
+			templates.GET("", ListPromptTemplatesHandler(db))
+			templates.POST("", CreatePromptTemplateHandler(db))
+			templates.GET("/:id", GetPromptTemplateHandler(db))
+			templates.PUT("/:id", UpdatePromptTemplateHandler(db))
+			templates.DELETE("/:id", DeletePromptTemplateHandler(db))
+			templates.POST("/:id/render", RenderPromptTemplateHandler(db))
+		}
+
+		// Reusable system prompt snippets, referenced as {{snippet:name}}
+		// from persona and chat system prompts
+		snippets := v1.Group("/snippets", MaxBodySize(smallBodyLimit))
+		{
+			snippets.GET("", ListPromptSnippetsHandler(db))
+			snippets.POST("", CreatePromptSnippetHandler(db))
+			snippets.GET("/:id", GetPromptSnippetHandler(db))
+			snippets.PUT("/:id", UpdatePromptSnippetHandler(db))
+			snippets.DELETE("/:id", DeletePromptSnippetHandler(db))
+		}
+
+		// Slash commands (/summarize etc.) shared across clients
+		// Routing rules: auto-tag new chats, apply personas, or route to a
+		// model based on the first message
+		routingRules := v1.Group("/routing-rules", MaxBodySize(smallBodyLimit))
+		{
+			routingRules.GET("", ListRoutingRulesHandler(db))
+			routingRules.POST("", CreateRoutingRuleHandler(db))
+			routingRules.PUT("/:id", UpdateRoutingRuleHandler(db))
+			routingRules.DELETE("/:id", DeleteRoutingRuleHandler(db))
+		}
+
+		commands := v1.Group("/commands", MaxBodySize(smallBodyLimit))
+		{
+			commands.GET("", ListSlashCommandsHandler(db))
+			commands.POST("", CreateSlashCommandHandler(db))
+			commands.PUT("/:id", UpdateSlashCommandHandler(db))
+			commands.DELETE("/:id", DeleteSlashCommandHandler(db))
+			commands.POST("/expand", ExpandSlashCommandHandler(db))
+		}
+
+		// In-app notification center (system events shown in the UI)
+		notifications := v1.Group("/notifications", MaxBodySize(smallBodyLimit))
+		{
+			notifications.GET("", ListNotificationsHandler(db))
+			notifications.POST("/read-all", MarkAllNotificationsReadHandler(db))
+			notifications.POST("/:id/read", MarkNotificationReadHandler(db))
+		}
+
+		// Background job queue (status, cancel, retry, history)
+		jobs := v1.Group("/jobs", MaxBodySize(smallBodyLimit))
+		{
+			jobs.GET("", ListJobsHandler(db))
+			jobs.POST("", EnqueueJobHandler(db))
+			jobs.GET("/:id", GetJobHandler(db))
+			jobs.POST("/:id/cancel", CancelJobHandler(db))
+			jobs.POST("/:id/retry", RetryJobHandler(db))
+		}
+
+		// Outbound webhooks (HMAC-signed event notifications)
+		webhooks := v1.Group("/webhooks", MaxBodySize(smallBodyLimit))
+		{
+			webhooks.GET("", ListWebhooksHandler(db))
+			webhooks.POST("", CreateWebhookHandler(db))
+			webhooks.PUT("/:id", UpdateWebhookHandler(db))
+			webhooks.DELETE("/:id", DeleteWebhookHandler(db))
+			webhooks.GET("/:id/deliveries", ListWebhookDeliveriesHandler(db))
+		}
+
+		// External plugins (JSON-RPC processes adding tools and providers)
+		pluginRoutes := v1.Group("/plugins", MaxBodySize(smallBodyLimit))
+		{
+			pluginRoutes.GET("", ListPluginsHandler())
+			pluginRoutes.POST("/:name/restart", RestartPluginHandler())
+			pluginRoutes.POST("/:name/call", CallPluginHandler())
+		}
+
+		// Slack slash-command bridge (searches chats from Slack)
+		v1.POST("/integrations/slack/commands", MaxBodySize(smallBodyLimit), SlackCommandHandler(db))
+
 		// URL fetch proxy (for tools that need to fetch external URLs)
 		// Uses curl/wget when available, falls back to native Go HTTP client
-		v1.POST("/proxy/fetch", URLFetchProxyHandler())
+		v1.POST("/proxy/fetch", MaxBodySize(smallBodyLimit), URLFetchProxyHandler(db, ollamaService))
 		v1.GET("/proxy/fetch-method", GetFetchMethodHandler())
 
+		// Fetch method policy (global and per-domain method ordering)
+		loadFetchPolicy(db)
+		v1.GET("/proxy/fetch-policy", GetFetchPolicyHandler())
+		v1.PUT("/proxy/fetch-policy", MaxBodySize(smallBodyLimit), UpdateFetchPolicyHandler(db))
+
+		// Saved auth profiles for fetching authenticated pages
+		fetchProfiles := v1.Group("/fetch/profiles")
+		{
+			fetchProfiles.GET("", ListFetchProfilesHandler(db))
+			fetchProfiles.POST("", CreateFetchProfileHandler(db))
+			fetchProfiles.DELETE("/:id", DeleteFetchProfileHandler(db))
+		}
+
+		// Fetcher health metrics (per-method success rates, latencies, fallbacks)
+		v1.GET("/fetcher/status", FetcherStatusHandler())
+
 		// Web search proxy (for web_search tool)
-		v1.POST("/proxy/search", WebSearchProxyHandler())
+		v1.POST("/proxy/search", OfflineGuard(), MaxBodySize(smallBodyLimit), WebSearchProxyHandler(db))
+
+		// Search provider health (error rates, block detections, quota)
+		v1.GET("/proxy/search/status", SearchProvidersStatusHandler())
 
-		// IP-based geolocation (fallback when browser geolocation fails)
+		// Image search proxy (thumbnails, source pages, dimensions)
+		v1.POST("/proxy/search-images", OfflineGuard(), MaxBodySize(smallBodyLimit), ImageSearchProxyHandler())
+
+		// Combined search-fetch-summarize endpoint (web research mode)
+		v1.POST("/research", OfflineGuard(), MaxBodySize(smallBodyLimit), ResearchHandler(ollamaService))
+
+		// Structured text translation via a local model
+		v1.POST("/translate", MaxBodySize(smallBodyLimit), TranslateHandler(ollamaService))
+
+		// IP-based geolocation (fallback when browser geolocation fails).
+		// Works offline when a local GeoIP database is configured, so the
+		// handler decides for itself whether policy blocks it.
 		v1.GET("/location", IPGeolocationHandler())
 
+		// Reverse geocoding for browser-provided coordinates
+		v1.GET("/location/reverse", OfflineGuard(), ReverseGeocodeHandler())
+
+		// Weather (current conditions + forecast via Open-Meteo)
+		v1.GET("/weather", OfflineGuard(), WeatherHandler())
+
+		// Admin statistics (counts, DB size, live streams, top models)
+		v1.GET("/admin/stats", AdminStatsHandler(db, config.Get().Database.Path))
+
+		// Usage analytics rollups (per day/model/user) and CSV export
+		v1.GET("/admin/usage", UsageHandler(db))
+		v1.GET("/admin/usage/export", UsageExportHandler(db))
+
+		// Estimated spend per month/user for models with configured pricing
+		v1.GET("/admin/costs", CostsHandler(db))
+
+		// Database size/row-count breakdown for operators
+		v1.GET("/admin/db-stats", DBStatsHandler(db, config.Get().Database.Path))
+
+		// Guardrail review: recent blocks, flags, and redactions
+		v1.GET("/admin/guardrails/events", ListGuardrailEventsHandler(db))
+
+		// Near-duplicate chat detection with merge/delete suggestions
+		if ollamaService != nil {
+			v1.GET("/admin/duplicates", ollamaService.DuplicateChatsHandler(db))
+		}
+
+		// Addresses that have reached the API (access anomaly tracking)
+		v1.GET("/admin/access-origins", ListAccessOriginsHandler(db))
+
+		// Provider API key vault: secrets encrypted at rest, never echoed
+		// back, with an audit of where each key is used
+		v1.GET("/admin/keys", ListAPIKeysHandler(db))
+		v1.PUT("/admin/keys/:provider", MaxBodySize(smallBodyLimit), SetAPIKeyHandler(db))
+		v1.DELETE("/admin/keys/:provider", DeleteAPIKeyHandler(db))
+
+		// Cold storage: move old chats into compressed archive files on
+		// disk, search the index, and restore them back into the live DB
+		v1.POST("/admin/archive", MaxBodySize(smallBodyLimit), ArchiveChatsHandler(db))
+		v1.GET("/admin/archive", ListArchivedChatsHandler(db))
+		v1.POST("/admin/archive/:id/restore", RestoreArchivedChatHandler(db))
+
+		// Detected hardware (CPU, RAM, GPU/VRAM, free disk)
+		v1.GET("/system/hardware", HardwareHandler())
+
+		// Ollama environment checklist with actionable messages
+		if ollamaService != nil {
+			v1.GET("/diagnostics", ollamaService.DiagnosticsHandler())
+		}
+
+		// Tool-call audit trail (injection detections on fetched content)
+		v1.GET("/admin/tool-audit", ListToolAuditHandler(db))
+
+		// Online backup (snapshot download) and staged restore
+		v1.POST("/admin/backup", BackupHandler(db))
+		v1.POST("/admin/restore", MaxBodySize(restoreBodyLimit), RestoreHandler(db, config.Get().Database.Path))
+
+		// Portable data export/import (zip of chats, messages, attachments)
+		v1.GET("/admin/export", ExportHandler(db))
+		v1.GET("/admin/export/finetune", FinetuneExportHandler(db))
+		v1.POST("/admin/import", MaxBodySize(restoreBodyLimit), ImportHandler(db))
+
+		// Speech-to-text (external Whisper server or local whisper.cpp)
+		v1.POST("/audio/transcriptions", MaxBodySize(attachmentBodyLimit), TranscriptionHandler())
+
 		// Tool execution (for Python tools)
-		v1.POST("/tools/execute", ExecuteToolHandler())
+		v1.POST("/tools/execute", MaxBodySize(smallBodyLimit), ExecuteToolHandler())
 
 		// Model registry routes (cached models from ollama.com)
 		models := v1.Group("/models")
@@ -90,11 +407,35 @@ func SetupRoutes(r *gin.Engine, db *sql.DB, ollamaURL string, appVersion string)
 			// Fetch detailed info from Ollama (requires model to be pulled)
 			models.POST("/remote/:slug/details", modelRegistry.FetchModelDetailsHandler())
 			// Fetch tag sizes from ollama.com (scrapes model detail page)
-			models.POST("/remote/:slug/sizes", modelRegistry.FetchTagSizesHandler())
+			models.POST("/remote/:slug/sizes", OfflineGuard(), modelRegistry.FetchTagSizesHandler())
 			// Sync models from ollama.com
-			models.POST("/remote/sync", modelRegistry.SyncModelsHandler())
+			models.POST("/remote/sync", OfflineGuard(), modelRegistry.SyncModelsHandler())
 			// Get sync status
 			models.GET("/remote/status", modelRegistry.SyncStatusHandler())
+
+			// Drift between the config provisioning list and what Ollama
+			// has, plus an on-demand trigger to pull what's missing
+			if ollamaService != nil {
+				models.GET("/provisioning", ollamaService.ModelProvisioningHandler())
+				models.POST("/provisioning/apply", MaxBodySize(smallBodyLimit), ollamaService.ApplyProvisioningHandler(db))
+			}
+
+			// Blind model arena: run a match, vote, see the leaderboard
+			if ollamaService != nil {
+				models.POST("/arena/matches", MaxBodySize(smallBodyLimit), ollamaService.ArenaMatchHandler(db))
+			}
+			models.POST("/arena/matches/:id/vote", MaxBodySize(smallBodyLimit), ArenaVoteHandler(db))
+			models.GET("/arena/leaderboard", ArenaLeaderboardHandler(db))
+
+			// Benchmark a local model with a standardized prompt set and
+			// list stored runs for comparison
+			models.POST("/:name/benchmark", modelRegistry.BenchmarkModelHandler())
+			models.GET("/:name/benchmarks", modelRegistry.ListBenchmarksHandler())
+
+			// Pre-load a model into memory to skip the cold-start pause
+			if ollamaService != nil {
+				models.POST("/:name/load", MaxBodySize(smallBodyLimit), ollamaService.LoadModelHandler())
+			}
 		}
 
 		// Ollama API routes (using official client)
@@ -110,8 +451,8 @@ func SetupRoutes(r *gin.Engine, db *sql.DB, ollamaURL string, appVersion string)
 				ollama.POST("/api/copy", ollamaService.CopyModelHandler())
 
 				// Chat and generation
-				ollama.POST("/api/chat", ollamaService.ChatHandler())
-				ollama.POST("/api/generate", ollamaService.GenerateHandler())
+				ollama.POST("/api/chat", ollamaService.ChatHandler(db))
+				ollama.POST("/api/generate", ollamaService.GenerateHandler(db))
 
 				// Embeddings
 				ollama.POST("/api/embed", ollamaService.EmbedHandler())
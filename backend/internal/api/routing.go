@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// routingSemanticDefaultThreshold applies when a semantic rule doesn't
+// set its own similarity cutoff
+const routingSemanticDefaultThreshold = 0.8
+
+// routingEmbedTimeout bounds the embedding call per semantic rule so a
+// slow classifier can't stall the chat noticeably
+const routingEmbedTimeout = 10 * time.Second
+
+// routingRuleRequest is the create/update payload for a routing rule
+type routingRuleRequest struct {
+	Name       string  `json:"name" binding:"required"`
+	MatchType  string  `json:"match_type" binding:"required,oneof=regex semantic"`
+	Pattern    string  `json:"pattern" binding:"required"`
+	Threshold  float64 `json:"threshold"`
+	EmbedModel string  `json:"embed_model"`
+	Tag        string  `json:"tag"`
+	PersonaID  string  `json:"persona_id"`
+	Model      string  `json:"model"`
+	Priority   int     `json:"priority"`
+	Enabled    *bool   `json:"enabled"`
+}
+
+// validateRoutingRule checks what the database schema can't express
+func validateRoutingRule(req *routingRuleRequest) error {
+	if req.MatchType == "regex" {
+		if _, err := regexp.Compile(req.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+	}
+	if req.MatchType == "semantic" && req.EmbedModel == "" {
+		return fmt.Errorf("semantic rules require embed_model")
+	}
+	if req.Tag == "" && req.PersonaID == "" && req.Model == "" {
+		return fmt.Errorf("rule needs at least one action (tag, persona_id, or model)")
+	}
+	return nil
+}
+
+// routingRuleFromRequest builds a RoutingRule from the request payload
+func routingRuleFromRequest(req *routingRuleRequest) *models.RoutingRule {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &models.RoutingRule{
+		Name:       strings.TrimSpace(req.Name),
+		MatchType:  req.MatchType,
+		Pattern:    req.Pattern,
+		Threshold:  req.Threshold,
+		EmbedModel: req.EmbedModel,
+		Tag:        req.Tag,
+		PersonaID:  req.PersonaID,
+		Model:      req.Model,
+		Priority:   req.Priority,
+		Enabled:    enabled,
+	}
+}
+
+// ListRoutingRulesHandler lists all routing rules
+func ListRoutingRulesHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rules, err := models.ListRoutingRules(db, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if rules == nil {
+			rules = []models.RoutingRule{}
+		}
+		c.JSON(http.StatusOK, gin.H{"rules": rules})
+	}
+}
+
+// CreateRoutingRuleHandler creates a new routing rule
+func CreateRoutingRuleHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req routingRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		if err := validateRoutingRule(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rule := routingRuleFromRequest(&req)
+		if err := models.CreateRoutingRule(db, rule); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, rule)
+	}
+}
+
+// UpdateRoutingRuleHandler updates an existing routing rule
+func UpdateRoutingRuleHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req routingRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		if err := validateRoutingRule(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rule := routingRuleFromRequest(&req)
+		rule.ID = c.Param("id")
+		if err := models.UpdateRoutingRule(db, rule); err != nil {
+			if err.Error() == "routing rule not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, rule)
+	}
+}
+
+// DeleteRoutingRuleHandler deletes a routing rule
+func DeleteRoutingRuleHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.DeleteRoutingRule(db, c.Param("id")); err != nil {
+			if err.Error() == "routing rule not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "routing rule deleted"})
+	}
+}
+
+// GetChatTagsHandler returns the chat's tags
+func GetChatTagsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tags, err := models.GetChatTags(db, c.Param("id"))
+		if err != nil {
+			if err.Error() == "chat not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"tags": tags})
+	}
+}
+
+// applyRoutingRules classifies the opening message of a new conversation
+// and applies the first matching rule's actions: tagging the chat,
+// applying a persona, and/or routing to a different model. Returns the
+// matched rule name, or empty when nothing applied.
+func (s *OllamaService) applyRoutingRules(ctx context.Context, db *sql.DB, chatID string, req *ollamaapi.ChatRequest) string {
+	first := openingUserMessage(req.Messages)
+	if first == "" {
+		return ""
+	}
+
+	rules, err := models.ListRoutingRules(db, true)
+	if err != nil || len(rules) == 0 {
+		return ""
+	}
+
+	logger := logging.Module("routing")
+	for i := range rules {
+		rule := &rules[i]
+		matched := false
+		switch rule.MatchType {
+		case "regex":
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				logger.Warn("Skipping routing rule with invalid pattern", "rule", rule.Name, "error", err)
+				continue
+			}
+			matched = re.MatchString(first)
+		case "semantic":
+			matched = s.semanticRuleMatches(ctx, rule, first)
+		}
+		if !matched {
+			continue
+		}
+
+		if rule.Tag != "" && chatID != "" {
+			if err := models.AddChatTag(db, chatID, rule.Tag); err != nil {
+				logger.Warn("Failed to tag chat", "chat", chatID, "rule", rule.Name, "error", err)
+			}
+		}
+		if rule.PersonaID != "" {
+			if err := applyPersona(db, req, rule.PersonaID); err != nil {
+				logger.Warn("Failed to apply routed persona", "rule", rule.Name, "error", err)
+			}
+		}
+		if rule.Model != "" {
+			req.Model = rule.Model
+		}
+		return rule.Name
+	}
+	return ""
+}
+
+// openingUserMessage returns the first user message when the
+// conversation is brand new (exactly one user turn, no assistant
+// replies yet) - routing only classifies chat openings
+func openingUserMessage(messages []ollamaapi.Message) string {
+	first := ""
+	userTurns := 0
+	for _, msg := range messages {
+		switch msg.Role {
+		case "assistant":
+			return ""
+		case "user":
+			userTurns++
+			if first == "" {
+				first = msg.Content
+			}
+		}
+	}
+	if userTurns != 1 {
+		return ""
+	}
+	return first
+}
+
+// semanticRuleMatches embeds the rule's example text alongside the
+// message and compares cosine similarity; errors fail closed
+func (s *OllamaService) semanticRuleMatches(ctx context.Context, rule *models.RoutingRule, message string) bool {
+	threshold := rule.Threshold
+	if threshold <= 0 {
+		threshold = routingSemanticDefaultThreshold
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, routingEmbedTimeout)
+	defer cancel()
+
+	resp, err := s.client.Embed(ctx, &ollamaapi.EmbedRequest{
+		Model: rule.EmbedModel,
+		Input: []string{rule.Pattern, message},
+	})
+	if err != nil || len(resp.Embeddings) != 2 {
+		logging.Module("routing").Warn("Semantic rule embedding failed", "rule", rule.Name, "error", err)
+		return false
+	}
+	return cosineSimilarity(resp.Embeddings[0], resp.Embeddings[1]) >= threshold
+}
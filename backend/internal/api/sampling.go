@@ -0,0 +1,161 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/models"
+)
+
+// Option kinds for validation
+const (
+	optInt     = "int"
+	optFloat   = "float"
+	optBool    = "bool"
+	optStrings = "strings"
+)
+
+// validOllamaOptions lists the generation options Ollama accepts and the
+// value kind each expects; anything else is rejected rather than silently
+// ignored by the runner
+var validOllamaOptions = map[string]string{
+	"num_ctx":           optInt,
+	"num_predict":       optInt,
+	"num_keep":          optInt,
+	"seed":              optInt,
+	"top_k":             optInt,
+	"mirostat":          optInt,
+	"repeat_last_n":     optInt,
+	"num_batch":         optInt,
+	"num_gpu":           optInt,
+	"num_thread":        optInt,
+	"temperature":       optFloat,
+	"top_p":             optFloat,
+	"min_p":             optFloat,
+	"typical_p":         optFloat,
+	"tfs_z":             optFloat,
+	"repeat_penalty":    optFloat,
+	"presence_penalty":  optFloat,
+	"frequency_penalty": optFloat,
+	"mirostat_tau":      optFloat,
+	"mirostat_eta":      optFloat,
+	"penalize_newline":  optBool,
+	"numa":              optBool,
+	"low_vram":          optBool,
+	"use_mmap":          optBool,
+	"use_mlock":         optBool,
+	"stop":              optStrings,
+}
+
+// validateSamplingOptions checks option names and value types against
+// what Ollama accepts
+func validateSamplingOptions(options map[string]interface{}) error {
+	for key, value := range options {
+		kind, ok := validOllamaOptions[key]
+		if !ok {
+			return fmt.Errorf("unknown option %q", key)
+		}
+
+		switch kind {
+		case optInt:
+			// JSON numbers arrive as float64; require an integral value
+			n, ok := value.(float64)
+			if !ok || n != float64(int64(n)) {
+				return fmt.Errorf("option %q must be an integer", key)
+			}
+		case optFloat:
+			if _, ok := value.(float64); !ok {
+				return fmt.Errorf("option %q must be a number", key)
+			}
+		case optBool:
+			if _, ok := value.(bool); !ok {
+				return fmt.Errorf("option %q must be a boolean", key)
+			}
+		case optStrings:
+			list, ok := value.([]interface{})
+			if !ok {
+				return fmt.Errorf("option %q must be an array of strings", key)
+			}
+			for _, item := range list {
+				if _, ok := item.(string); !ok {
+					return fmt.Errorf("option %q must be an array of strings", key)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// applyChatSamplingOptions layers the chat's stored options onto a
+// request for any option the request leaves unset - explicit request
+// values always win, mirroring applyPersona
+func applyChatSamplingOptions(db *sql.DB, chatID string, req *ollamaapi.ChatRequest) {
+	if chatID == "" {
+		return
+	}
+	options, err := models.GetChatSamplingOptions(db, chatID)
+	if err != nil || len(options) == 0 {
+		return
+	}
+
+	if req.Options == nil {
+		req.Options = map[string]interface{}{}
+	}
+	for key, value := range options {
+		if _, set := req.Options[key]; !set {
+			req.Options[key] = value
+		}
+	}
+}
+
+// SetChatOptionsRequest carries a chat's advanced sampling options
+type SetChatOptionsRequest struct {
+	Options map[string]interface{} `json:"options"`
+}
+
+// GetChatOptionsHandler returns the chat's stored sampling options
+func GetChatOptionsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		options, err := models.GetChatSamplingOptions(db, c.Param("id"))
+		if err != nil {
+			if err.Error() == "chat not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"options": options})
+	}
+}
+
+// SetChatOptionsHandler validates and stores advanced sampling options
+// (stop sequences, seed, mirostat, repeat_penalty...) applied to every
+// completion in this chat
+func SetChatOptionsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SetChatOptionsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		if err := validateSamplingOptions(req.Options); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := models.SetChatSamplingOptions(db, c.Param("id"), req.Options); err != nil {
+			if err.Error() == "chat not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"options": req.Options})
+	}
+}
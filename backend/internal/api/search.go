@@ -1,13 +1,17 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	mathrand "math/rand"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,10 +19,26 @@ import (
 type SearchRequest struct {
 	Query      string `json:"query" binding:"required"`
 	MaxResults int    `json:"maxResults"`
-	Site       string `json:"site"`      // Limit to specific site (e.g., "reddit.com")
-	Freshness  string `json:"freshness"` // Date filter: "day", "week", "month", "year"
-	Region     string `json:"region"`    // Region code (e.g., "us-en", "de-de", "uk-en")
-	Timeout    int    `json:"timeout"`   // Timeout in seconds
+	Provider   string `json:"provider"`   // "web" (default) or "local" (user's own data)
+	Site       string `json:"site"`       // Limit to specific site (e.g., "reddit.com")
+	Mode       string `json:"mode"`       // Search vertical: "web" (default) or "news"
+	Freshness  string `json:"freshness"`  // Date filter: "day", "week", "month", "year"
+	Region     string `json:"region"`     // Region code (e.g., "us-en", "de-de", "uk-en")
+	Language   string `json:"language"`   // Preferred result language (e.g., "en", "de-DE")
+	SafeSearch string `json:"safeSearch"` // "strict", "moderate" (default), or "off"
+	Timeout    int    `json:"timeout"`    // Timeout in seconds
+}
+
+// searchParams bundles everything needed to run one provider search
+type searchParams struct {
+	query      string
+	maxResults int
+	mode       string // "web" or "news"
+	freshness  string
+	region     string
+	language   string
+	safeSearch string
+	timeout    time.Duration
 }
 
 // SearchResult represents a single search result
@@ -30,7 +50,7 @@ type SearchResult struct {
 
 // WebSearchProxyHandler returns a handler that performs web searches via DuckDuckGo
 // Uses curl/wget when available for better compatibility
-func WebSearchProxyHandler() gin.HandlerFunc {
+func WebSearchProxyHandler(db *sql.DB) gin.HandlerFunc {
 	fetcher := GetFetcher()
 
 	return func(c *gin.Context) {
@@ -49,76 +69,276 @@ func WebSearchProxyHandler() gin.HandlerFunc {
 			maxResults = 10
 		}
 
-		// Build query with site filter if provided
-		query := req.Query
+		// The "local" provider searches the user's own data instead of the web
+		if req.Provider == "local" {
+			results, err := searchLocal(db, req.Query, maxResults)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if results == nil {
+				results = []SearchResult{}
+			}
+			c.JSON(http.StatusOK, gin.H{
+				"query":       req.Query,
+				"results":     results,
+				"count":       len(results),
+				"fetchMethod": "local",
+			})
+			return
+		}
+		if req.Provider != "" && req.Provider != "web" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "provider must be 'web' or 'local'"})
+			return
+		}
+
+		// Build query with site filter if provided; the query leaves the
+		// machine, so scrub any PII first
+		query := scrubOutboundText(req.Query, "web-search")
 		if req.Site != "" {
 			query = fmt.Sprintf("site:%s %s", req.Site, query)
 		}
 
-		// Build DuckDuckGo HTML search URL with parameters
-		searchURL := fmt.Sprintf("https://html.duckduckgo.com/html/?q=%s", url.QueryEscape(query))
+		// Validate search mode
+		mode := req.Mode
+		if mode == "" {
+			mode = "web"
+		}
+		if mode != "web" && mode != "news" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be 'web' or 'news'"})
+			return
+		}
 
-		// Add region parameter if provided (e.g., "us-en", "de-de", "uk-en")
-		if req.Region != "" {
-			searchURL += "&kl=" + url.QueryEscape(req.Region)
+		// News queries default to recent results - stale news is rarely useful
+		freshness := req.Freshness
+		if mode == "news" && freshness == "" {
+			freshness = "week"
 		}
 
-		// Add date filter if provided
-		if req.Freshness != "" {
-			var df string
-			switch req.Freshness {
-			case "day", "d":
-				df = "d"
-			case "week", "w":
-				df = "w"
-			case "month", "m":
-				df = "m"
-			case "year", "y":
-				df = "y"
-			}
-			if df != "" {
-				searchURL += "&df=" + df
-			}
+		// Validate safe-search level
+		safeSearch := req.SafeSearch
+		if safeSearch == "" {
+			safeSearch = "moderate"
+		}
+		if safeSearch != "strict" && safeSearch != "moderate" && safeSearch != "off" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "safeSearch must be 'strict', 'moderate', or 'off'"})
+			return
 		}
 
-		// Set up fetch options with browser-like headers
-		opts := DefaultFetchOptions()
-		opts.MaxLength = 500000 // 500KB is plenty for search results
+		// Serve from cache when an identical query was made recently
+		cacheKey := fmt.Sprintf("%s|%d|%s|%s|%s|%s|%s", query, maxResults, mode, freshness, req.Region, req.Language, safeSearch)
+		if cached := globalSearchCache.get(cacheKey); cached != nil {
+			c.JSON(http.StatusOK, gin.H{
+				"query":       req.Query,
+				"results":     cached,
+				"count":       len(cached),
+				"fetchMethod": "cache",
+			})
+			return
+		}
 
 		// Set timeout (default 20s, max 60s)
+		timeout := 20 * time.Second
 		if req.Timeout > 0 && req.Timeout <= 60 {
-			opts.Timeout = time.Duration(req.Timeout) * time.Second
-		} else {
-			opts.Timeout = 20 * time.Second
+			timeout = time.Duration(req.Timeout) * time.Second
 		}
 
-		// Fetch search results
-		result, err := fetcher.Fetch(c.Request.Context(), searchURL, opts)
+		results, method, err := searchDuckDuckGoParams(c.Request.Context(), fetcher, searchParams{
+			query:      query,
+			maxResults: maxResults,
+			mode:       mode,
+			freshness:  freshness,
+			region:     req.Region,
+			language:   req.Language,
+			safeSearch: safeSearch,
+			timeout:    timeout,
+		})
 		if err != nil {
-			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to perform search: " + err.Error()})
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Check status
-		if result.StatusCode >= 400 {
-			c.JSON(http.StatusBadGateway, gin.H{"error": "search failed: HTTP " + http.StatusText(result.StatusCode)})
-			return
+		if len(results) > 0 {
+			globalSearchCache.set(cacheKey, results)
 		}
 
-		// Parse results from HTML
-		results := parseDuckDuckGoResults(result.Content, maxResults)
-
 		c.JSON(http.StatusOK, gin.H{
 			"query":       req.Query,
+			"mode":        mode,
 			"results":     results,
 			"count":       len(results),
-			"fetchMethod": string(result.Method),
+			"fetchMethod": method,
 		})
 	}
 }
 
-// parseDuckDuckGoResults extracts search results from DuckDuckGo HTML
+// searchDuckDuckGo performs a DuckDuckGo web search and returns parsed,
+// deduplicated results along with the fetch method used
+func searchDuckDuckGo(ctx context.Context, fetcher *Fetcher, query string, maxResults int, freshness, region string, timeout time.Duration) ([]SearchResult, string, error) {
+	return searchDuckDuckGoParams(ctx, fetcher, searchParams{
+		query:      query,
+		maxResults: maxResults,
+		mode:       "web",
+		freshness:  freshness,
+		region:     region,
+		timeout:    timeout,
+	})
+}
+
+// searchDuckDuckGoParams performs a DuckDuckGo HTML search with the given
+// parameters and returns parsed, deduplicated results
+func searchDuckDuckGoParams(ctx context.Context, fetcher *Fetcher, params searchParams) ([]SearchResult, string, error) {
+	// Build DuckDuckGo HTML search URL with parameters
+	searchURL := fmt.Sprintf("https://html.duckduckgo.com/html/?q=%s", url.QueryEscape(params.query))
+
+	// The HTML endpoint has no dedicated news vertical, so map news mode onto
+	// its news-tab parameters - DuckDuckGo then prioritizes news sources
+	if params.mode == "news" {
+		searchURL += "&ia=news&iar=news"
+	}
+
+	// Add region parameter if provided (e.g., "us-en", "de-de", "uk-en")
+	if params.region != "" {
+		searchURL += "&kl=" + url.QueryEscape(params.region)
+	}
+
+	// Map safe-search level onto DuckDuckGo's kp parameter
+	switch params.safeSearch {
+	case "strict":
+		searchURL += "&kp=1"
+	case "off":
+		searchURL += "&kp=-2"
+	}
+
+	// Add date filter if provided
+	if params.freshness != "" {
+		var df string
+		switch params.freshness {
+		case "day", "d":
+			df = "d"
+		case "week", "w":
+			df = "w"
+		case "month", "m":
+			df = "m"
+		case "year", "y":
+			df = "y"
+		}
+		if df != "" {
+			searchURL += "&df=" + df
+		}
+	}
+
+	// Set up fetch options with browser-like headers
+	opts := DefaultFetchOptions()
+	opts.MaxLength = 500000 // 500KB is plenty for search results
+	opts.Timeout = params.timeout
+
+	// Prefer results in the requested language rather than hard-coded en-US
+	if params.language != "" {
+		opts.Headers["Accept-Language"] = params.language + ";q=0.9,en;q=0.5"
+	}
+
+	result, err := fetcher.Fetch(ctx, searchURL, opts)
+	if err != nil {
+		err = fmt.Errorf("failed to perform search: %w", err)
+		globalSearchHealth.recordSearch(searchProviderDuckDuckGo, false, err)
+		return nil, "", err
+	}
+
+	// DuckDuckGo sometimes blocks automated clients (403/429 or an anomaly
+	// page). Retry once with the full method fallback chain after a short
+	// pause - a different client fingerprint often gets through.
+	if isDuckDuckGoBlocked(result) {
+		globalSearchHealth.recordBlock(searchProviderDuckDuckGo)
+
+		select {
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		case <-time.After(time.Duration(1500+mathrand.Intn(1500)) * time.Millisecond):
+		}
+
+		retried, retryErr := fetcher.TryFetchWithFallback(ctx, searchURL, opts)
+		if retryErr == nil && !isDuckDuckGoBlocked(retried) {
+			result = retried
+		}
+	}
+
+	if isDuckDuckGoBlocked(result) {
+		err := fmt.Errorf("search blocked by provider - try again in a few minutes")
+		globalSearchHealth.recordSearch(searchProviderDuckDuckGo, true, err)
+		return nil, "", err
+	}
+	if result.StatusCode >= 400 {
+		err := fmt.Errorf("search failed: HTTP %s", http.StatusText(result.StatusCode))
+		globalSearchHealth.recordSearch(searchProviderDuckDuckGo, false, err)
+		return nil, "", err
+	}
+
+	results := parseDuckDuckGoResults(result.Content, params.maxResults)
+	results = dedupeSearchResults(results)
+	globalSearchHealth.recordSearch(searchProviderDuckDuckGo, false, nil)
+
+	return results, string(result.Method), nil
+}
+
+// isDuckDuckGoBlocked detects DuckDuckGo's bot-block responses: rate-limit
+// status codes or the anomaly/captcha interstitial page
+func isDuckDuckGoBlocked(result *FetchResult) bool {
+	if result.StatusCode == http.StatusForbidden || result.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	contentLower := strings.ToLower(result.Content)
+	return strings.Contains(contentLower, "anomaly-modal") ||
+		strings.Contains(contentLower, "unfortunately, bots use duckduckgo too")
+}
+
+// parseDuckDuckGoResults extracts search results from DuckDuckGo HTML.
+// It uses a real HTML parser first; the regex-based extraction remains as a
+// fallback for markup the parser cannot handle.
 func parseDuckDuckGoResults(html string, maxResults int) []SearchResult {
+	if results := parseDuckDuckGoGoquery(html, maxResults); len(results) > 0 {
+		return results
+	}
+	return parseDuckDuckGoRegex(html, maxResults)
+}
+
+// parseDuckDuckGoGoquery extracts results using goquery CSS selectors,
+// which tolerate attribute reordering and nesting changes that break regexes
+func parseDuckDuckGoGoquery(html string, maxResults int) []SearchResult {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil
+	}
+
+	var results []SearchResult
+	doc.Find("div.results_links, div.result").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if len(results) >= maxResults {
+			return false
+		}
+
+		link := s.Find("a.result__a").First()
+		href, _ := link.Attr("href")
+		resultURL := decodeURL(href)
+		title := strings.TrimSpace(link.Text())
+		snippet := strings.TrimSpace(s.Find(".result__snippet").First().Text())
+
+		if title == "" || resultURL == "" || strings.Contains(resultURL, "duckduckgo.com") {
+			return true
+		}
+
+		results = append(results, SearchResult{
+			Title:   title,
+			URL:     resultURL,
+			Snippet: snippet,
+		})
+		return true
+	})
+
+	return results
+}
+
+// parseDuckDuckGoRegex extracts search results with regular expressions
+func parseDuckDuckGoRegex(html string, maxResults int) []SearchResult {
 	var results []SearchResult
 
 	// DuckDuckGo HTML result structure:
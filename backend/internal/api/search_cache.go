@@ -0,0 +1,122 @@
+package api
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"vessel-backend/internal/config"
+)
+
+// searchCacheEntry holds cached results for one query
+type searchCacheEntry struct {
+	results   []SearchResult
+	expiresAt time.Time
+}
+
+// searchCache is an in-memory TTL cache for search results, so repeated
+// agent searches within one conversation don't hammer the provider
+type searchCache struct {
+	mu      sync.Mutex
+	entries map[string]searchCacheEntry
+	ttlOnce sync.Once
+	ttl     time.Duration
+}
+
+var globalSearchCache = &searchCache{
+	entries: make(map[string]searchCacheEntry),
+}
+
+// cacheTTL resolves the TTL lazily so config is loaded before first use.
+// Configured via search.cache_ttl_seconds / SEARCH_CACHE_TTL; 0 disables caching.
+func (sc *searchCache) cacheTTL() time.Duration {
+	sc.ttlOnce.Do(func() {
+		sc.ttl = time.Duration(config.Get().Search.CacheTTLSeconds) * time.Second
+	})
+	return sc.ttl
+}
+
+// get returns cached results for a key, or nil on miss/expiry
+func (sc *searchCache) get(key string) []SearchResult {
+	if sc.cacheTTL() == 0 {
+		return nil
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	entry, ok := sc.entries[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(sc.entries, key)
+		return nil
+	}
+	return entry.results
+}
+
+// set stores results for a key and opportunistically evicts expired entries
+func (sc *searchCache) set(key string, results []SearchResult) {
+	if sc.cacheTTL() == 0 {
+		return
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range sc.entries {
+		if now.After(entry.expiresAt) {
+			delete(sc.entries, k)
+		}
+	}
+
+	sc.entries[key] = searchCacheEntry{
+		results:   results,
+		expiresAt: now.Add(sc.ttl),
+	}
+}
+
+// normalizeResultURL canonicalizes a URL for dedup purposes: lowercase
+// scheme/host, tracking parameters and fragments stripped, trailing slash removed
+func normalizeResultURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return strings.TrimSuffix(strings.ToLower(rawURL), "/")
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(strings.TrimPrefix(parsed.Host, "www."))
+	parsed.Fragment = ""
+
+	// Strip common tracking parameters that make identical pages look distinct
+	query := parsed.Query()
+	for param := range query {
+		lower := strings.ToLower(param)
+		if strings.HasPrefix(lower, "utm_") || lower == "ref" || lower == "fbclid" || lower == "gclid" {
+			query.Del(param)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+	return parsed.String()
+}
+
+// dedupeSearchResults removes results whose normalized URLs collide,
+// keeping the first (highest-ranked) occurrence
+func dedupeSearchResults(results []SearchResult) []SearchResult {
+	seen := make(map[string]bool, len(results))
+	deduped := results[:0]
+	for _, result := range results {
+		key := normalizeResultURL(result.URL)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, result)
+	}
+	return deduped
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// searchBlockCooldown is how long a captcha/bot-block detection keeps a
+// provider marked unhealthy
+const searchBlockCooldown = 10 * time.Minute
+
+// Provider names as recorded in the health stats
+const (
+	searchProviderDuckDuckGo       = "duckduckgo"
+	searchProviderDuckDuckGoImages = "duckduckgo-images"
+)
+
+// searchProviderStats tracks search outcomes for one provider
+type searchProviderStats struct {
+	Searches     int64
+	Errors       int64
+	Blocks       int64 // captcha/bot-block detections, including ones a retry got past
+	LastErrorMsg string
+	LastErrorAt  time.Time
+	LastBlockAt  time.Time
+
+	// Quota as last reported by the provider's API (rate-limit headers);
+	// scraped providers never report one
+	QuotaRemaining int64
+	QuotaKnown     bool
+	QuotaResetAt   time.Time
+}
+
+// searchHealth aggregates per-provider search statistics so the research
+// tooling can rotate away from a blocked or failing provider
+type searchHealth struct {
+	mu        sync.Mutex
+	providers map[string]*searchProviderStats
+	startedAt time.Time
+}
+
+var globalSearchHealth = newSearchHealth()
+
+func newSearchHealth() *searchHealth {
+	return &searchHealth{
+		providers: make(map[string]*searchProviderStats),
+		startedAt: time.Now(),
+	}
+}
+
+func (h *searchHealth) stats(provider string) *searchProviderStats {
+	stats, ok := h.providers[provider]
+	if !ok {
+		stats = &searchProviderStats{}
+		h.providers[provider] = stats
+	}
+	return stats
+}
+
+// recordSearch registers the outcome of one search; blocked marks a
+// captcha/bot-block response (which also counts as an error)
+func (h *searchHealth) recordSearch(provider string, blocked bool, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := h.stats(provider)
+	stats.Searches++
+	if blocked {
+		stats.Blocks++
+		stats.LastBlockAt = time.Now()
+	}
+	if err != nil {
+		stats.Errors++
+		stats.LastErrorMsg = err.Error()
+		stats.LastErrorAt = time.Now()
+	}
+}
+
+// recordBlock registers a transient block detection - one a retry got
+// past - without counting an extra search
+func (h *searchHealth) recordBlock(provider string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := h.stats(provider)
+	stats.Blocks++
+	stats.LastBlockAt = time.Now()
+}
+
+// recordQuota stores the remaining quota a provider's API reported (e.g.
+// X-RateLimit headers). The scraped DuckDuckGo providers expose none;
+// API-backed providers should call this after every response.
+func (h *searchHealth) recordQuota(provider string, remaining int64, resetAt time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := h.stats(provider)
+	stats.QuotaRemaining = remaining
+	stats.QuotaKnown = true
+	stats.QuotaResetAt = resetAt
+}
+
+// SearchProviderStatus is the per-provider view returned by the status endpoint
+type SearchProviderStatus struct {
+	Provider       string  `json:"provider"`
+	Searches       int64   `json:"searches"`
+	Errors         int64   `json:"errors"`
+	Blocks         int64   `json:"blocks"`
+	ErrorRate      float64 `json:"errorRate"`
+	Healthy        bool    `json:"healthy"`
+	LastError      string  `json:"lastError,omitempty"`
+	LastErrorAt    string  `json:"lastErrorAt,omitempty"`
+	LastBlockAt    string  `json:"lastBlockAt,omitempty"`
+	QuotaRemaining *int64  `json:"quotaRemaining,omitempty"`
+	QuotaResetAt   string  `json:"quotaResetAt,omitempty"`
+}
+
+// snapshot returns a consistent copy of the current per-provider stats
+func (h *searchHealth) snapshot() []SearchProviderStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	statuses := []SearchProviderStatus{}
+	for provider, stats := range h.providers {
+		status := SearchProviderStatus{
+			Provider: provider,
+			Searches: stats.Searches,
+			Errors:   stats.Errors,
+			Blocks:   stats.Blocks,
+		}
+		if stats.Searches > 0 {
+			status.ErrorRate = float64(stats.Errors) / float64(stats.Searches)
+		}
+
+		// Healthy means: no block inside the cooldown, quota (when known)
+		// not exhausted, and recent traffic not mostly failing
+		status.Healthy = true
+		if !stats.LastBlockAt.IsZero() && now.Sub(stats.LastBlockAt) < searchBlockCooldown {
+			status.Healthy = false
+		}
+		if stats.QuotaKnown && stats.QuotaRemaining <= 0 && (stats.QuotaResetAt.IsZero() || now.Before(stats.QuotaResetAt)) {
+			status.Healthy = false
+		}
+		if stats.Searches >= 4 && status.ErrorRate >= 0.5 {
+			status.Healthy = false
+		}
+
+		if stats.LastErrorMsg != "" {
+			status.LastError = stats.LastErrorMsg
+			status.LastErrorAt = stats.LastErrorAt.UTC().Format(time.RFC3339)
+		}
+		if !stats.LastBlockAt.IsZero() {
+			status.LastBlockAt = stats.LastBlockAt.UTC().Format(time.RFC3339)
+		}
+		if stats.QuotaKnown {
+			remaining := stats.QuotaRemaining
+			status.QuotaRemaining = &remaining
+			if !stats.QuotaResetAt.IsZero() {
+				status.QuotaResetAt = stats.QuotaResetAt.UTC().Format(time.RFC3339)
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Provider < statuses[j].Provider
+	})
+	return statuses
+}
+
+// SearchProvidersStatusHandler reports per-provider search health -
+// error rates, captcha/block detections, and remaining quota where the
+// provider's API exposes it - plus a recommendation, so the research
+// tooling can rotate to a healthy provider automatically.
+func SearchProvidersStatusHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		statuses := globalSearchHealth.snapshot()
+
+		// Recommend the first healthy provider (the snapshot is sorted);
+		// empty means none is currently healthy
+		recommended := ""
+		for _, status := range statuses {
+			if status.Healthy {
+				recommended = status.Provider
+				break
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"providers":   statuses,
+			"recommended": recommended,
+			"since":       globalSearchHealth.startedAt.UTC().Format(time.RFC3339),
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImageSearchRequest represents an image search request
+type ImageSearchRequest struct {
+	Query      string `json:"query" binding:"required"`
+	MaxResults int    `json:"maxResults"`
+	Region     string `json:"region"`  // Region code (e.g., "us-en", "de-de")
+	Timeout    int    `json:"timeout"` // Timeout in seconds
+}
+
+// ImageSearchResult represents a single image search result
+type ImageSearchResult struct {
+	Title        string `json:"title"`
+	ImageURL     string `json:"imageUrl"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+	SourceURL    string `json:"sourceUrl"` // The page the image was found on
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+}
+
+// ddgImageResponse mirrors the JSON returned by DuckDuckGo's i.js endpoint
+type ddgImageResponse struct {
+	Results []struct {
+		Title     string `json:"title"`
+		Image     string `json:"image"`
+		Thumbnail string `json:"thumbnail"`
+		URL       string `json:"url"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"results"`
+}
+
+// vqdPattern extracts the vqd token DuckDuckGo requires for its JSON APIs
+var vqdPattern = regexp.MustCompile(`vqd=['"]?([\d-]+)['"]?`)
+
+// ImageSearchProxyHandler returns a handler that performs image searches via
+// DuckDuckGo, returning thumbnail URLs, source pages, and dimensions so
+// vision-capable chats can pull reference images
+func ImageSearchProxyHandler() gin.HandlerFunc {
+	fetcher := GetFetcher()
+
+	return func(c *gin.Context) {
+		var req ImageSearchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		maxResults := req.MaxResults
+		if maxResults <= 0 {
+			maxResults = 5
+		}
+		if maxResults > 20 {
+			maxResults = 20
+		}
+
+		opts := DefaultFetchOptions()
+		if req.Timeout > 0 && req.Timeout <= 60 {
+			opts.Timeout = time.Duration(req.Timeout) * time.Second
+		} else {
+			opts.Timeout = 20 * time.Second
+		}
+
+		ctx := c.Request.Context()
+
+		// The query leaves the machine, so scrub any PII first
+		query := scrubOutboundText(req.Query, "image-search")
+
+		// Step 1: fetch the regular search page to obtain the vqd token,
+		// which DuckDuckGo requires for its image JSON API
+		tokenURL := fmt.Sprintf("https://duckduckgo.com/?q=%s&iax=images&ia=images", url.QueryEscape(query))
+		tokenPage, err := fetcher.Fetch(ctx, tokenURL, opts)
+		if err != nil {
+			globalSearchHealth.recordSearch(searchProviderDuckDuckGoImages, false, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to perform image search: " + err.Error()})
+			return
+		}
+
+		vqdMatch := vqdPattern.FindStringSubmatch(tokenPage.Content)
+		if len(vqdMatch) < 2 {
+			// A missing token usually means the bot-block interstitial
+			globalSearchHealth.recordSearch(searchProviderDuckDuckGoImages, true,
+				fmt.Errorf("could not obtain search token"))
+			c.JSON(http.StatusBadGateway, gin.H{"error": "image search failed: could not obtain search token"})
+			return
+		}
+
+		region := req.Region
+		if region == "" {
+			region = "us-en"
+		}
+
+		// Step 2: call the image JSON endpoint with the token
+		imageURL := fmt.Sprintf("https://duckduckgo.com/i.js?l=%s&o=json&q=%s&vqd=%s",
+			url.QueryEscape(region), url.QueryEscape(query), vqdMatch[1])
+
+		result, err := fetcher.Fetch(ctx, imageURL, opts)
+		if err != nil {
+			globalSearchHealth.recordSearch(searchProviderDuckDuckGoImages, false, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch image results: " + err.Error()})
+			return
+		}
+		if result.StatusCode >= 400 {
+			blocked := result.StatusCode == http.StatusForbidden || result.StatusCode == http.StatusTooManyRequests
+			globalSearchHealth.recordSearch(searchProviderDuckDuckGoImages, blocked,
+				fmt.Errorf("HTTP %s", http.StatusText(result.StatusCode)))
+			c.JSON(http.StatusBadGateway, gin.H{"error": "image search failed: HTTP " + http.StatusText(result.StatusCode)})
+			return
+		}
+
+		var ddgResp ddgImageResponse
+		if err := json.Unmarshal([]byte(result.Content), &ddgResp); err != nil {
+			globalSearchHealth.recordSearch(searchProviderDuckDuckGoImages, false,
+				fmt.Errorf("failed to parse image results"))
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to parse image results"})
+			return
+		}
+		globalSearchHealth.recordSearch(searchProviderDuckDuckGoImages, false, nil)
+
+		results := []ImageSearchResult{}
+		for _, r := range ddgResp.Results {
+			if len(results) >= maxResults {
+				break
+			}
+			if r.Image == "" {
+				continue
+			}
+			results = append(results, ImageSearchResult{
+				Title:        r.Title,
+				ImageURL:     r.Image,
+				ThumbnailURL: r.Thumbnail,
+				SourceURL:    r.URL,
+				Width:        r.Width,
+				Height:       r.Height,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"query":   req.Query,
+			"results": results,
+			"count":   len(results),
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// localSearchSnippetLength caps the excerpt returned for a matching message
+const localSearchSnippetLength = 200
+
+// searchLocal searches the user's own data (chat titles, message content,
+// and audio attachment transcripts) and returns results in the same shape
+// as web search, with vessel:// URLs pointing at the matching chat. This
+// lets the agent choose between searching the web and searching what the
+// user already has.
+func searchLocal(db *sql.DB, query string, maxResults int) ([]SearchResult, error) {
+	pattern := "%" + query + "%"
+
+	// Chat title matches rank first - a title hit is usually the better answer
+	rows, err := db.Query(`
+		SELECT id, title, '' AS snippet FROM chats WHERE title LIKE ?
+		UNION ALL
+		SELECT c.id, c.title, m.content FROM messages m
+		JOIN chats c ON c.id = m.chat_id
+		WHERE m.content LIKE ?
+		UNION ALL
+		SELECT c.id, c.title, a.transcript FROM attachments a
+		JOIN messages m ON m.id = a.message_id
+		JOIN chats c ON c.id = m.chat_id
+		WHERE a.transcript LIKE ?
+		LIMIT ?`, pattern, pattern, pattern, maxResults*3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search local data: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	seen := make(map[string]bool)
+
+	for rows.Next() {
+		var chatID, title, content string
+		if err := rows.Scan(&chatID, &title, &content); err != nil {
+			return nil, fmt.Errorf("failed to scan local search result: %w", err)
+		}
+
+		// One result per chat - additional message hits add nothing
+		if seen[chatID] {
+			continue
+		}
+		seen[chatID] = true
+
+		results = append(results, SearchResult{
+			Title:   title,
+			URL:     "vessel://chats/" + chatID,
+			Snippet: localSearchSnippet(content, query),
+		})
+
+		if len(results) >= maxResults {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// localSearchSnippet extracts a short excerpt around the first match
+func localSearchSnippet(content, query string) string {
+	if content == "" {
+		return ""
+	}
+
+	index := strings.Index(strings.ToLower(content), strings.ToLower(query))
+	if index < 0 {
+		index = 0
+	}
+
+	// Center the excerpt on the match
+	start := index - localSearchSnippetLength/2
+	if start < 0 {
+		start = 0
+	}
+	end := start + localSearchSnippetLength
+	if end > len(content) {
+		end = len(content)
+	}
+
+	snippet := strings.TrimSpace(content[start:end])
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(content) {
+		snippet += "..."
+	}
+	return snippet
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/models"
+)
+
+// GetSettingsHandler returns all stored settings as one JSON object. Keys
+// are whatever the client chooses ("defaultModel", "generation", "ui",
+// ...); the server treats values as opaque JSON.
+func GetSettingsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings, err := models.GetAllSettings(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load settings"})
+			return
+		}
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+// PatchSettingsHandler merges the request body into the stored settings:
+// each top-level key is upserted, and an explicit JSON null deletes the
+// key. Returns the full settings object after the merge.
+func PatchSettingsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var patch map[string]json.RawMessage
+		if err := c.ShouldBindJSON(&patch); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+
+		for key, value := range patch {
+			if key == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Setting keys must not be empty"})
+				return
+			}
+			var err error
+			if string(value) == "null" {
+				err = models.DeleteSetting(db, key)
+			} else {
+				err = models.SetSetting(db, key, value)
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save setting: " + key})
+				return
+			}
+		}
+
+		settings, err := models.GetAllSettings(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load settings"})
+			return
+		}
+		c.JSON(http.StatusOK, settings)
+	}
+}
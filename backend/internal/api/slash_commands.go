@@ -0,0 +1,164 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/models"
+)
+
+// slashCommandRequest is the create/update payload for a slash command
+type slashCommandRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Template    string `json:"template" binding:"required"`
+}
+
+// ListSlashCommandsHandler lists all slash commands
+func ListSlashCommandsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		commands, err := models.ListSlashCommands(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if commands == nil {
+			commands = []models.SlashCommand{}
+		}
+		c.JSON(http.StatusOK, gin.H{"commands": commands})
+	}
+}
+
+// CreateSlashCommandHandler creates a new slash command
+func CreateSlashCommandHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req slashCommandRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+
+		name := normalizeCommandName(req.Name)
+		if !models.ValidSlashCommandName(name) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Command names must be lowercase letters, digits, and hyphens"})
+			return
+		}
+
+		command := &models.SlashCommand{
+			Name:        name,
+			Description: req.Description,
+			Template:    req.Template,
+		}
+		if err := models.CreateSlashCommand(db, command); err != nil {
+			if strings.Contains(err.Error(), "UNIQUE") {
+				c.JSON(http.StatusConflict, gin.H{"error": "A command with that name already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, command)
+	}
+}
+
+// UpdateSlashCommandHandler updates an existing slash command
+func UpdateSlashCommandHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req slashCommandRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+
+		name := normalizeCommandName(req.Name)
+		if !models.ValidSlashCommandName(name) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Command names must be lowercase letters, digits, and hyphens"})
+			return
+		}
+
+		command := &models.SlashCommand{
+			ID:          c.Param("id"),
+			Name:        name,
+			Description: req.Description,
+			Template:    req.Template,
+		}
+		if err := models.UpdateSlashCommand(db, command); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "command not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, command)
+	}
+}
+
+// DeleteSlashCommandHandler deletes a slash command
+func DeleteSlashCommandHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.DeleteSlashCommand(db, c.Param("id")); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "command not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "command deleted"})
+	}
+}
+
+// ExpandSlashCommandHandler expands a typed command into its prompt.
+// "/translate-to:fr" with text "bonjour" looks up the translate-to command
+// and renders its template with {{arg}}=fr and {{text}}=bonjour.
+func ExpandSlashCommandHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Command string `json:"command" binding:"required"`
+			Text    string `json:"text"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+
+		name := normalizeCommandName(req.Command)
+		arg := ""
+		if i := strings.Index(name, ":"); i >= 0 {
+			name, arg = name[:i], name[i+1:]
+		}
+
+		command, err := models.GetSlashCommandByName(db, name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if command == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "command not found: /" + name})
+			return
+		}
+
+		rendered, missing := models.RenderTemplate(command.Template, map[string]string{
+			"text": req.Text,
+			"arg":  arg,
+		})
+		if missing == nil {
+			missing = []string{}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"command":  command.Name,
+			"arg":      arg,
+			"rendered": rendered,
+			"missing":  missing,
+		})
+	}
+}
+
+// normalizeCommandName strips the leading slash and surrounding whitespace
+func normalizeCommandName(name string) string {
+	return strings.TrimPrefix(strings.TrimSpace(name), "/")
+}
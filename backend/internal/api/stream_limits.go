@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+)
+
+// streamLimiter tracks in-flight streaming generations globally and per
+// client address, enforcing the configured ceilings. Rejections are
+// counted for the admin stats.
+type streamLimiter struct {
+	mu        sync.Mutex
+	total     int
+	perClient map[string]int
+}
+
+var (
+	streams         = &streamLimiter{perClient: map[string]int{}}
+	streamRejects   atomic.Int64
+	streamPeakTotal atomic.Int64
+)
+
+// acquire reserves a streaming slot for the client; the caller must
+// invoke the returned release exactly once. A false result means a
+// limit was hit and nothing was reserved.
+func (l *streamLimiter) acquire(client string) (func(), bool) {
+	cfg := config.Get().Ollama.Streams
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if cfg.MaxConcurrent > 0 && l.total >= cfg.MaxConcurrent {
+		streamRejects.Add(1)
+		return nil, false
+	}
+	if cfg.MaxPerClient > 0 && l.perClient[client] >= cfg.MaxPerClient {
+		streamRejects.Add(1)
+		return nil, false
+	}
+
+	l.total++
+	l.perClient[client]++
+	if peak := int64(l.total); peak > streamPeakTotal.Load() {
+		streamPeakTotal.Store(peak)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.total--
+			l.perClient[client]--
+			if l.perClient[client] <= 0 {
+				delete(l.perClient, client)
+			}
+		})
+	}, true
+}
+
+// counts returns the live totals for limit responses and stats
+func (l *streamLimiter) counts(client string) (total, forClient int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.total, l.perClient[client]
+}
+
+// acquireStreamSlot reserves a slot for this request's client address.
+// On rejection it writes the 429 itself - active counts, the limits,
+// and a Retry-After hint - and returns false.
+func acquireStreamSlot(c *gin.Context) (func(), bool) {
+	client := getClientIP(c)
+	release, ok := streams.acquire(client)
+	if ok {
+		return release, true
+	}
+
+	cfg := config.Get().Ollama.Streams
+	total, forClient := streams.counts(client)
+	c.Header("Retry-After", "5")
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":             "too many concurrent streams - wait for one to finish",
+		"active":            total,
+		"activeForClient":   forClient,
+		"maxConcurrent":     cfg.MaxConcurrent,
+		"maxPerClient":      cfg.MaxPerClient,
+		"retryAfterSeconds": 5,
+	})
+	return nil, false
+}
@@ -65,6 +65,12 @@ func PushChangesHandler(db *sql.DB) gin.HandlerFunc {
 
 		// Process messages
 		for _, msg := range req.Messages {
+			storedContent, encErr := models.EncodeMessageContent(msg.Content)
+			if encErr != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encrypt message content: " + encErr.Error()})
+				return
+			}
+
 			// Check if message exists
 			var existingVersion int64
 			err := tx.QueryRow("SELECT sync_version FROM messages WHERE id = ?", msg.ID).Scan(&existingVersion)
@@ -74,7 +80,7 @@ func PushChangesHandler(db *sql.DB) gin.HandlerFunc {
 				_, err = tx.Exec(`
 					INSERT INTO messages (id, chat_id, parent_id, role, content, sibling_index, created_at, sync_version)
 					VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-					msg.ID, msg.ChatID, msg.ParentID, msg.Role, msg.Content,
+					msg.ID, msg.ChatID, msg.ParentID, msg.Role, storedContent,
 					msg.SiblingIndex, msg.CreatedAt, msg.SyncVersion,
 				)
 			} else if err == nil && msg.SyncVersion > existingVersion {
@@ -82,7 +88,7 @@ func PushChangesHandler(db *sql.DB) gin.HandlerFunc {
 				_, err = tx.Exec(`
 					UPDATE messages SET content = ?, sibling_index = ?, sync_version = ?
 					WHERE id = ?`,
-					msg.Content, msg.SiblingIndex, msg.SyncVersion, msg.ID,
+					storedContent, msg.SiblingIndex, msg.SyncVersion, msg.ID,
 				)
 			}
 
@@ -103,6 +109,12 @@ func PushChangesHandler(db *sql.DB) gin.HandlerFunc {
 			maxVersion = 0
 		}
 
+		EmitEvent(EventSyncPushed, map[string]interface{}{
+			"chats":        len(req.Chats),
+			"messages":     len(req.Messages),
+			"sync_version": maxVersion,
+		})
+
 		c.JSON(http.StatusOK, gin.H{
 			"message":      "changes pushed successfully",
 			"sync_version": maxVersion,
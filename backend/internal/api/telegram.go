@@ -0,0 +1,391 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	ollamaapi "github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// telegramAPIBase is the Telegram Bot API endpoint
+const telegramAPIBase = "https://api.telegram.org"
+
+// telegramPollTimeout is the long-poll wait passed to getUpdates
+const telegramPollTimeout = 60 * time.Second
+
+// telegramEditInterval throttles streaming edits - Telegram rate-limits
+// editMessageText, so partial replies update at most this often
+const telegramEditInterval = 1500 * time.Millisecond
+
+// telegramMessageLimit is Telegram's maximum message length
+const telegramMessageLimit = 4096
+
+// telegramHistoryLimit caps how many stored messages are replayed as
+// context for each reply
+const telegramHistoryLimit = 40
+
+// telegramHTTPClient outlives the long-poll timeout by a margin
+var telegramHTTPClient = &http.Client{Transport: sharedRoundTripper{}, Timeout: telegramPollTimeout + 10*time.Second}
+
+// telegramBridge maps Telegram conversations onto vessel chats
+type telegramBridge struct {
+	db           *sql.DB
+	ollama       *OllamaService
+	token        string
+	defaultModel string
+	allowed      []int64
+}
+
+// tgUpdate, tgMessage, and tgChat are the slices of the Bot API types the
+// bridge actually uses
+type tgUpdate struct {
+	UpdateID int64      `json:"update_id"`
+	Message  *tgMessage `json:"message"`
+}
+
+type tgMessage struct {
+	MessageID int64  `json:"message_id"`
+	Chat      tgChat `json:"chat"`
+	Text      string `json:"text"`
+}
+
+type tgChat struct {
+	ID int64 `json:"id"`
+}
+
+// StartTelegramBridge launches the Telegram bot long-poll loop when a bot
+// token is configured. Conversations map onto regular vessel chats, so
+// everything said over Telegram shows up in the web UI too.
+func StartTelegramBridge(db *sql.DB, ollamaService *OllamaService) {
+	cfg := config.Get().Telegram
+	if cfg.BotToken == "" {
+		return
+	}
+
+	logger := logging.Module("telegram")
+
+	if offlineMode() {
+		logger.Info("Telegram bridge disabled: offline mode is on")
+		return
+	}
+	if ollamaService == nil {
+		logger.Warn("Telegram bridge disabled: Ollama service not initialized")
+		return
+	}
+
+	bridge := &telegramBridge{
+		db:           db,
+		ollama:       ollamaService,
+		token:        cfg.BotToken,
+		defaultModel: cfg.Model,
+		allowed:      cfg.AllowedChats,
+	}
+
+	logger.Info("Telegram bridge started")
+	go bridge.run()
+}
+
+// run is the getUpdates long-poll loop
+func (b *telegramBridge) run() {
+	logger := logging.Module("telegram")
+	var offset int64
+
+	for {
+		updates, err := b.getUpdates(offset)
+		if err != nil {
+			logger.Warn("Failed to fetch Telegram updates", "error", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message == nil || update.Message.Text == "" {
+				continue
+			}
+			if !b.chatAllowed(update.Message.Chat.ID) {
+				logger.Warn("Ignoring message from unlisted Telegram chat", "telegramChatId", update.Message.Chat.ID)
+				continue
+			}
+			b.handleMessage(update.Message)
+		}
+	}
+}
+
+// chatAllowed enforces the allowed_chats list; an empty list allows everyone
+func (b *telegramBridge) chatAllowed(telegramChatID int64) bool {
+	if len(b.allowed) == 0 {
+		return true
+	}
+	for _, id := range b.allowed {
+		if id == telegramChatID {
+			return true
+		}
+	}
+	return false
+}
+
+// handleMessage routes one incoming Telegram message: bot commands are
+// handled directly, everything else becomes a model turn
+func (b *telegramBridge) handleMessage(msg *tgMessage) {
+	text := strings.TrimSpace(msg.Text)
+	if strings.HasPrefix(text, "/") {
+		b.handleCommand(msg.Chat.ID, text)
+		return
+	}
+	b.handleChatTurn(msg.Chat.ID, text)
+}
+
+// handleCommand implements the bot commands: /start, /new, and /model
+func (b *telegramBridge) handleCommand(telegramChatID int64, text string) {
+	command, arg, _ := strings.Cut(text, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch command {
+	case "/start", "/help":
+		b.reply(telegramChatID,
+			"Connected to vessel. Send a message to chat with "+b.modelFor(nil)+".\n"+
+				"/new starts a fresh conversation, /model <name> switches models.")
+	case "/new":
+		chat := &models.Chat{Title: "Telegram chat", Model: b.defaultModel}
+		if err := models.CreateChat(b.db, chat); err != nil {
+			b.reply(telegramChatID, "Failed to start a new chat: "+err.Error())
+			return
+		}
+		mapping := &models.TelegramChat{TelegramChatID: telegramChatID, ChatID: chat.ID}
+		if err := models.UpsertTelegramChat(b.db, mapping); err != nil {
+			b.reply(telegramChatID, "Failed to start a new chat: "+err.Error())
+			return
+		}
+		b.reply(telegramChatID, "Started a new conversation.")
+	case "/model":
+		mapping, err := models.GetTelegramChat(b.db, telegramChatID)
+		if err != nil {
+			b.reply(telegramChatID, "Failed to look up conversation: "+err.Error())
+			return
+		}
+		if arg == "" {
+			b.reply(telegramChatID, "Current model: "+b.modelFor(mapping))
+			return
+		}
+		if mapping == nil {
+			if mapping, err = b.ensureMapping(telegramChatID); err != nil {
+				b.reply(telegramChatID, "Failed to look up conversation: "+err.Error())
+				return
+			}
+		}
+		if err := models.SetTelegramChatModel(b.db, telegramChatID, arg); err != nil {
+			b.reply(telegramChatID, "Failed to switch model: "+err.Error())
+			return
+		}
+		b.reply(telegramChatID, "Switched to "+arg+".")
+	default:
+		b.reply(telegramChatID, "Unknown command. Use /new, /model <name>, or just send a message.")
+	}
+}
+
+// handleChatTurn stores the user message, streams a model reply back as an
+// edited Telegram message, and stores the finished reply
+func (b *telegramBridge) handleChatTurn(telegramChatID int64, text string) {
+	logger := logging.Module("telegram")
+
+	mapping, err := b.ensureMapping(telegramChatID)
+	if err != nil {
+		b.reply(telegramChatID, "Error: "+err.Error())
+		return
+	}
+
+	model := b.modelFor(mapping)
+	if model == "" {
+		b.reply(telegramChatID, "No model configured. Set telegram.model or use /model <name>.")
+		return
+	}
+
+	userMsg := &models.Message{ChatID: mapping.ChatID, Role: "user", Content: text}
+	if err := models.CreateMessage(b.db, userMsg); err != nil {
+		b.reply(telegramChatID, "Error: "+err.Error())
+		return
+	}
+
+	history, err := b.chatHistory(mapping.ChatID)
+	if err != nil {
+		b.reply(telegramChatID, "Error: "+err.Error())
+		return
+	}
+
+	// Placeholder message that the streamed reply edits in place
+	placeholderID, err := b.sendMessage(telegramChatID, "...")
+	if err != nil {
+		logger.Warn("Failed to send Telegram reply", "error", err)
+		return
+	}
+
+	stream := true
+	chatReq := &ollamaapi.ChatRequest{Model: model, Messages: history, Stream: &stream}
+
+	var reply strings.Builder
+	lastEdit := time.Now()
+
+	err = b.ollama.Client().Chat(context.Background(), chatReq, func(resp ollamaapi.ChatResponse) error {
+		reply.WriteString(resp.Message.Content)
+		if !resp.Done && time.Since(lastEdit) >= telegramEditInterval && reply.Len() > 0 {
+			b.editMessage(telegramChatID, placeholderID, reply.String())
+			lastEdit = time.Now()
+		}
+		return nil
+	})
+	if err != nil {
+		b.editMessage(telegramChatID, placeholderID, "Error: "+err.Error())
+		return
+	}
+
+	final := reply.String()
+	if final == "" {
+		final = "(empty reply)"
+	}
+	b.editMessage(telegramChatID, placeholderID, final)
+
+	assistantMsg := &models.Message{ChatID: mapping.ChatID, ParentID: &userMsg.ID, Role: "assistant", Content: final}
+	if err := models.CreateMessage(b.db, assistantMsg); err != nil {
+		logger.Warn("Failed to store Telegram reply", "error", err)
+	}
+}
+
+// ensureMapping returns the vessel chat mapped to a Telegram conversation,
+// creating both on first contact
+func (b *telegramBridge) ensureMapping(telegramChatID int64) (*models.TelegramChat, error) {
+	mapping, err := models.GetTelegramChat(b.db, telegramChatID)
+	if err != nil || mapping != nil {
+		return mapping, err
+	}
+
+	chat := &models.Chat{Title: "Telegram chat", Model: b.defaultModel}
+	if err := models.CreateChat(b.db, chat); err != nil {
+		return nil, err
+	}
+
+	mapping = &models.TelegramChat{TelegramChatID: telegramChatID, ChatID: chat.ID}
+	if err := models.UpsertTelegramChat(b.db, mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// modelFor picks the conversation's model override, falling back to the
+// configured bridge default
+func (b *telegramBridge) modelFor(mapping *models.TelegramChat) string {
+	if mapping != nil && mapping.Model != "" {
+		return mapping.Model
+	}
+	return b.defaultModel
+}
+
+// chatHistory replays the stored conversation as Ollama chat messages
+func (b *telegramBridge) chatHistory(chatID string) ([]ollamaapi.Message, error) {
+	stored, err := models.GetMessagesByChatID(b.db, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stored) > telegramHistoryLimit {
+		stored = stored[len(stored)-telegramHistoryLimit:]
+	}
+
+	history := make([]ollamaapi.Message, 0, len(stored))
+	for _, msg := range stored {
+		history = append(history, ollamaapi.Message{Role: msg.Role, Content: msg.Content})
+	}
+	return history, nil
+}
+
+// reply sends a plain message, logging failures
+func (b *telegramBridge) reply(telegramChatID int64, text string) {
+	if _, err := b.sendMessage(telegramChatID, text); err != nil {
+		logging.Module("telegram").Warn("Failed to send Telegram reply", "error", err)
+	}
+}
+
+// sendMessage posts a new message and returns its message_id (for edits)
+func (b *telegramBridge) sendMessage(telegramChatID int64, text string) (int64, error) {
+	var sent tgMessage
+	err := b.call("sendMessage", map[string]interface{}{
+		"chat_id": telegramChatID,
+		"text":    truncateTelegramText(text),
+	}, &sent)
+	return sent.MessageID, err
+}
+
+// editMessage replaces a previously sent message's text. "message is not
+// modified" errors are expected when a partial equals the last edit and
+// are ignored.
+func (b *telegramBridge) editMessage(telegramChatID, messageID int64, text string) {
+	err := b.call("editMessageText", map[string]interface{}{
+		"chat_id":    telegramChatID,
+		"message_id": messageID,
+		"text":       truncateTelegramText(text),
+	}, nil)
+	if err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		logging.Module("telegram").Warn("Failed to edit Telegram message", "error", err)
+	}
+}
+
+// getUpdates long-polls for new updates starting at offset
+func (b *telegramBridge) getUpdates(offset int64) ([]tgUpdate, error) {
+	var updates []tgUpdate
+	err := b.call("getUpdates", map[string]interface{}{
+		"offset":  offset,
+		"timeout": int(telegramPollTimeout.Seconds()),
+	}, &updates)
+	return updates, err
+}
+
+// call performs one Bot API request, decoding the result into out when
+// out is non-nil
+func (b *telegramBridge) call(method string, params map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	resp, err := telegramHTTPClient.Post(
+		fmt.Sprintf("%s/bot%s/%s", telegramAPIBase, b.token, method),
+		"application/json", bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var decoded struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return err
+	}
+	if !decoded.OK {
+		return fmt.Errorf("telegram API error: %s", decoded.Description)
+	}
+	if out != nil {
+		return json.Unmarshal(decoded.Result, out)
+	}
+	return nil
+}
+
+// truncateTelegramText keeps messages under Telegram's length cap
+func truncateTelegramText(text string) string {
+	if len(text) <= telegramMessageLimit {
+		return text
+	}
+	return text[:telegramMessageLimit-3] + "..."
+}
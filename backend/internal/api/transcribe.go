@@ -0,0 +1,283 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+)
+
+// whisperBinaryCandidates are the whisper.cpp executable names probed on
+// PATH when no binary is configured explicitly
+var whisperBinaryCandidates = []string{"whisper-cli", "whisper-cpp", "whisper"}
+
+// TranscriptionHandler accepts an audio upload (multipart field "file")
+// and transcribes it. With whisper.server_url configured the upload is
+// forwarded to the external Whisper server; otherwise a local whisper.cpp
+// binary runs it and partial segments stream back as NDJSON frames, ending
+// with {"done": true, "text": ...}.
+func TranscriptionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.Get().Whisper
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing audio upload in 'file' field"})
+			return
+		}
+
+		if cfg.ServerURL != "" {
+			proxyWhisperServer(c, cfg.ServerURL, fileHeader)
+			return
+		}
+
+		binary := whisperBinary(cfg)
+		if binary == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "no whisper server or whisper.cpp binary configured"})
+			return
+		}
+		if cfg.ModelPath == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "whisper.model_path is required for local transcription"})
+			return
+		}
+
+		runLocalWhisper(c, binary, cfg.ModelPath, fileHeader)
+	}
+}
+
+// whisperBinary resolves the whisper.cpp executable: the configured path,
+// or the first candidate found on PATH
+func whisperBinary(cfg config.WhisperConfig) string {
+	if cfg.Binary != "" {
+		return cfg.Binary
+	}
+	for _, candidate := range whisperBinaryCandidates {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// transcriptionAvailable reports whether any Whisper integration is usable
+func transcriptionAvailable() bool {
+	cfg := config.Get().Whisper
+	if cfg.ServerURL != "" {
+		return true
+	}
+	return whisperBinary(cfg) != "" && cfg.ModelPath != ""
+}
+
+// transcribeAudioData transcribes raw audio bytes with whichever Whisper
+// integration is configured. Used by the attachment pipeline; the upload
+// endpoint streams instead.
+func transcribeAudioData(ctx context.Context, data []byte, filename string) (string, error) {
+	cfg := config.Get().Whisper
+
+	if cfg.ServerURL != "" {
+		return transcribeViaServer(ctx, cfg.ServerURL, data, filename)
+	}
+
+	binary := whisperBinary(cfg)
+	if binary == "" || cfg.ModelPath == "" {
+		return "", fmt.Errorf("no whisper server or whisper.cpp binary configured")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "vessel-whisper-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	audioPath := filepath.Join(tmpDir, filepath.Base(filename))
+	if err := os.WriteFile(audioPath, data, 0o600); err != nil {
+		return "", err
+	}
+
+	output, err := exec.CommandContext(ctx, binary, "-m", cfg.ModelPath, "-f", audioPath).Output()
+	if err != nil {
+		return "", fmt.Errorf("whisper failed: %w", err)
+	}
+
+	var segments []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if segment := whisperSegmentText(line); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, " "), nil
+}
+
+// transcribeViaServer posts the audio to the external Whisper server and
+// reads the transcript from its JSON response
+func transcribeViaServer(ctx context.Context, serverURL string, data []byte, filename string) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err == nil {
+		_, err = part.Write(data)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", serverURL, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if key := vaultKey("whisper"); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("whisper server returned status %d", resp.StatusCode)
+	}
+
+	var decoded struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(decoded.Text), nil
+}
+
+// whisperSegmentText strips whisper.cpp's "[00:00.000 --> 00:02.000]"
+// timestamp prefix from an output line, returning just the spoken text
+func whisperSegmentText(line string) string {
+	line = strings.TrimSpace(line)
+	if i := strings.Index(line, "]"); strings.HasPrefix(line, "[") && i > 0 {
+		line = strings.TrimSpace(line[i+1:])
+	}
+	return line
+}
+
+// proxyWhisperServer forwards the upload to the external Whisper server
+// and passes its response through
+func proxyWhisperServer(c *gin.Context, serverURL string, fileHeader *multipart.FileHeader) {
+	src, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read upload: " + err.Error()})
+		return
+	}
+	defer src.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", fileHeader.Filename)
+	if err == nil {
+		_, err = io.Copy(part, src)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build upstream request: " + err.Error()})
+		return
+	}
+
+	req, err := http.NewRequestWithContext(c.Request.Context(), "POST", serverURL, &body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if key := vaultKey("whisper"); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "whisper server unreachable: " + err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.DataFromReader(resp.StatusCode, resp.ContentLength, resp.Header.Get("Content-Type"), resp.Body, nil)
+}
+
+// runLocalWhisper saves the upload to a temp file, runs whisper.cpp on
+// it, and streams each transcribed segment as it appears on stdout
+func runLocalWhisper(c *gin.Context, binary, modelPath string, fileHeader *multipart.FileHeader) {
+	tmpDir, err := os.MkdirTemp("", "vessel-whisper-")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	audioPath := filepath.Join(tmpDir, filepath.Base(fileHeader.Filename))
+	if err := c.SaveUploadedFile(fileHeader, audioPath); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save upload: " + err.Error()})
+		return
+	}
+
+	cmd := exec.CommandContext(c.Request.Context(), binary, "-m", modelPath, "-f", audioPath)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start whisper: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Cache-Control", "no-cache")
+	flusher, _ := c.Writer.(http.Flusher)
+
+	var segments []string
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		// whisper.cpp prints "[00:00.000 --> 00:02.000]  text"; keep just
+		// the text for the partial frames
+		line = whisperSegmentText(line)
+		if line == "" {
+			continue
+		}
+		segments = append(segments, line)
+
+		frame, _ := json.Marshal(gin.H{"partial": line})
+		c.Writer.Write(append(frame, '\n'))
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	err = cmd.Wait()
+	final := gin.H{"done": true, "text": strings.Join(segments, " ")}
+	if err != nil {
+		final["error"] = err.Error()
+	}
+	frame, _ := json.Marshal(final)
+	c.Writer.Write(append(frame, '\n'))
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ollama/ollama/api"
+
+	"vessel-backend/internal/config"
+)
+
+// TranslateRequest represents a translation request. SourceLanguage is
+// optional; when empty the model detects it.
+type TranslateRequest struct {
+	Text           string `json:"text" binding:"required"`
+	TargetLanguage string `json:"target_language" binding:"required"`
+	SourceLanguage string `json:"source_language"`
+	Model          string `json:"model"` // Overrides translate.model from config
+}
+
+// TranslateHandler returns a handler that translates text with a local
+// model using a fixed structured prompt, so every client gets the same
+// translation behavior without crafting prompts itself.
+func TranslateHandler(ollamaService *OllamaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ollamaService == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "translation unavailable: Ollama service not initialized"})
+			return
+		}
+
+		var req TranslateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		model := req.Model
+		if model == "" {
+			model = config.Get().Translate.Model
+		}
+		if model == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no model in request and translate.model not configured"})
+			return
+		}
+
+		source := strings.TrimSpace(req.SourceLanguage)
+		var prompt string
+		if source != "" {
+			prompt = fmt.Sprintf(
+				"Translate the following text from %s to %s. Respond with JSON: {\"detected_language\": source language name, \"translation\": the translated text}. Do not add explanations.\n\nText:\n%s",
+				source, req.TargetLanguage, req.Text,
+			)
+		} else {
+			prompt = fmt.Sprintf(
+				"Detect the language of the following text and translate it to %s. Respond with JSON: {\"detected_language\": source language name, \"translation\": the translated text}. Do not add explanations.\n\nText:\n%s",
+				req.TargetLanguage, req.Text,
+			)
+		}
+
+		stream := false
+		genReq := &api.GenerateRequest{
+			Model:  model,
+			Prompt: prompt,
+			Format: json.RawMessage(`"json"`),
+			Stream: &stream,
+		}
+
+		var output string
+		err := ollamaService.Client().Generate(c.Request.Context(), genReq, func(resp api.GenerateResponse) error {
+			output = resp.Response
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "translation failed: " + err.Error()})
+			return
+		}
+
+		var decoded struct {
+			DetectedLanguage string `json:"detected_language"`
+			Translation      string `json:"translation"`
+		}
+		if err := json.Unmarshal([]byte(output), &decoded); err != nil || decoded.Translation == "" {
+			// JSON mode occasionally produces malformed output; surface the
+			// raw response rather than failing the whole request
+			decoded.Translation = strings.TrimSpace(output)
+		}
+		if source != "" {
+			decoded.DetectedLanguage = source
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"translation":     decoded.Translation,
+			"source_language": decoded.DetectedLanguage,
+			"target_language": req.TargetLanguage,
+			"model":           model,
+		})
+	}
+}
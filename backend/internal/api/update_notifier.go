@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
+)
+
+// updateCheckInterval is how often the background notifier looks for a new
+// release; it matches the TTL of the version cache so at most one GitHub
+// call per hour comes out of it
+const updateCheckInterval = 1 * time.Hour
+
+// notifyHTTPClient is shared by webhook and ntfy deliveries
+var notifyHTTPClient = &http.Client{Transport: sharedRoundTripper{}, Timeout: 15 * time.Second}
+
+// StartUpdateNotifier launches the hourly release check when a webhook URL
+// or ntfy topic is configured. Each new version is announced exactly once
+// per process lifetime, so a restart may repeat a notification but an idle
+// server never spams.
+func StartUpdateNotifier(currentVersion string) {
+	cfg := config.Get().Notifications
+	if cfg.WebhookURL == "" && cfg.NtfyTopic == "" && cfg.DiscordWebhookURL == "" && cfg.SlackWebhookURL == "" {
+		return
+	}
+
+	go func() {
+		logger := logging.Module("updates")
+		lastNotified := ""
+
+		ticker := time.NewTicker(updateCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			if !offlineMode() {
+				latest, url := getLatestVersion()
+				if latest != "" && latest != lastNotified && compareVersions(currentVersion, latest) {
+					notifyEvent(
+						"Vessel update available",
+						"Version "+latest+" is available (running "+currentVersion+"): "+url,
+					)
+					lastNotified = latest
+					logger.Info("Sent update notification", "version", latest)
+				}
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// notifyEvent delivers a notification to every configured channel. Used by
+// the update checker; safe to call from anywhere that has something worth
+// telling the operator about.
+func notifyEvent(title, message string) {
+	cfg := config.Get().Notifications
+	logger := logging.Module("updates")
+
+	// Always surface operator notifications in the in-app center too
+	addNotification("system", title, message)
+
+	if cfg.WebhookURL != "" {
+		payload, _ := json.Marshal(map[string]string{
+			"title":   title,
+			"message": message,
+		})
+		resp, err := notifyHTTPClient.Post(cfg.WebhookURL, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			logger.Warn("Webhook notification failed", "error", err)
+		} else {
+			resp.Body.Close()
+		}
+	}
+
+	if cfg.NtfyTopic != "" {
+		server := cfg.NtfyServer
+		if server == "" {
+			server = "https://ntfy.sh"
+		}
+		url := strings.TrimSuffix(server, "/") + "/" + cfg.NtfyTopic
+
+		req, err := http.NewRequest("POST", url, strings.NewReader(message))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Title", title)
+
+		resp, err := notifyHTTPClient.Do(req)
+		if err != nil {
+			logger.Warn("ntfy notification failed", "error", err)
+		} else {
+			resp.Body.Close()
+		}
+	}
+
+	// Discord and Slack incoming webhooks take one text field each
+	if cfg.DiscordWebhookURL != "" {
+		payload, _ := json.Marshal(map[string]string{
+			"content": "**" + title + "**\n" + message,
+		})
+		resp, err := notifyHTTPClient.Post(cfg.DiscordWebhookURL, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			logger.Warn("Discord notification failed", "error", err)
+		} else {
+			resp.Body.Close()
+		}
+	}
+
+	if cfg.SlackWebhookURL != "" {
+		payload, _ := json.Marshal(map[string]string{
+			"text": "*" + title + "*\n" + message,
+		})
+		resp, err := notifyHTTPClient.Post(cfg.SlackWebhookURL, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			logger.Warn("Slack notification failed", "error", err)
+		} else {
+			resp.Body.Close()
+		}
+	}
+}
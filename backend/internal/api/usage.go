@@ -0,0 +1,137 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/logging"
+	"vessel-backend/internal/models"
+)
+
+// metricUser identifies who ran a generation. There are no accounts, so
+// this is the X-Vessel-User header when a client sets one (shared
+// deployments behind a proxy can inject it) and the client IP otherwise.
+func metricUser(c *gin.Context) string {
+	if user := c.GetHeader("X-Vessel-User"); user != "" {
+		return user
+	}
+	return getClientIP(c)
+}
+
+// recordGeneration stores one usage record, logging instead of failing the
+// request when the insert goes wrong
+func recordGeneration(db *sql.DB, metric *models.GenerationMetric) {
+	if db == nil {
+		return
+	}
+	metric.CostUSD = estimateCost(metric.Model, metric.PromptTokens, metric.CompletionTokens)
+	if err := models.RecordGeneration(db, metric); err != nil {
+		logging.Module("metrics").Warn("Failed to record generation metric", "error", err)
+	}
+}
+
+// estimateCost prices a generation from the costs.models config. Pricing
+// is matched on the exact model name first, then on the base name before
+// the tag ("llama3.1" for "llama3.1:8b"). Unpriced models cost zero.
+func estimateCost(model string, promptTokens, completionTokens int) float64 {
+	pricing := config.Get().Costs.Models
+	if len(pricing) == 0 {
+		return 0
+	}
+
+	entry, ok := pricing[model]
+	if !ok {
+		if base, _, found := strings.Cut(model, ":"); found {
+			entry, ok = pricing[base]
+		}
+	}
+	if !ok {
+		return 0
+	}
+
+	return float64(promptTokens)/1e6*entry.InputPerMTok +
+		float64(completionTokens)/1e6*entry.OutputPerMTok
+}
+
+// CostsHandler returns estimated spend per calendar month and user
+func CostsHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		totals, err := models.MonthlyCostTotals(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"costs": totals})
+	}
+}
+
+// usageSince parses the ?days= window (default 30, capped at 365)
+func usageSince(c *gin.Context) time.Time {
+	days := 30
+	if value := c.Query("days"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= 365 {
+			days = n
+		}
+	}
+	return time.Now().UTC().AddDate(0, 0, -days)
+}
+
+// UsageHandler returns usage rollups grouped by ?group_by= day (default),
+// model, or user, over the last ?days= days
+func UsageHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dimension := c.DefaultQuery("group_by", "day")
+
+		rollups, err := models.UsageRollupBy(db, dimension, usageSince(c))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"groupBy": dimension,
+			"usage":   rollups,
+		})
+	}
+}
+
+// UsageExportHandler streams the raw metric rows as CSV
+func UsageExportHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metrics, err := models.ListGenerationMetrics(db, usageSince(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		filename := "vessel-usage-" + time.Now().UTC().Format("2006-01-02") + ".csv"
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+		writer := csv.NewWriter(c.Writer)
+		writer.Write([]string{"created_at", "model", "user", "kind", "prompt_tokens", "completion_tokens", "duration_ms", "first_token_ms", "queue_ms", "tool_calls", "cost_usd"})
+		for _, metric := range metrics {
+			writer.Write([]string{
+				metric.CreatedAt.Format(time.RFC3339),
+				metric.Model,
+				metric.User,
+				metric.Kind,
+				strconv.Itoa(metric.PromptTokens),
+				strconv.Itoa(metric.CompletionTokens),
+				fmt.Sprintf("%d", metric.DurationMs),
+				fmt.Sprintf("%d", metric.FirstTokenMs),
+				fmt.Sprintf("%d", metric.QueueMs),
+				strconv.Itoa(metric.ToolCalls),
+				strconv.FormatFloat(metric.CostUSD, 'f', -1, 64),
+			})
+		}
+		writer.Flush()
+	}
+}
@@ -52,7 +52,7 @@ func fetchLatestRelease() (string, string, error) {
 	repo := getGitHubRepo()
 	url := "https://api.github.com/repos/" + repo + "/releases/latest"
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := newOutboundClient(10 * time.Second)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return "", "", err
@@ -60,6 +60,9 @@ func fetchLatestRelease() (string, string, error) {
 
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", "Vessel-Update-Checker")
+	if key := vaultKey("github"); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -155,6 +158,12 @@ func compareVersions(current, latest string) bool {
 // VersionHandler returns a handler that provides version information
 func VersionHandler(currentVersion string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// In offline mode, report only the running version - no GitHub call
+		if offlineMode() {
+			c.JSON(http.StatusOK, VersionInfo{Current: currentVersion})
+			return
+		}
+
 		latest, updateURL := getLatestVersion()
 
 		info := VersionInfo{
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	ollamaapi "github.com/ollama/ollama/api"
+)
+
+// defaultWarmupKeepAlive keeps a pre-loaded model resident long enough
+// for the user to type their first message
+const defaultWarmupKeepAlive = 10 * time.Minute
+
+// WarmupRequest optionally overrides how long the model stays loaded
+// after the warm-up (Go duration string, e.g. "5m" or "1h"; "-1s" pins
+// the model until Ollama restarts)
+type WarmupRequest struct {
+	KeepAlive string `json:"keep_alive"`
+}
+
+// LoadModelHandler pre-loads a model into memory by issuing an empty
+// generate request, eliminating the cold-start pause on the first real
+// message (e.g. triggered when the user opens a chat)
+func (s *OllamaService) LoadModelHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req WarmupRequest
+		if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+
+		keepAlive := defaultWarmupKeepAlive
+		if req.KeepAlive != "" {
+			parsed, err := time.ParseDuration(req.KeepAlive)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid keep_alive: " + err.Error()})
+				return
+			}
+			keepAlive = parsed
+		}
+
+		// An empty prompt makes Ollama load the model without generating
+		genReq := &ollamaapi.GenerateRequest{
+			Model:     c.Param("name"),
+			KeepAlive: &ollamaapi.Duration{Duration: keepAlive},
+		}
+
+		start := time.Now()
+		var loaded bool
+		err := s.client.Generate(c.Request.Context(), genReq, func(resp ollamaapi.GenerateResponse) error {
+			loaded = loaded || resp.Done
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to load model: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"model":      c.Param("name"),
+			"loaded":     loaded,
+			"load_ms":    time.Since(start).Milliseconds(),
+			"keep_alive": keepAlive.String(),
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WeatherCurrent is the current-conditions block of a weather response
+type WeatherCurrent struct {
+	TemperatureC float64 `json:"temperatureC"`
+	FeelsLikeC   float64 `json:"feelsLikeC"`
+	Humidity     float64 `json:"humidity"`
+	WindSpeedKmh float64 `json:"windSpeedKmh"`
+	WeatherCode  int     `json:"weatherCode"`
+	Description  string  `json:"description"`
+}
+
+// WeatherDay is one day of forecast
+type WeatherDay struct {
+	Date                string  `json:"date"`
+	WeatherCode         int     `json:"weatherCode"`
+	Description         string  `json:"description"`
+	MinC                float64 `json:"minC"`
+	MaxC                float64 `json:"maxC"`
+	PrecipitationChance int     `json:"precipitationChance"`
+}
+
+// WeatherResponse is the stable schema served to both the UI and the
+// model tool layer
+type WeatherResponse struct {
+	Location LocationResponse `json:"location"`
+	Current  WeatherCurrent   `json:"current"`
+	Forecast []WeatherDay     `json:"forecast"`
+}
+
+// weatherCodeDescriptions maps WMO weather interpretation codes (used by
+// Open-Meteo) to human-readable text
+var weatherCodeDescriptions = map[int]string{
+	0:  "Clear sky",
+	1:  "Mainly clear",
+	2:  "Partly cloudy",
+	3:  "Overcast",
+	45: "Fog",
+	48: "Depositing rime fog",
+	51: "Light drizzle",
+	53: "Moderate drizzle",
+	55: "Dense drizzle",
+	56: "Light freezing drizzle",
+	57: "Dense freezing drizzle",
+	61: "Slight rain",
+	63: "Moderate rain",
+	65: "Heavy rain",
+	66: "Light freezing rain",
+	67: "Heavy freezing rain",
+	71: "Slight snowfall",
+	73: "Moderate snowfall",
+	75: "Heavy snowfall",
+	77: "Snow grains",
+	80: "Slight rain showers",
+	81: "Moderate rain showers",
+	82: "Violent rain showers",
+	85: "Slight snow showers",
+	86: "Heavy snow showers",
+	95: "Thunderstorm",
+	96: "Thunderstorm with slight hail",
+	99: "Thunderstorm with heavy hail",
+}
+
+func weatherDescription(code int) string {
+	if description, ok := weatherCodeDescriptions[code]; ok {
+		return description
+	}
+	return "Unknown"
+}
+
+// openMeteoResponse mirrors the Open-Meteo forecast payload
+type openMeteoResponse struct {
+	Current struct {
+		Temperature float64 `json:"temperature_2m"`
+		FeelsLike   float64 `json:"apparent_temperature"`
+		Humidity    float64 `json:"relative_humidity_2m"`
+		WindSpeed   float64 `json:"wind_speed_10m"`
+		WeatherCode int     `json:"weather_code"`
+	} `json:"current"`
+	Daily struct {
+		Time              []string  `json:"time"`
+		WeatherCode       []int     `json:"weather_code"`
+		TemperatureMax    []float64 `json:"temperature_2m_max"`
+		TemperatureMin    []float64 `json:"temperature_2m_min"`
+		PrecipProbability []int     `json:"precipitation_probability_max"`
+	} `json:"daily"`
+}
+
+// weatherCacheTTL keeps repeated weather requests for the same place from
+// hammering Open-Meteo; conditions don't change minute to minute
+const weatherCacheTTL = 10 * time.Minute
+
+type weatherCacheEntry struct {
+	response  WeatherResponse
+	expiresAt time.Time
+}
+
+var weatherCache = struct {
+	mu      sync.Mutex
+	entries map[string]weatherCacheEntry
+}{entries: make(map[string]weatherCacheEntry)}
+
+// WeatherHandler serves current conditions and a 5-day forecast from
+// Open-Meteo. Coordinates come from lat/lon query parameters when the
+// browser provided them, otherwise from IP-based geolocation.
+func WeatherHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var location *LocationResponse
+
+		latStr, lonStr := c.Query("lat"), c.Query("lon")
+		if latStr != "" && lonStr != "" {
+			lat, latErr := strconv.ParseFloat(latStr, 64)
+			lon, lonErr := strconv.ParseFloat(lonStr, 64)
+			if latErr != nil || lonErr != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid coordinates"})
+				return
+			}
+			location = &LocationResponse{Success: true, Latitude: lat, Longitude: lon, Source: "client"}
+		} else {
+			resolved, err := locateByIP(c)
+			if err != nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "could not determine location: " + err.Error()})
+				return
+			}
+			location = resolved
+		}
+
+		cacheKey := fmt.Sprintf("%.2f,%.2f", location.Latitude, location.Longitude)
+		weatherCache.mu.Lock()
+		if entry, ok := weatherCache.entries[cacheKey]; ok && time.Now().Before(entry.expiresAt) {
+			weatherCache.mu.Unlock()
+			response := entry.response
+			response.Location = *location
+			c.JSON(http.StatusOK, response)
+			return
+		}
+		weatherCache.mu.Unlock()
+
+		url := fmt.Sprintf(
+			"https://api.open-meteo.com/v1/forecast?latitude=%.4f&longitude=%.4f"+
+				"&current=temperature_2m,apparent_temperature,relative_humidity_2m,wind_speed_10m,weather_code"+
+				"&daily=weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max"+
+				"&timezone=auto&forecast_days=5",
+			location.Latitude, location.Longitude,
+		)
+
+		req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, url, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		resp, err := geoHTTPClient.Do(req)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "failed to reach weather service: " + err.Error()})
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("weather service returned HTTP %d", resp.StatusCode)})
+			return
+		}
+
+		var meteo openMeteoResponse
+		if err := json.NewDecoder(resp.Body).Decode(&meteo); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse weather response"})
+			return
+		}
+
+		response := WeatherResponse{
+			Location: *location,
+			Current: WeatherCurrent{
+				TemperatureC: meteo.Current.Temperature,
+				FeelsLikeC:   meteo.Current.FeelsLike,
+				Humidity:     meteo.Current.Humidity,
+				WindSpeedKmh: meteo.Current.WindSpeed,
+				WeatherCode:  meteo.Current.WeatherCode,
+				Description:  weatherDescription(meteo.Current.WeatherCode),
+			},
+		}
+		for i, date := range meteo.Daily.Time {
+			day := WeatherDay{Date: date}
+			if i < len(meteo.Daily.WeatherCode) {
+				day.WeatherCode = meteo.Daily.WeatherCode[i]
+				day.Description = weatherDescription(day.WeatherCode)
+			}
+			if i < len(meteo.Daily.TemperatureMin) {
+				day.MinC = meteo.Daily.TemperatureMin[i]
+			}
+			if i < len(meteo.Daily.TemperatureMax) {
+				day.MaxC = meteo.Daily.TemperatureMax[i]
+			}
+			if i < len(meteo.Daily.PrecipProbability) {
+				day.PrecipitationChance = meteo.Daily.PrecipProbability[i]
+			}
+			response.Forecast = append(response.Forecast, day)
+		}
+
+		weatherCache.mu.Lock()
+		weatherCache.entries[cacheKey] = weatherCacheEntry{response: response, expiresAt: time.Now().Add(weatherCacheTTL)}
+		weatherCache.mu.Unlock()
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/models"
+)
+
+// webhookRequest is the request body for creating or updating a webhook
+type webhookRequest struct {
+	URL     string   `json:"url" binding:"required"`
+	Secret  string   `json:"secret"`
+	Events  []string `json:"events"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// knownEvents is the set of event names webhooks can subscribe to
+var knownEvents = []string{
+	EventChatCreated,
+	EventMessageCompleted,
+	EventModelPullFinished,
+	EventSyncPushed,
+	EventBackupCompleted,
+}
+
+// validateEvents rejects event filters naming events that don't exist -
+// almost always a typo that would silently drop every notification
+func validateEvents(events []string) string {
+	for _, event := range events {
+		known := false
+		for _, candidate := range knownEvents {
+			if event == candidate {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return event
+		}
+	}
+	return ""
+}
+
+// ListWebhooksHandler returns all configured webhooks and the available
+// event names (for building subscription UIs)
+func ListWebhooksHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhooks, err := models.ListWebhooks(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if webhooks == nil {
+			webhooks = []models.Webhook{}
+		}
+		c.JSON(http.StatusOK, gin.H{"webhooks": webhooks, "events": knownEvents})
+	}
+}
+
+// CreateWebhookHandler registers a new webhook
+func CreateWebhookHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req webhookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+		if !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "url must be an http(s) URL"})
+			return
+		}
+		if unknown := validateEvents(req.Events); unknown != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown event: " + unknown})
+			return
+		}
+
+		webhook := &models.Webhook{
+			URL:     req.URL,
+			Secret:  req.Secret,
+			Events:  req.Events,
+			Enabled: req.Enabled == nil || *req.Enabled,
+		}
+		if err := models.CreateWebhook(db, webhook); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, webhook)
+	}
+}
+
+// UpdateWebhookHandler updates a webhook's URL, secret, events, or enabled flag
+func UpdateWebhookHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhook, err := models.GetWebhook(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if webhook == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+			return
+		}
+
+		var req webhookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+		if !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "url must be an http(s) URL"})
+			return
+		}
+		if unknown := validateEvents(req.Events); unknown != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown event: " + unknown})
+			return
+		}
+
+		webhook.URL = req.URL
+		webhook.Secret = req.Secret
+		webhook.Events = req.Events
+		if req.Enabled != nil {
+			webhook.Enabled = *req.Enabled
+		}
+
+		if err := models.UpdateWebhook(db, webhook); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, webhook)
+	}
+}
+
+// DeleteWebhookHandler deletes a webhook and its delivery log
+func DeleteWebhookHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.DeleteWebhook(db, c.Param("id")); err != nil {
+			if err.Error() == "webhook not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "webhook deleted"})
+	}
+}
+
+// ListWebhookDeliveriesHandler returns the recent delivery log for a webhook
+func ListWebhookDeliveriesHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 0
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+				limit = l
+			}
+		}
+
+		deliveries, err := models.ListWebhookDeliveries(db, c.Param("id"), limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if deliveries == nil {
+			deliveries = []models.WebhookDelivery{}
+		}
+		c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+	}
+}
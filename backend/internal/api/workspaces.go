@@ -0,0 +1,187 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"vessel-backend/internal/models"
+)
+
+// workspaceRequest is the request body for creating or updating a workspace
+type workspaceRequest struct {
+	Name           string          `json:"name" binding:"required"`
+	Description    string          `json:"description"`
+	ModelAllowlist []string        `json:"model_allowlist"`
+	Settings       json.RawMessage `json:"settings"`
+}
+
+// ListWorkspacesHandler returns all workspaces
+func ListWorkspacesHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspaces, err := models.ListWorkspaces(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if workspaces == nil {
+			workspaces = []models.Workspace{}
+		}
+		c.JSON(http.StatusOK, gin.H{"workspaces": workspaces})
+	}
+}
+
+// CreateWorkspaceHandler creates a new workspace
+func CreateWorkspaceHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req workspaceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		workspace := &models.Workspace{
+			Name:           req.Name,
+			Description:    req.Description,
+			ModelAllowlist: req.ModelAllowlist,
+			Settings:       req.Settings,
+		}
+		if err := models.CreateWorkspace(db, workspace); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, workspace)
+	}
+}
+
+// GetWorkspaceHandler returns one workspace with its members
+func GetWorkspaceHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspace, err := models.GetWorkspace(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if workspace == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "workspace not found"})
+			return
+		}
+
+		members, err := models.ListWorkspaceMembers(db, workspace.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if members == nil {
+			members = []models.WorkspaceMember{}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"workspace": workspace, "members": members})
+	}
+}
+
+// UpdateWorkspaceHandler updates a workspace
+func UpdateWorkspaceHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspace, err := models.GetWorkspace(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if workspace == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "workspace not found"})
+			return
+		}
+
+		var req workspaceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		workspace.Name = req.Name
+		workspace.Description = req.Description
+		workspace.ModelAllowlist = req.ModelAllowlist
+		workspace.Settings = req.Settings
+
+		if err := models.UpdateWorkspace(db, workspace); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, workspace)
+	}
+}
+
+// DeleteWorkspaceHandler deletes a workspace (its chats stay)
+func DeleteWorkspaceHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.DeleteWorkspace(db, c.Param("id")); err != nil {
+			if err.Error() == "workspace not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "workspace not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "workspace deleted"})
+	}
+}
+
+// workspaceMemberRequest is the body for adding a member
+type workspaceMemberRequest struct {
+	User string `json:"user" binding:"required"`
+	Role string `json:"role"`
+}
+
+// AddWorkspaceMemberHandler adds (or re-roles) a member
+func AddWorkspaceMemberHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspace, err := models.GetWorkspace(db, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if workspace == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "workspace not found"})
+			return
+		}
+
+		var req workspaceMemberRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+		if req.Role != "" && req.Role != "owner" && req.Role != "member" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "role must be 'owner' or 'member'"})
+			return
+		}
+
+		member := &models.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			User:        req.User,
+			Role:        req.Role,
+		}
+		if err := models.AddWorkspaceMember(db, member); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, member)
+	}
+}
+
+// RemoveWorkspaceMemberHandler removes a member
+func RemoveWorkspaceMemberHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := models.RemoveWorkspaceMember(db, c.Param("id"), c.Param("user")); err != nil {
+			if err.Error() == "member not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+	}
+}
@@ -0,0 +1,521 @@
+// Package config provides layered configuration for the server: built-in
+// defaults, overridden by an optional YAML config file, overridden by
+// environment variables. CLI flags in main layer on top of all of these.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ServerConfig covers the HTTP server itself. trusted_proxies lists the
+// CIDRs (or single IPs) whose X-Forwarded-For headers are believed when
+// resolving the client IP; unset, only private and loopback peers are
+// trusted. allow_ips/deny_ips gate the whole API by client IP - deny
+// wins, and an empty allow list means "allow everyone not denied".
+type ServerConfig struct {
+	Port           string    `yaml:"port"`
+	CORSOrigins    []string  `yaml:"cors_origins"`
+	TLS            TLSConfig `yaml:"tls"`
+	TrustedProxies []string  `yaml:"trusted_proxies"`
+	AllowIPs       []string  `yaml:"allow_ips"`
+	DenyIPs        []string  `yaml:"deny_ips"`
+}
+
+// TLSConfig covers native HTTPS serving. Either a cert/key pair or an
+// autocert domain enables TLS; autocert takes precedence when both are set.
+type TLSConfig struct {
+	CertFile         string `yaml:"cert_file"`
+	KeyFile          string `yaml:"key_file"`
+	AutocertDomain   string `yaml:"autocert_domain"`
+	AutocertCacheDir string `yaml:"autocert_cache_dir"`
+	RedirectHTTP     bool   `yaml:"redirect_http"`
+}
+
+// DatabaseConfig covers the SQLite database. With encrypt_content on,
+// message content and attachment data are encrypted at rest (AES-GCM,
+// keyed from ENCRYPTION_KEY or the key file); note that local search
+// cannot match inside encrypted rows.
+type DatabaseConfig struct {
+	Path           string `yaml:"path"`
+	EncryptContent bool   `yaml:"encrypt_content"`
+}
+
+// OllamaConfig covers the Ollama backend connection
+type OllamaConfig struct {
+	URL     string              `yaml:"url"`
+	Cache   ResponseCacheConfig `yaml:"cache"`
+	Pull    PullConfig          `yaml:"pull"`
+	Streams StreamLimitConfig   `yaml:"streams"`
+}
+
+// StreamLimitConfig caps simultaneous streaming generations so one
+// client opening twenty tabs can't starve the Ollama backend for
+// everyone else. max_concurrent is the global ceiling, max_per_client
+// the per-address one; 0 disables either check. Requests over a limit
+// get 429 with the live counts.
+type StreamLimitConfig struct {
+	MaxConcurrent int `yaml:"max_concurrent"`
+	MaxPerClient  int `yaml:"max_per_client"`
+}
+
+// PullConfig throttles model downloads run through the job queue, so a
+// scheduled pull doesn't saturate a home connection during the day.
+// max_bytes_per_second caps the average download rate (0 = unlimited);
+// window_start/window_end ("HH:MM", may wrap midnight) restrict pulls
+// to that daily window - jobs landing outside it are deferred to the
+// next opening. Pulls started interactively are not affected.
+type PullConfig struct {
+	MaxBytesPerSecond int64  `yaml:"max_bytes_per_second"`
+	WindowStart       string `yaml:"window_start"`
+	WindowEnd         string `yaml:"window_end"`
+}
+
+// ResponseCacheConfig covers the in-memory cache for non-streaming
+// generate and embed responses. Identical requests (same model, prompt,
+// and options) within the TTL are answered from memory instead of
+// re-running the model, which matters for automations and RAG pipelines
+// that repeat prompts. Off by default; streamed chat is never cached.
+type ResponseCacheConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	TTLSeconds int  `yaml:"ttl_seconds"`
+	MaxEntries int  `yaml:"max_entries"`
+}
+
+// ProvisioningConfig pins the models a deployment must have pulled,
+// for reproducible setups driven entirely from config. Each entry is a
+// model name with tag (e.g. "llama3.2:3b"; a bare name means ":latest").
+// With pull_missing on, absent models are pulled through the job queue
+// at startup; either way GET /models/provisioning reports the drift
+// between the list and what Ollama actually has.
+type ProvisioningConfig struct {
+	Models      []string `yaml:"models"`
+	PullMissing bool     `yaml:"pull_missing"`
+}
+
+// FetcherConfig covers the URL fetcher
+type FetcherConfig struct {
+	ProxyURL  string         `yaml:"proxy_url"`
+	MaxLength int            `yaml:"max_length"`
+	JSRender  JSRenderConfig `yaml:"js_render"`
+}
+
+// JSRenderConfig tunes the heuristics that decide whether a fetched page
+// looks JS-rendered and deserves a second pass through headless Chrome.
+// Pages shorter than min_content_length are assumed unrendered; when a
+// framework marker is found, pages with less extracted text than
+// min_text_length are re-rendered, as are pages mentioning a known
+// JS-heavy site with less text than min_site_text_length. Setting a
+// threshold to 0 disables that check. sites replaces the built-in
+// JS-heavy site list (an empty list disables it); disabled turns the
+// whole fallback off.
+type JSRenderConfig struct {
+	Disabled          bool     `yaml:"disabled"`
+	MinContentLength  int      `yaml:"min_content_length"`
+	MinTextLength     int      `yaml:"min_text_length"`
+	MinSiteTextLength int      `yaml:"min_site_text_length"`
+	Sites             []string `yaml:"sites"`
+}
+
+// SearchConfig covers the web search proxy
+type SearchConfig struct {
+	CacheTTLSeconds int `yaml:"cache_ttl_seconds"`
+}
+
+// UpdatesConfig covers release update checks. Channel selects which
+// GitHub releases count: "stable" (default) ignores pre-releases,
+// "prerelease" includes them.
+type UpdatesConfig struct {
+	Channel string `yaml:"channel"`
+}
+
+// ModelPricing is the price per million tokens for one model. Local
+// models cost nothing; entries here are for cloud-hosted or otherwise
+// paid models so usage can carry cost estimates.
+type ModelPricing struct {
+	InputPerMTok  float64 `yaml:"input_per_mtok"`
+	OutputPerMTok float64 `yaml:"output_per_mtok"`
+}
+
+// CostsConfig maps model names (exact, or the base name before the tag)
+// to their pricing
+type CostsConfig struct {
+	Models map[string]ModelPricing `yaml:"models"`
+}
+
+// WhisperConfig covers speech-to-text. Either an external Whisper server
+// (server_url points at an OpenAI-compatible /audio/transcriptions
+// endpoint) or a local whisper.cpp binary plus model file can be used;
+// the server takes precedence when both are set.
+type WhisperConfig struct {
+	ServerURL string `yaml:"server_url"`
+	Binary    string `yaml:"binary"`
+	ModelPath string `yaml:"model_path"`
+}
+
+// TranslateConfig covers the translation endpoint. Model names the local
+// model used for translations when a request does not pick one itself.
+type TranslateConfig struct {
+	Model string `yaml:"model"`
+}
+
+// TelegramConfig covers the optional Telegram bot bridge. The bridge only
+// starts when bot_token is set; allowed_chats restricts who may talk to
+// the bot (empty allows everyone, which is rarely what you want for a bot
+// wired into a home LLM).
+type TelegramConfig struct {
+	BotToken     string  `yaml:"bot_token"`
+	Model        string  `yaml:"model"`
+	AllowedChats []int64 `yaml:"allowed_chats"`
+}
+
+// NotificationsConfig covers outbound notifications to self-hosters.
+// Either target (or both) can be set; nothing is sent when both are empty.
+type NotificationsConfig struct {
+	WebhookURL        string `yaml:"webhook_url"`
+	NtfyTopic         string `yaml:"ntfy_topic"`
+	NtfyServer        string `yaml:"ntfy_server"`
+	DiscordWebhookURL string `yaml:"discord_webhook_url"`
+	SlackWebhookURL   string `yaml:"slack_webhook_url"`
+}
+
+// GuardrailRule is one pattern-based completion filter. Action decides
+// what a match does: "block" rejects the request/response, "redact"
+// replaces matched text (with replacement, default "[redacted]"), and
+// "flag" records it for review but lets it through.
+type GuardrailRule struct {
+	Name        string `yaml:"name"`
+	Pattern     string `yaml:"pattern"`
+	Action      string `yaml:"action"`
+	Replacement string `yaml:"replacement"`
+}
+
+// GuardrailsConfig covers the optional completion moderation pipeline.
+// Rules run on prompts and responses; moderation_model, when set, also
+// asks that local model to classify prompts (moderation_action: "block"
+// or "flag", default "flag").
+type GuardrailsConfig struct {
+	Enabled          bool            `yaml:"enabled"`
+	Rules            []GuardrailRule `yaml:"rules"`
+	ModerationModel  string          `yaml:"moderation_model"`
+	ModerationAction string          `yaml:"moderation_action"`
+}
+
+// PluginsConfig covers the external plugin system. Dir is scanned for
+// plugin subdirectories (each with a plugin.yaml manifest) at startup;
+// empty disables plugins entirely.
+type PluginsConfig struct {
+	Dir string `yaml:"dir"`
+}
+
+// EmailConfig covers outbound email over SMTP. Digest enables the
+// scheduled activity summary ("daily" or "weekly"); digest_to is where it
+// goes and digest_hour (0-23, local time) when. The SMTP settings double
+// as the transport for any future transactional mail.
+type EmailConfig struct {
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+	From         string `yaml:"from"`
+	Digest       string `yaml:"digest"`
+	DigestTo     string `yaml:"digest_to"`
+	DigestHour   int    `yaml:"digest_hour"`
+}
+
+// IntegrationsConfig covers inbound chat-platform bridges. The Slack
+// slash-command endpoint only verifies requests when a signing secret is
+// set; without one it accepts anything, which is fine behind a private
+// network but not on the open internet.
+type IntegrationsConfig struct {
+	SlackSigningSecret string `yaml:"slack_signing_secret"`
+}
+
+// GeoIPConfig covers offline IP geolocation. With an mmdb_path set, the
+// /location endpoint answers from a local GeoLite2/GeoIP2 City database
+// instead of calling ip-api.com; download_url enables fetching the file
+// when missing (or older than refresh_days). The online provider is only
+// used when online_fallback is true.
+type GeoIPConfig struct {
+	MMDBPath       string `yaml:"mmdb_path"`
+	DownloadURL    string `yaml:"download_url"`
+	RefreshDays    int    `yaml:"refresh_days"`
+	OnlineFallback bool   `yaml:"online_fallback"`
+}
+
+// PrivacyConfig covers air-gapped and privacy-sensitive deployments.
+// With offline_mode on, the backend makes no outbound calls of its own
+// (ollama.com scraping, GitHub release checks, geolocation, web search);
+// the affected endpoints return explicit "disabled by policy" responses.
+type PrivacyConfig struct {
+	OfflineMode  bool               `yaml:"offline_mode"`
+	PIIRedaction PIIRedactionConfig `yaml:"pii_redaction"`
+}
+
+// PIIRedactionConfig controls scrubbing of text before it leaves the
+// machine (web search, image search, research queries). Built-in detectors
+// cover emails, phone numbers, and common API key formats; extra_patterns
+// adds deployment-specific regexes. Action is "redact" (default, replaces
+// matches with a placeholder) or "warn" (leaves the text alone but logs
+// what would have been stripped).
+type PIIRedactionConfig struct {
+	Enabled       bool     `yaml:"enabled"`
+	Action        string   `yaml:"action"`
+	ExtraPatterns []string `yaml:"extra_patterns"`
+}
+
+// LoggingConfig covers structured logging output
+type LoggingConfig struct {
+	Level  string `yaml:"level"`  // "debug", "info" (default), "warn", "error"
+	Format string `yaml:"format"` // "text" (default) or "json"
+}
+
+// Config is the root configuration object
+type Config struct {
+	Server        ServerConfig        `yaml:"server"`
+	Database      DatabaseConfig      `yaml:"database"`
+	Ollama        OllamaConfig        `yaml:"ollama"`
+	Provisioning  ProvisioningConfig  `yaml:"provisioning"`
+	Fetcher       FetcherConfig       `yaml:"fetcher"`
+	Search        SearchConfig        `yaml:"search"`
+	Updates       UpdatesConfig       `yaml:"updates"`
+	Costs         CostsConfig         `yaml:"costs"`
+	Whisper       WhisperConfig       `yaml:"whisper"`
+	Translate     TranslateConfig     `yaml:"translate"`
+	Telegram      TelegramConfig      `yaml:"telegram"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Integrations  IntegrationsConfig  `yaml:"integrations"`
+	Email         EmailConfig         `yaml:"email"`
+	Plugins       PluginsConfig       `yaml:"plugins"`
+	Guardrails    GuardrailsConfig    `yaml:"guardrails"`
+	GeoIP         GeoIPConfig         `yaml:"geoip"`
+	Privacy       PrivacyConfig       `yaml:"privacy"`
+	Logging       LoggingConfig       `yaml:"logging"`
+}
+
+// current holds the loaded configuration; defaults until Load is called
+var current = Default()
+
+// Default returns the built-in default configuration
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:        "8080",
+			CORSOrigins: []string{"*"},
+		},
+		Database: DatabaseConfig{
+			Path: "./data/vessel.db",
+		},
+		Ollama: OllamaConfig{
+			URL: "http://localhost:11434",
+			Cache: ResponseCacheConfig{
+				TTLSeconds: 300,
+				MaxEntries: 256,
+			},
+			Streams: StreamLimitConfig{
+				MaxConcurrent: 8,
+				MaxPerClient:  4,
+			},
+		},
+		Fetcher: FetcherConfig{
+			MaxLength: 500000,
+			JSRender: JSRenderConfig{
+				MinContentLength:  500,
+				MinTextLength:     1000,
+				MinSiteTextLength: 2000,
+			},
+		},
+		Search: SearchConfig{
+			CacheTTLSeconds: 300,
+		},
+		Updates: UpdatesConfig{
+			Channel: "stable",
+		},
+		Email: EmailConfig{
+			SMTPPort:   587,
+			DigestHour: 8,
+		},
+		GeoIP: GeoIPConfig{
+			OnlineFallback: true,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+}
+
+// Load reads the config file at path (if it exists) and applies environment
+// variable overrides. The result becomes the active configuration returned
+// by Get. A missing file is only an error when the path was set explicitly.
+func Load(path string, explicit bool) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) || explicit {
+				return nil, fmt.Errorf("failed to read config file: %w", err)
+			}
+		} else if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+
+	applyEnvOverrides(cfg)
+
+	// Resolve enc:v1: and cmd: secret references wherever they appear
+	if err := resolveSecrets(cfg); err != nil {
+		return nil, err
+	}
+
+	current = cfg
+	return cfg, nil
+}
+
+// Get returns the active configuration
+func Get() *Config {
+	return current
+}
+
+// applyEnvOverrides layers environment variables over the file values
+func applyEnvOverrides(cfg *Config) {
+	if value := os.Getenv("PORT"); value != "" {
+		cfg.Server.Port = value
+	}
+	if value := os.Getenv("CORS_ORIGINS"); value != "" {
+		var origins []string
+		for _, origin := range strings.Split(value, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+		if len(origins) > 0 {
+			cfg.Server.CORSOrigins = origins
+		}
+	}
+	if value := os.Getenv("TLS_CERT"); value != "" {
+		cfg.Server.TLS.CertFile = value
+	}
+	if value := os.Getenv("TLS_KEY"); value != "" {
+		cfg.Server.TLS.KeyFile = value
+	}
+	if value := os.Getenv("AUTOCERT_DOMAIN"); value != "" {
+		cfg.Server.TLS.AutocertDomain = value
+	}
+	if value := os.Getenv("DB_PATH"); value != "" {
+		cfg.Database.Path = value
+	}
+	if value := os.Getenv("DB_ENCRYPT_CONTENT"); value != "" {
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			cfg.Database.EncryptContent = enabled
+		}
+	}
+	if value := os.Getenv("OLLAMA_URL"); value != "" {
+		cfg.Ollama.URL = value
+	}
+	if value := os.Getenv("PROXY_URL"); value != "" {
+		cfg.Fetcher.ProxyURL = value
+	}
+	if value := os.Getenv("FETCH_MAX_LENGTH"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			cfg.Fetcher.MaxLength = n
+		}
+	}
+	if value := os.Getenv("SEARCH_CACHE_TTL"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			cfg.Search.CacheTTLSeconds = n
+		}
+	}
+	if value := os.Getenv("UPDATE_CHANNEL"); value != "" {
+		cfg.Updates.Channel = value
+	}
+	if value := os.Getenv("WHISPER_SERVER_URL"); value != "" {
+		cfg.Whisper.ServerURL = value
+	}
+	if value := os.Getenv("WHISPER_BINARY"); value != "" {
+		cfg.Whisper.Binary = value
+	}
+	if value := os.Getenv("WHISPER_MODEL"); value != "" {
+		cfg.Whisper.ModelPath = value
+	}
+	if value := os.Getenv("TRANSLATE_MODEL"); value != "" {
+		cfg.Translate.Model = value
+	}
+	if value := os.Getenv("TELEGRAM_BOT_TOKEN"); value != "" {
+		cfg.Telegram.BotToken = value
+	}
+	if value := os.Getenv("TELEGRAM_MODEL"); value != "" {
+		cfg.Telegram.Model = value
+	}
+	if value := os.Getenv("WEBHOOK_URL"); value != "" {
+		cfg.Notifications.WebhookURL = value
+	}
+	if value := os.Getenv("NTFY_TOPIC"); value != "" {
+		cfg.Notifications.NtfyTopic = value
+	}
+	if value := os.Getenv("NTFY_SERVER"); value != "" {
+		cfg.Notifications.NtfyServer = value
+	}
+	if value := os.Getenv("DISCORD_WEBHOOK_URL"); value != "" {
+		cfg.Notifications.DiscordWebhookURL = value
+	}
+	if value := os.Getenv("SLACK_WEBHOOK_URL"); value != "" {
+		cfg.Notifications.SlackWebhookURL = value
+	}
+	if value := os.Getenv("SLACK_SIGNING_SECRET"); value != "" {
+		cfg.Integrations.SlackSigningSecret = value
+	}
+	if value := os.Getenv("PLUGINS_DIR"); value != "" {
+		cfg.Plugins.Dir = value
+	}
+	if value := os.Getenv("SMTP_HOST"); value != "" {
+		cfg.Email.SMTPHost = value
+	}
+	if value := os.Getenv("SMTP_PORT"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			cfg.Email.SMTPPort = n
+		}
+	}
+	if value := os.Getenv("SMTP_USERNAME"); value != "" {
+		cfg.Email.SMTPUsername = value
+	}
+	if value := os.Getenv("SMTP_PASSWORD"); value != "" {
+		cfg.Email.SMTPPassword = value
+	}
+	if value := os.Getenv("EMAIL_FROM"); value != "" {
+		cfg.Email.From = value
+	}
+	if value := os.Getenv("EMAIL_DIGEST"); value != "" {
+		cfg.Email.Digest = value
+	}
+	if value := os.Getenv("EMAIL_DIGEST_TO"); value != "" {
+		cfg.Email.DigestTo = value
+	}
+	if value := os.Getenv("GEOIP_MMDB"); value != "" {
+		cfg.GeoIP.MMDBPath = value
+	}
+	if value := os.Getenv("GEOIP_DOWNLOAD_URL"); value != "" {
+		cfg.GeoIP.DownloadURL = value
+	}
+	if value := os.Getenv("GEOIP_ONLINE_FALLBACK"); value != "" {
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			cfg.GeoIP.OnlineFallback = enabled
+		}
+	}
+	if value := os.Getenv("OFFLINE_MODE"); value != "" {
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			cfg.Privacy.OfflineMode = enabled
+		}
+	}
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		cfg.Logging.Level = value
+	}
+	if value := os.Getenv("LOG_FORMAT"); value != "" {
+		cfg.Logging.Format = value
+	}
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os/exec"
+	"reflect"
+	"strings"
+
+	"vessel-backend/internal/crypto"
+)
+
+// Secret references in config values. "enc:v1:..." holds a value sealed
+// with the server's encryption key (same format as encrypted database
+// content; produce one with `vessel -encrypt-secret`). "cmd:..." runs a
+// shell command at load time and uses its trimmed output, for secret
+// managers like pass or vault CLIs.
+const (
+	encryptedValuePrefix = "enc:v1:"
+	commandValuePrefix   = "cmd:"
+)
+
+// EncryptValue seals a plaintext config value into the enc:v1: form,
+// for the -encrypt-secret CLI helper
+func EncryptValue(plaintext string) (string, error) {
+	sealed, err := crypto.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return encryptedValuePrefix + sealed, nil
+}
+
+// resolveSecretValue turns a secret reference into its plaintext; plain
+// values pass through untouched
+func resolveSecretValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, encryptedValuePrefix):
+		plaintext, err := crypto.Decrypt(strings.TrimPrefix(value, encryptedValuePrefix))
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt config secret: %w", err)
+		}
+		return string(plaintext), nil
+	case strings.HasPrefix(value, commandValuePrefix):
+		command := strings.TrimPrefix(value, commandValuePrefix)
+		output, err := exec.Command("sh", "-c", command).Output()
+		if err != nil {
+			return "", fmt.Errorf("config secret command failed: %w", err)
+		}
+		return strings.TrimSpace(string(output)), nil
+	default:
+		return value, nil
+	}
+}
+
+// resolveSecrets walks every string field of the config and resolves
+// secret references in place, so the rest of the server only ever sees
+// plaintext. Failing loudly here beats a half-configured server quietly
+// sending an "enc:v1:..." literal as an SMTP password.
+func resolveSecrets(cfg *Config) error {
+	return resolveSecretFields(reflect.ValueOf(cfg).Elem())
+}
+
+// resolveSecretFields recurses through structs and slices resolving
+// string values
+func resolveSecretFields(value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.String:
+		resolved, err := resolveSecretValue(value.String())
+		if err != nil {
+			return err
+		}
+		value.SetString(resolved)
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if !value.Field(i).CanSet() {
+				continue
+			}
+			if err := resolveSecretFields(value.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			if err := resolveSecretFields(value.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package crypto provides symmetric encryption for secrets stored in the
+// database (auth profiles, provider API keys). The key comes from the
+// ENCRYPTION_KEY environment variable, or is auto-generated and persisted
+// to a key file on first use.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	keyOnce sync.Once
+	key     []byte
+	keyErr  error
+)
+
+// keyFilePath returns where the auto-generated key is persisted
+func keyFilePath() string {
+	if path := os.Getenv("ENCRYPTION_KEY_FILE"); path != "" {
+		return path
+	}
+	return "./data/vessel.key"
+}
+
+// loadKey resolves the 32-byte AES key. ENCRYPTION_KEY takes precedence
+// (any string, hashed to 32 bytes); otherwise a random key is generated
+// and persisted to the key file so secrets survive restarts.
+func loadKey() ([]byte, error) {
+	if k := os.Getenv("ENCRYPTION_KEY"); k != "" {
+		sum := sha256.Sum256([]byte(k))
+		return sum[:], nil
+	}
+
+	path := keyFilePath()
+	if data, err := os.ReadFile(path); err == nil && len(data) >= 32 {
+		return data[:32], nil
+	}
+
+	k := make([]byte, 32)
+	if _, err := rand.Read(k); err != nil {
+		return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %w", err)
+	}
+	if err := os.WriteFile(path, k, 0600); err != nil {
+		return nil, fmt.Errorf("failed to persist encryption key: %w", err)
+	}
+	return k, nil
+}
+
+func getKey() ([]byte, error) {
+	keyOnce.Do(func() {
+		key, keyErr = loadKey()
+	})
+	return key, keyErr
+}
+
+// Encrypt seals plaintext with AES-256-GCM and returns base64(nonce|ciphertext)
+func Encrypt(plaintext []byte) (string, error) {
+	k, err := getKey()
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt
+func Decrypt(encoded string) ([]byte, error) {
+	k, err := getKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
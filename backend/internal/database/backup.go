@@ -0,0 +1,93 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+// BackupTo writes a consistent snapshot of the live database to destPath
+// using VACUUM INTO, which works under WAL without blocking writers and
+// produces a compact single-file copy (attachments included, since they
+// live in the database).
+func BackupTo(db *sql.DB, destPath string) error {
+	// VACUUM INTO refuses to overwrite an existing file
+	if err := os.Remove(destPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear backup destination: %w", err)
+	}
+
+	if _, err := db.Exec(`VACUUM INTO ?`, destPath); err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	return nil
+}
+
+// ValidateBackup checks that path is a healthy SQLite database containing
+// the vessel schema, before it is allowed anywhere near the live database
+func ValidateBackup(path string) error {
+	db, err := sql.Open("sqlite", path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("backup is not a valid SQLite database: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("backup failed integrity check: %s", result)
+	}
+
+	var count int
+	err = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name IN ('chats', 'messages')`).Scan(&count)
+	if err != nil || count < 2 {
+		return fmt.Errorf("backup does not contain the vessel schema")
+	}
+	return nil
+}
+
+// StagedRestorePath is where an uploaded backup waits until the next
+// restart swaps it in
+func StagedRestorePath(dbPath string) string {
+	return dbPath + ".restore"
+}
+
+// ApplyStagedRestore swaps a staged restore file into place. Called before
+// the database is opened: the old database is kept as a .pre-restore copy
+// and stale WAL/SHM files are removed so SQLite doesn't replay old state.
+// Returns true when a restore was applied.
+func ApplyStagedRestore(dbPath string) (bool, error) {
+	staged := StagedRestorePath(dbPath)
+	if _, err := os.Stat(staged); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check staged restore: %w", err)
+	}
+
+	if err := ValidateBackup(staged); err != nil {
+		// Leave the bad file for inspection but don't block startup
+		if renameErr := os.Rename(staged, staged+".invalid"); renameErr != nil {
+			os.Remove(staged)
+		}
+		return false, fmt.Errorf("staged restore rejected: %w", err)
+	}
+
+	// Keep the current database around in case the restore was a mistake
+	if _, err := os.Stat(dbPath); err == nil {
+		if err := os.Rename(dbPath, dbPath+".pre-restore"); err != nil {
+			return false, fmt.Errorf("failed to set aside current database: %w", err)
+		}
+	}
+
+	if err := os.Rename(staged, dbPath); err != nil {
+		return false, fmt.Errorf("failed to move restore into place: %w", err)
+	}
+
+	// Stale journal files belong to the replaced database
+	os.Remove(dbPath + "-wal")
+	os.Remove(dbPath + "-shm")
+
+	return true, nil
+}
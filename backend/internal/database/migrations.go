@@ -88,6 +88,18 @@ CREATE TABLE IF NOT EXISTS remote_models (
     url TEXT NOT NULL
 );
 
+-- Saved auth profiles for fetching authenticated pages
+-- The secret column holds encrypted credential material (cookies/token/basic auth)
+CREATE TABLE IF NOT EXISTS fetch_profiles (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    domain TEXT NOT NULL,
+    auth_type TEXT NOT NULL CHECK (auth_type IN ('cookie', 'bearer', 'basic')),
+    secret TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
 -- Indexes for remote models
 CREATE INDEX IF NOT EXISTS idx_remote_models_name ON remote_models(name);
 CREATE INDEX IF NOT EXISTS idx_remote_models_model_type ON remote_models(model_type);
@@ -95,45 +107,811 @@ CREATE INDEX IF NOT EXISTS idx_remote_models_pull_count ON remote_models(pull_co
 CREATE INDEX IF NOT EXISTS idx_remote_models_scraped_at ON remote_models(scraped_at);
 `
 
-// Additional migrations for schema updates (run separately to handle existing tables)
-const additionalMigrations = `
--- Add tag_sizes column for storing file sizes per tag variant
--- This column stores a JSON object mapping tag names to file sizes in bytes
--- Example: {"8b": 4700000000, "70b": 40000000000}
-`
+// Migration is a single, ordered schema change. Up and Down are SQL
+// scripts; UpFunc can be used instead of Up when the change needs logic
+// SQLite DDL can't express (such as adding a column only when missing,
+// which matters for databases created before migrations were versioned).
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	UpFunc  func(*sql.Tx) error
+	Down    string
+}
 
-// RunMigrations executes all database migrations
-func RunMigrations(db *sql.DB) error {
-	_, err := db.Exec(migrationsSQL)
+// migrations lists every schema change in order. Append new entries with
+// the next version number; never edit or reorder released ones.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline schema",
+		Up:      migrationsSQL,
+		Down: `
+DROP TABLE IF EXISTS fetch_profiles;
+DROP TABLE IF EXISTS remote_models;
+DROP TABLE IF EXISTS attachments;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS chats;
+`,
+	},
+	{
+		Version: 2,
+		Name:    "add remote_models.tag_sizes",
+		UpFunc:  addColumnIfMissing("remote_models", "tag_sizes", `TEXT NOT NULL DEFAULT '{}'`),
+		Down:    `ALTER TABLE remote_models DROP COLUMN tag_sizes`,
+	},
+	{
+		Version: 3,
+		Name:    "add chats.system_prompt_id",
+		UpFunc:  addColumnIfMissing("chats", "system_prompt_id", "TEXT"),
+		Down:    `ALTER TABLE chats DROP COLUMN system_prompt_id`,
+	},
+	{
+		// Rows written through the SQL defaults carry 'YYYY-MM-DD HH:MM:SS'
+		// while Go writes RFC3339 ('YYYY-MM-DDTHH:MM:SSZ'); mixed formats
+		// break lexicographic ordering. Normalize everything to RFC3339 UTC
+		// and add the composite indexes the hot queries actually use.
+		Version: 4,
+		Name:    "normalize timestamps to RFC3339 and add composite indexes",
+		Up: `
+UPDATE chats SET created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at) WHERE created_at NOT LIKE '%T%';
+UPDATE chats SET updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at) WHERE updated_at NOT LIKE '%T%';
+UPDATE messages SET created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at) WHERE created_at NOT LIKE '%T%';
+UPDATE fetch_profiles SET created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at) WHERE created_at NOT LIKE '%T%';
+UPDATE fetch_profiles SET updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at) WHERE updated_at NOT LIKE '%T%';
+
+-- Chat listing: pinned first, newest activity first, usually non-archived
+CREATE INDEX IF NOT EXISTS idx_chats_pinned_updated ON chats(pinned DESC, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_chats_archived_updated ON chats(archived, updated_at DESC);
+
+-- Message history: always fetched per chat in creation order
+CREATE INDEX IF NOT EXISTS idx_messages_chat_created ON messages(chat_id, created_at);
+`,
+		Down: `
+DROP INDEX IF EXISTS idx_chats_pinned_updated;
+DROP INDEX IF EXISTS idx_chats_archived_updated;
+DROP INDEX IF EXISTS idx_messages_chat_created;
+`,
+	},
+	{
+		// Server-side user settings (default model, generation parameters,
+		// UI preferences) keyed by setting name with a JSON value, so they
+		// follow the user across browsers instead of living in localStorage.
+		Version: 5,
+		Name:    "add settings table",
+		Up: `
+CREATE TABLE IF NOT EXISTS settings (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+`,
+		Down: `DROP TABLE IF EXISTS settings`,
+	},
+	{
+		// Reusable prompt templates with {{variable}} placeholders,
+		// organized by free-form category
+		Version: 6,
+		Name:    "add prompt_templates table",
+		Up: `
+CREATE TABLE IF NOT EXISTS prompt_templates (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_prompt_templates_category ON prompt_templates(category);
+`,
+		Down: `DROP TABLE IF EXISTS prompt_templates`,
+	},
+	{
+		// Assistant personas: a named bundle of system prompt, default
+		// model, generation parameters, and enabled tools. Chats carry an
+		// optional persona_id so completions can apply the configuration.
+		Version: 7,
+		Name:    "add personas table and chats.persona_id",
+		UpFunc: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS personas (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    avatar TEXT NOT NULL DEFAULT '',
+    system_prompt TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    parameters TEXT NOT NULL DEFAULT '{}',
+    tools TEXT NOT NULL DEFAULT '[]',
+    knowledge TEXT NOT NULL DEFAULT '[]',
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+`)
+			if err != nil {
+				return err
+			}
+			return addColumnIfMissing("chats", "persona_id", "TEXT")(tx)
+		},
+		Down: `
+ALTER TABLE chats DROP COLUMN persona_id;
+DROP TABLE IF EXISTS personas;
+`,
+	},
+	{
+		// Slash commands (/summarize, /translate-to:fr) that expand to a
+		// prompt template with the selected text; stored server-side so
+		// every client shares the same set
+		Version: 8,
+		Name:    "add slash_commands table",
+		Up: `
+CREATE TABLE IF NOT EXISTS slash_commands (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    template TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+`,
+		Down: `DROP TABLE IF EXISTS slash_commands`,
+	},
+	{
+		// Per-generation usage metrics (tokens, duration, tool calls) for
+		// the analytics rollups; one row per chat/generate completion
+		Version: 9,
+		Name:    "add generation_metrics table",
+		Up: `
+CREATE TABLE IF NOT EXISTS generation_metrics (
+    id TEXT PRIMARY KEY,
+    model TEXT NOT NULL,
+    user TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL DEFAULT 'chat' CHECK (kind IN ('chat', 'generate')),
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    tool_calls INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_generation_metrics_created ON generation_metrics(created_at);
+CREATE INDEX IF NOT EXISTS idx_generation_metrics_model ON generation_metrics(model);
+`,
+		Down: `DROP TABLE IF EXISTS generation_metrics`,
+	},
+	{
+		// Stored benchmark runs so users can compare quantizations of the
+		// same model on their own hardware over time
+		Version: 10,
+		Name:    "add model_benchmarks table",
+		Up: `
+CREATE TABLE IF NOT EXISTS model_benchmarks (
+    id TEXT PRIMARY KEY,
+    model TEXT NOT NULL,
+    prompts INTEGER NOT NULL DEFAULT 0,
+    tokens_per_second REAL NOT NULL DEFAULT 0,
+    time_to_first_token_ms INTEGER NOT NULL DEFAULT 0,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    memory_bytes INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_model_benchmarks_model ON model_benchmarks(model);
+`,
+		Down: `DROP TABLE IF EXISTS model_benchmarks`,
+	},
+	{
+		// Per-generation cost estimates for models with configured pricing
+		Version: 11,
+		Name:    "add generation_metrics.cost_usd",
+		UpFunc:  addColumnIfMissing("generation_metrics", "cost_usd", "REAL NOT NULL DEFAULT 0"),
+		Down:    `ALTER TABLE generation_metrics DROP COLUMN cost_usd`,
+	},
+	{
+		// Latency breakdown per generation: wall-clock time to first token
+		// and queue/load wait before generation started
+		Version: 12,
+		Name:    "add latency columns to generation_metrics",
+		UpFunc: func(tx *sql.Tx) error {
+			if err := addColumnIfMissing("generation_metrics", "first_token_ms", "INTEGER NOT NULL DEFAULT 0")(tx); err != nil {
+				return err
+			}
+			return addColumnIfMissing("generation_metrics", "queue_ms", "INTEGER NOT NULL DEFAULT 0")(tx)
+		},
+		Down: `
+ALTER TABLE generation_metrics DROP COLUMN first_token_ms;
+ALTER TABLE generation_metrics DROP COLUMN queue_ms;
+`,
+	},
+	{
+		// Transcripts for audio attachments, filled in by the background
+		// transcription pipeline and searched alongside message content
+		Version: 13,
+		Name:    "add attachments.transcript",
+		UpFunc:  addColumnIfMissing("attachments", "transcript", `TEXT NOT NULL DEFAULT ''`),
+		Down:    `ALTER TABLE attachments DROP COLUMN transcript`,
+	},
+	{
+		// Outbound webhooks: subscriber URLs with an HMAC secret and an
+		// event filter (empty list = all events), plus a delivery log so
+		// operators can see which notifications went out and which failed
+		Version: 14,
+		Name:    "add webhooks and webhook_deliveries tables",
+		Up: `
+CREATE TABLE IF NOT EXISTS webhooks (
+    id TEXT PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL DEFAULT '',
+    events TEXT NOT NULL DEFAULT '[]',
+    enabled INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id TEXT PRIMARY KEY,
+    webhook_id TEXT NOT NULL,
+    event TEXT NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at);
+`,
+		Down: `
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+`,
+	},
+	{
+		// Telegram bridge: maps a Telegram conversation onto a vessel chat
+		// so the history shows up in the web UI, with an optional per-
+		// conversation model override (set via the /model bot command)
+		Version: 15,
+		Name:    "add telegram_chats table",
+		Up: `
+CREATE TABLE IF NOT EXISTS telegram_chats (
+    telegram_chat_id INTEGER PRIMARY KEY,
+    chat_id TEXT NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+`,
+		Down: `DROP TABLE IF EXISTS telegram_chats`,
+	},
+	{
+		// Workspaces: a namespace above chats with a per-workspace model
+		// allowlist, settings blob, and membership list, so one instance
+		// can separate "work" and "personal" or serve a small team
+		Version: 16,
+		Name:    "add workspaces and workspace_members tables, chats.workspace_id",
+		UpFunc: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS workspaces (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    model_allowlist TEXT NOT NULL DEFAULT '[]',
+    settings TEXT NOT NULL DEFAULT '{}',
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id TEXT NOT NULL,
+    user TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'member')),
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (workspace_id, user),
+    FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+);
+`)
+			if err != nil {
+				return err
+			}
+			return addColumnIfMissing("chats", "workspace_id", "TEXT")(tx)
+		},
+		Down: `
+ALTER TABLE chats DROP COLUMN workspace_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+`,
+	},
+	{
+		// Artifacts: model-produced code files, documents, and SVG/HTML
+		// fragments stored outside message text. Every save of the same
+		// (chat, name) pair is a new version; nothing is overwritten.
+		Version: 17,
+		Name:    "add artifacts table",
+		Up: `
+CREATE TABLE IF NOT EXISTS artifacts (
+    id TEXT PRIMARY KEY,
+    chat_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL DEFAULT '',
+    language TEXT NOT NULL DEFAULT '',
+    version INTEGER NOT NULL DEFAULT 1,
+    content TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    UNIQUE (chat_id, name, version),
+    FOREIGN KEY (chat_id) REFERENCES chats(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_artifacts_chat ON artifacts(chat_id, name, version);
+`,
+		Down: `DROP TABLE IF EXISTS artifacts`,
+	},
+	{
+		// Model arena: blind A/B matches between local models and an
+		// Elo-style rating per model, so "which model should I keep" gets
+		// an empirical answer
+		Version: 18,
+		Name:    "add arena_matches and arena_ratings tables",
+		Up: `
+CREATE TABLE IF NOT EXISTS arena_matches (
+    id TEXT PRIMARY KEY,
+    prompt TEXT NOT NULL,
+    model_a TEXT NOT NULL,
+    model_b TEXT NOT NULL,
+    response_a TEXT NOT NULL DEFAULT '',
+    response_b TEXT NOT NULL DEFAULT '',
+    winner TEXT NOT NULL DEFAULT '' CHECK (winner IN ('', 'a', 'b', 'tie')),
+    voted_at TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS arena_ratings (
+    model TEXT PRIMARY KEY,
+    rating REAL NOT NULL DEFAULT 1000,
+    wins INTEGER NOT NULL DEFAULT 0,
+    losses INTEGER NOT NULL DEFAULT 0,
+    ties INTEGER NOT NULL DEFAULT 0
+);
+`,
+		Down: `
+DROP TABLE IF EXISTS arena_matches;
+DROP TABLE IF EXISTS arena_ratings;
+`,
+	},
+	{
+		// Guardrail events: everything the moderation pipeline blocked,
+		// flagged, or redacted, with a short excerpt for admin review
+		Version: 19,
+		Name:    "add guardrail_events table",
+		Up: `
+CREATE TABLE IF NOT EXISTS guardrail_events (
+    id TEXT PRIMARY KEY,
+    rule TEXT NOT NULL,
+    action TEXT NOT NULL,
+    direction TEXT NOT NULL CHECK (direction IN ('input', 'output')),
+    model TEXT NOT NULL DEFAULT '',
+    excerpt TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_guardrail_events_created ON guardrail_events(created_at);
+`,
+		Down: `DROP TABLE IF EXISTS guardrail_events`,
+	},
+	{
+		// Persistent job queue backing background work (registry sync,
+		// backups, pulls); rows double as the job history
+		Version: 20,
+		Name:    "add jobs table",
+		Up: `
+CREATE TABLE IF NOT EXISTS jobs (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    payload TEXT NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'cancelled')),
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 1,
+    scheduled_at TEXT NOT NULL,
+    started_at TEXT,
+    finished_at TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status_scheduled ON jobs(status, scheduled_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_kind ON jobs(kind);
+`,
+		Down: `DROP TABLE IF EXISTS jobs`,
+	},
+	{
+		// In-app notification center: system events (pull finished,
+		// update available, backup failed) surfaced inside the UI
+		Version: 21,
+		Name:    "add notifications table",
+		Up: `
+CREATE TABLE IF NOT EXISTS notifications (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    read INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_read_created ON notifications(read, created_at);
+`,
+		Down: `DROP TABLE IF EXISTS notifications`,
+	},
+	{
+		// Thumbs-up/down feedback on assistant messages; drives the
+		// fine-tuning dataset export
+		Version: 22,
+		Name:    "add message_ratings table",
+		Up: `
+CREATE TABLE IF NOT EXISTS message_ratings (
+    message_id TEXT PRIMARY KEY,
+    rating INTEGER NOT NULL CHECK (rating IN (-1, 1)),
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+);
+`,
+		Down: `DROP TABLE IF EXISTS message_ratings`,
+	},
+	{
+		// Per-chat context window strategy applied by the completion
+		// endpoint: '' (off), truncate-oldest, sliding-window, summarize
+		Version: 23,
+		Name:    "add context_strategy column to chats",
+		UpFunc:  addColumnIfMissing("chats", "context_strategy", `TEXT NOT NULL DEFAULT ''`),
+	},
+	{
+		// Per-chat advanced sampling options (stop sequences, seed,
+		// mirostat, repeat_penalty...) applied on every completion
+		Version: 24,
+		Name:    "add sampling_options column to chats",
+		UpFunc:  addColumnIfMissing("chats", "sampling_options", `TEXT NOT NULL DEFAULT '{}'`),
+	},
+	{
+		// The exact model and options behind each assistant message, so a
+		// generation can be replayed with identical parameters
+		Version: 25,
+		Name:    "add message_generations table",
+		Up: `
+CREATE TABLE IF NOT EXISTS message_generations (
+    message_id TEXT PRIMARY KEY,
+    model TEXT NOT NULL,
+    options TEXT NOT NULL DEFAULT '{}',
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+);
+`,
+		Down: `DROP TABLE IF EXISTS message_generations`,
+	},
+	{
+		// Index of chats moved out of the live database into compressed
+		// archive files on disk, so they stay searchable and restorable
+		Version: 26,
+		Name:    "add archived_chats index table",
+		Up: `
+CREATE TABLE IF NOT EXISTS archived_chats (
+    id TEXT PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    messages INTEGER NOT NULL DEFAULT 0,
+    file TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    archived_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_archived_chats_title ON archived_chats(title);
+`,
+		Down: `DROP TABLE IF EXISTS archived_chats`,
+	},
+	{
+		// Vault for third-party provider API keys, encrypted at rest like
+		// fetch profile secrets; hint keeps the last characters for display
+		Version: 27,
+		Name:    "add api_keys table",
+		Up: `
+CREATE TABLE IF NOT EXISTS api_keys (
+    provider TEXT PRIMARY KEY,
+    secret TEXT NOT NULL,
+    hint TEXT NOT NULL DEFAULT '',
+    last_used_at TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+`,
+		Down: `DROP TABLE IF EXISTS api_keys`,
+	},
+	{
+		// User-defined rules that tag, route, or apply a persona to new
+		// chats based on the first message (regex or embedding classifier)
+		Version: 28,
+		Name:    "add routing_rules table",
+		Up: `
+CREATE TABLE IF NOT EXISTS routing_rules (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    match_type TEXT NOT NULL CHECK (match_type IN ('regex', 'semantic')),
+    pattern TEXT NOT NULL,
+    threshold REAL NOT NULL DEFAULT 0,
+    embed_model TEXT NOT NULL DEFAULT '',
+    tag TEXT NOT NULL DEFAULT '',
+    persona_id TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+`,
+		Down: `DROP TABLE IF EXISTS routing_rules`,
+	},
+	{
+		// Comma-separated tags applied by routing rules (or by hand)
+		Version: 29,
+		Name:    "add tags column to chats",
+		UpFunc:  addColumnIfMissing("chats", "tags", `TEXT NOT NULL DEFAULT ''`),
+	},
+	{
+		// Reusable system prompt snippets referenced by name from persona
+		// and chat system prompts ({{snippet:name}}); version counts edits
+		Version: 30,
+		Name:    "add prompt_snippets table",
+		Up: `
+CREATE TABLE IF NOT EXISTS prompt_snippets (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+`,
+		Down: `DROP TABLE IF EXISTS prompt_snippets`,
+	},
+	{
+		// Primary language of a chat (ISO 639-1), detected from the first
+		// user message; drives listing filters and translation defaults
+		Version: 31,
+		Name:    "add language column to chats",
+		UpFunc:  addColumnIfMissing("chats", "language", `TEXT NOT NULL DEFAULT ''`),
+	},
+	{
+		// Which embedding model produced each knowledge collection's
+		// vectors (the vectors themselves live client-side); lets the
+		// backend detect and migrate stale collections on model switches
+		Version: 32,
+		Name:    "add collection_embeddings table",
+		Up: `
+CREATE TABLE IF NOT EXISTS collection_embeddings (
+    collection_id TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    embed_model TEXT NOT NULL,
+    vectors INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+`,
+		Down: `DROP TABLE IF EXISTS collection_embeddings`,
+	},
+	{
+		// Public client addresses that have reached the API, for the
+		// access anomaly alerts (new address = notification + webhook)
+		Version: 33,
+		Name:    "add access_origins table",
+		Up: `
+CREATE TABLE IF NOT EXISTS access_origins (
+    ip TEXT PRIMARY KEY,
+    country TEXT NOT NULL DEFAULT '',
+    first_seen TEXT NOT NULL DEFAULT (datetime('now')),
+    last_seen TEXT NOT NULL DEFAULT (datetime('now'))
+);
+`,
+		Down: `DROP TABLE IF EXISTS access_origins`,
+	},
+	{
+		// Retention lock: a locked chat is refused by every deletion
+		// path (single delete, cold storage sweeps) until explicitly
+		// unlocked, so cleanup automation can't eat important records
+		Version: 34,
+		Name:    "add locked column to chats",
+		UpFunc:  addColumnIfMissing("chats", "locked", `INTEGER NOT NULL DEFAULT 0`),
+	},
+	{
+		// Last-read position per chat and device, so multi-device users
+		// can see which conversations gained messages since they left.
+		// The read message's created_at is denormalized for the unread
+		// count query.
+		Version: 35,
+		Name:    "add chat_read_state table",
+		Up: `
+CREATE TABLE IF NOT EXISTS chat_read_state (
+    chat_id TEXT NOT NULL,
+    device_id TEXT NOT NULL,
+    last_read_message_id TEXT NOT NULL DEFAULT '',
+    last_read_message_at TEXT NOT NULL DEFAULT '',
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (chat_id, device_id)
+);
+`,
+		Down: `DROP TABLE IF EXISTS chat_read_state`,
+	},
+	{
+		// Named generation option bundles selectable per completion
+		// (?preset=). Ships with three starting points; all rows are
+		// editable through the presets CRUD.
+		Version: 36,
+		Name:    "add generation_presets table",
+		Up: `
+CREATE TABLE IF NOT EXISTS generation_presets (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    options TEXT NOT NULL DEFAULT '{}',
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+INSERT OR IGNORE INTO generation_presets (id, name, description, options) VALUES
+    ('creative', 'creative', 'Loose sampling for brainstorming and fiction',
+     '{"temperature":1.1,"top_p":0.95,"repeat_penalty":1.05}'),
+    ('precise', 'precise', 'Tight sampling for factual and technical answers',
+     '{"temperature":0.3,"top_p":0.8,"repeat_penalty":1.1}'),
+    ('deterministic', 'deterministic', 'Reproducible output for testing and evals',
+     '{"temperature":0.0,"seed":42}');
+`,
+		Down: `DROP TABLE IF EXISTS generation_presets`,
+	},
+	{
+		// Audit trail for tool calls that touched outside content;
+		// currently fed by the fetch proxy when the injection scanner
+		// flags a page
+		Version: 37,
+		Name:    "add tool_audit table",
+		Up: `
+CREATE TABLE IF NOT EXISTS tool_audit (
+    id TEXT PRIMARY KEY,
+    tool TEXT NOT NULL,
+    target TEXT NOT NULL DEFAULT '',
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_tool_audit_created ON tool_audit(created_at DESC);
+`,
+		Down: `
+DROP INDEX IF EXISTS idx_tool_audit_created;
+DROP TABLE IF EXISTS tool_audit;
+`,
+	},
+}
+
+// addColumnIfMissing returns an UpFunc adding a column unless it already
+// exists. SQLite has no IF NOT EXISTS for ALTER TABLE, and databases that
+// predate versioned migrations may already carry the column.
+func addColumnIfMissing(table, column, definition string) func(*sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		var count int
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name='%s'`, table, column)
+		if err := tx.QueryRow(query).Scan(&count); err != nil {
+			return fmt.Errorf("failed to check %s.%s column: %w", table, column, err)
+		}
+		if count > 0 {
+			return nil
+		}
+		_, err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+		if err != nil {
+			return fmt.Errorf("failed to add %s.%s column: %w", table, column, err)
+		}
+		return nil
+	}
+}
+
+// ensureMigrationsTable creates the bookkeeping table on first run
+func ensureMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TEXT NOT NULL DEFAULT (datetime('now'))
+		)
+	`)
 	if err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
 	}
+	return nil
+}
 
-	// Add tag_sizes column if it doesn't exist
-	// SQLite doesn't have IF NOT EXISTS for ALTER TABLE, so we check first
-	var count int
-	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('remote_models') WHERE name='tag_sizes'`).Scan(&count)
+// SchemaVersion returns the highest applied migration version (0 for a
+// fresh database)
+func SchemaVersion(db *sql.DB) (int, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return 0, err
+	}
+	var version int
+	err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
 	if err != nil {
-		return fmt.Errorf("failed to check tag_sizes column: %w", err)
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
 	}
-	if count == 0 {
-		_, err = db.Exec(`ALTER TABLE remote_models ADD COLUMN tag_sizes TEXT NOT NULL DEFAULT '{}'`)
-		if err != nil {
-			return fmt.Errorf("failed to add tag_sizes column: %w", err)
+	return version, nil
+}
+
+// RunMigrations applies all pending migrations in order, each in its own
+// transaction so a failure leaves the database at a well-defined version
+func RunMigrations(db *sql.DB) error {
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+		if err := applyMigration(db, migration); err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+// applyMigration runs one migration and records it atomically
+func applyMigration(db *sql.DB, migration Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if migration.UpFunc != nil {
+		err = migration.UpFunc(tx)
+	} else {
+		_, err = tx.Exec(migration.Up)
+	}
+	if err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, migration.Version, migration.Name)
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+
+	return tx.Commit()
+}
 
-	// Add system_prompt_id column to chats table if it doesn't exist
-	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('chats') WHERE name='system_prompt_id'`).Scan(&count)
+// MigrateDown rolls back applied migrations, newest first, until the
+// schema is at targetVersion. Migrations without a Down script stop the
+// rollback with an error.
+func MigrateDown(db *sql.DB, targetVersion int) error {
+	current, err := SchemaVersion(db)
 	if err != nil {
-		return fmt.Errorf("failed to check system_prompt_id column: %w", err)
+		return err
 	}
-	if count == 0 {
-		_, err = db.Exec(`ALTER TABLE chats ADD COLUMN system_prompt_id TEXT`)
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if migration.Version > current || migration.Version <= targetVersion {
+			continue
+		}
+		if migration.Down == "" {
+			return fmt.Errorf("migration %d (%s) has no down script", migration.Version, migration.Name)
+		}
+
+		tx, err := db.Begin()
 		if err != nil {
-			return fmt.Errorf("failed to add system_prompt_id column: %w", err)
+			return fmt.Errorf("failed to begin rollback of migration %d: %w", migration.Version, err)
+		}
+		if _, err := tx.Exec(migration.Down); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("rollback of migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, migration.Version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to unrecord migration %d: %w", migration.Version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit rollback of migration %d: %w", migration.Version, err)
 		}
 	}
-
 	return nil
 }
@@ -0,0 +1,47 @@
+// Package logging configures structured logging (slog) for the server.
+// Level and format come from the config file (logging.level / logging.format)
+// or the LOG_LEVEL / LOG_FORMAT environment variables.
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"vessel-backend/internal/config"
+)
+
+// Setup installs the default slog logger according to the configuration.
+// It also routes the standard library log package through slog, so any
+// remaining log.Printf calls get structured output too.
+func Setup(cfg config.LoggingConfig) {
+	var level slog.Level
+	switch strings.ToLower(cfg.Level) {
+	case "debug":
+		level = slog.LevelDebug
+	case "warn", "warning":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	default:
+		level = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if strings.ToLower(cfg.Format) == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+
+	slog.SetDefault(slog.New(handler))
+}
+
+// Module returns a logger scoped to a subsystem, tagging every record with
+// a module field. Call it at use time (not in package variables) so it picks
+// up the handler installed by Setup.
+func Module(name string) *slog.Logger {
+	return slog.Default().With("module", name)
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"vessel-backend/internal/crypto"
+)
+
+// APIKey is the non-secret view of one stored provider key. The secret
+// itself is encrypted at rest and never included in JSON output; Hint
+// keeps the last few characters so the UI can show which key is set.
+type APIKey struct {
+	Provider   string `json:"provider"`
+	Hint       string `json:"hint"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// SetAPIKey stores (or replaces) the encrypted key for a provider
+func SetAPIKey(db *sql.DB, provider, secret string) error {
+	encrypted, err := crypto.Encrypt([]byte(secret))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key: %w", err)
+	}
+
+	hint := ""
+	if len(secret) > 4 {
+		hint = "..." + secret[len(secret)-4:]
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err = db.Exec(`
+		INSERT INTO api_keys (provider, secret, hint, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(provider) DO UPDATE SET secret = excluded.secret, hint = excluded.hint, updated_at = excluded.updated_at`,
+		provider, encrypted, hint, now, now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store key: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKey returns the decrypted key for a provider, or empty when none
+// is stored, and touches last_used_at for the usage audit
+func GetAPIKey(db *sql.DB, provider string) (string, error) {
+	var encrypted string
+	err := db.QueryRow(`SELECT secret FROM api_keys WHERE provider = ?`, provider).Scan(&encrypted)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get key: %w", err)
+	}
+
+	plaintext, err := crypto.Decrypt(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt key: %w", err)
+	}
+
+	db.Exec(`UPDATE api_keys SET last_used_at = ? WHERE provider = ?`,
+		time.Now().UTC().Format(time.RFC3339), provider)
+
+	return string(plaintext), nil
+}
+
+// ListAPIKeys retrieves all stored keys without secret material
+func ListAPIKeys(db *sql.DB) ([]APIKey, error) {
+	rows, err := db.Query(`
+		SELECT provider, hint, last_used_at, created_at, updated_at
+		FROM api_keys ORDER BY provider ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		var key APIKey
+		if err := rows.Scan(&key.Provider, &key.Hint, &key.LastUsedAt,
+			&key.CreatedAt, &key.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// DeleteAPIKey removes a provider's stored key
+func DeleteAPIKey(db *sql.DB, provider string) error {
+	result, err := db.Exec(`DELETE FROM api_keys WHERE provider = ?`, provider)
+	if err != nil {
+		return fmt.Errorf("failed to delete key: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("key not found")
+	}
+	return nil
+}
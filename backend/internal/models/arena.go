@@ -0,0 +1,206 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// arenaEloK is the Elo K-factor: how far one vote moves a rating
+const arenaEloK = 32
+
+// arenaInitialRating is where unrated models start
+const arenaInitialRating = 1000
+
+// ArenaMatch is one blind comparison: the same prompt answered by two
+// models. Winner stays empty until the user votes ("a", "b", or "tie").
+type ArenaMatch struct {
+	ID        string `json:"id"`
+	Prompt    string `json:"prompt"`
+	ModelA    string `json:"model_a"`
+	ModelB    string `json:"model_b"`
+	ResponseA string `json:"response_a"`
+	ResponseB string `json:"response_b"`
+	Winner    string `json:"winner,omitempty"`
+	VotedAt   string `json:"voted_at,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ArenaRating is one model's standing on the local leaderboard
+type ArenaRating struct {
+	Model  string  `json:"model"`
+	Rating float64 `json:"rating"`
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Ties   int     `json:"ties"`
+}
+
+// CreateArenaMatch stores a completed (but unvoted) match
+func CreateArenaMatch(db *sql.DB, match *ArenaMatch) error {
+	match.ID = uuid.New().String()
+	match.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	_, err := db.Exec(`
+		INSERT INTO arena_matches (id, prompt, model_a, model_b, response_a, response_b, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		match.ID, match.Prompt, match.ModelA, match.ModelB, match.ResponseA, match.ResponseB, match.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create arena match: %w", err)
+	}
+	return nil
+}
+
+// GetArenaMatch retrieves one match, or nil
+func GetArenaMatch(db *sql.DB, id string) (*ArenaMatch, error) {
+	var m ArenaMatch
+	err := db.QueryRow(`
+		SELECT id, prompt, model_a, model_b, response_a, response_b, winner, voted_at, created_at
+		FROM arena_matches WHERE id = ?`, id).
+		Scan(&m.ID, &m.Prompt, &m.ModelA, &m.ModelB, &m.ResponseA, &m.ResponseB, &m.Winner, &m.VotedAt, &m.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get arena match: %w", err)
+	}
+	return &m, nil
+}
+
+// RecordArenaVote stores the verdict for a match and updates both models'
+// Elo ratings. A match takes exactly one vote.
+func RecordArenaVote(db *sql.DB, matchID, winner string) (*ArenaMatch, error) {
+	match, err := GetArenaMatch(db, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		return nil, fmt.Errorf("match not found")
+	}
+	if match.Winner != "" {
+		return nil, fmt.Errorf("match already voted")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	match.Winner = winner
+	match.VotedAt = time.Now().UTC().Format(time.RFC3339)
+	_, err = tx.Exec(`
+		UPDATE arena_matches SET winner = ?, voted_at = ? WHERE id = ?`,
+		match.Winner, match.VotedAt, match.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record vote: %w", err)
+	}
+
+	if err := updateEloRatings(tx, match.ModelA, match.ModelB, winner); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit vote: %w", err)
+	}
+	return match, nil
+}
+
+// updateEloRatings applies one match outcome to both models' ratings
+func updateEloRatings(tx *sql.Tx, modelA, modelB, winner string) error {
+	ratingA, err := arenaRating(tx, modelA)
+	if err != nil {
+		return err
+	}
+	ratingB, err := arenaRating(tx, modelB)
+	if err != nil {
+		return err
+	}
+
+	// Standard Elo expectation and update
+	expectedA := 1 / (1 + math.Pow(10, (ratingB-ratingA)/400))
+	scoreA := 0.5
+	switch winner {
+	case "a":
+		scoreA = 1
+	case "b":
+		scoreA = 0
+	}
+
+	delta := arenaEloK * (scoreA - expectedA)
+	ratingA += delta
+	ratingB -= delta
+
+	winsA, lossesA, tiesA := 0, 0, 0
+	winsB, lossesB, tiesB := 0, 0, 0
+	switch winner {
+	case "a":
+		winsA, lossesB = 1, 1
+	case "b":
+		winsB, lossesA = 1, 1
+	default:
+		tiesA, tiesB = 1, 1
+	}
+
+	for _, update := range []struct {
+		model              string
+		rating             float64
+		wins, losses, ties int
+	}{
+		{modelA, ratingA, winsA, lossesA, tiesA},
+		{modelB, ratingB, winsB, lossesB, tiesB},
+	} {
+		_, err := tx.Exec(`
+			INSERT INTO arena_ratings (model, rating, wins, losses, ties)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(model) DO UPDATE SET
+				rating = excluded.rating,
+				wins = wins + excluded.wins,
+				losses = losses + excluded.losses,
+				ties = ties + excluded.ties`,
+			update.model, update.rating, update.wins, update.losses, update.ties,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update rating: %w", err)
+		}
+	}
+	return nil
+}
+
+// arenaRating reads a model's current rating, defaulting new models
+func arenaRating(tx *sql.Tx, model string) (float64, error) {
+	var rating float64
+	err := tx.QueryRow("SELECT rating FROM arena_ratings WHERE model = ?", model).Scan(&rating)
+	if err == sql.ErrNoRows {
+		return arenaInitialRating, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read rating: %w", err)
+	}
+	return rating, nil
+}
+
+// ArenaLeaderboard returns all rated models, best first
+func ArenaLeaderboard(db *sql.DB) ([]ArenaRating, error) {
+	rows, err := db.Query(`
+		SELECT model, rating, wins, losses, ties
+		FROM arena_ratings ORDER BY rating DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	var leaderboard []ArenaRating
+	for rows.Next() {
+		var r ArenaRating
+		if err := rows.Scan(&r.Model, &r.Rating, &r.Wins, &r.Losses, &r.Ties); err != nil {
+			return nil, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		leaderboard = append(leaderboard, r)
+	}
+	return leaderboard, rows.Err()
+}
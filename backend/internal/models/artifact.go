@@ -0,0 +1,131 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Artifact is one version of a model-produced file (code, document, SVG,
+// HTML) linked to a chat. Saving under an existing name creates the next
+// version instead of overwriting.
+type Artifact struct {
+	ID        string `json:"id"`
+	ChatID    string `json:"chat_id"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind,omitempty"`
+	Language  string `json:"language,omitempty"`
+	Version   int    `json:"version"`
+	Content   string `json:"content,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// CreateArtifactVersion stores a new version of an artifact, numbering it
+// one past the latest existing version of the same name in the chat
+func CreateArtifactVersion(db *sql.DB, artifact *Artifact) error {
+	artifact.ID = uuid.New().String()
+	artifact.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	err := db.QueryRow(`
+		SELECT COALESCE(MAX(version), 0) + 1 FROM artifacts WHERE chat_id = ? AND name = ?`,
+		artifact.ChatID, artifact.Name).Scan(&artifact.Version)
+	if err != nil {
+		return fmt.Errorf("failed to determine artifact version: %w", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO artifacts (id, chat_id, name, kind, language, version, content, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		artifact.ID, artifact.ChatID, artifact.Name, artifact.Kind, artifact.Language,
+		artifact.Version, artifact.Content, artifact.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create artifact: %w", err)
+	}
+	return nil
+}
+
+// ListChatArtifacts returns the latest version of every artifact in a
+// chat, without content (lists stay light; fetch one for the body)
+func ListChatArtifacts(db *sql.DB, chatID string) ([]Artifact, error) {
+	rows, err := db.Query(`
+		SELECT a.id, a.chat_id, a.name, a.kind, a.language, a.version, a.created_at
+		FROM artifacts a
+		JOIN (
+			SELECT name, MAX(version) AS version FROM artifacts WHERE chat_id = ? GROUP BY name
+		) latest ON latest.name = a.name AND latest.version = a.version
+		WHERE a.chat_id = ?
+		ORDER BY a.name`, chatID, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+	defer rows.Close()
+
+	var artifacts []Artifact
+	for rows.Next() {
+		var a Artifact
+		if err := rows.Scan(&a.ID, &a.ChatID, &a.Name, &a.Kind, &a.Language, &a.Version, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan artifact: %w", err)
+		}
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, rows.Err()
+}
+
+// ListArtifactVersions returns every stored version of one artifact,
+// oldest first, without content
+func ListArtifactVersions(db *sql.DB, chatID, name string) ([]Artifact, error) {
+	rows, err := db.Query(`
+		SELECT id, chat_id, name, kind, language, version, created_at
+		FROM artifacts WHERE chat_id = ? AND name = ?
+		ORDER BY version`, chatID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifact versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []Artifact
+	for rows.Next() {
+		var a Artifact
+		if err := rows.Scan(&a.ID, &a.ChatID, &a.Name, &a.Kind, &a.Language, &a.Version, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan artifact: %w", err)
+		}
+		versions = append(versions, a)
+	}
+	return versions, rows.Err()
+}
+
+// GetArtifact retrieves one artifact version by ID (with content), or nil
+func GetArtifact(db *sql.DB, id string) (*Artifact, error) {
+	var a Artifact
+	err := db.QueryRow(`
+		SELECT id, chat_id, name, kind, language, version, content, created_at
+		FROM artifacts WHERE id = ?`, id).
+		Scan(&a.ID, &a.ChatID, &a.Name, &a.Kind, &a.Language, &a.Version, &a.Content, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artifact: %w", err)
+	}
+	return &a, nil
+}
+
+// GetArtifactVersion retrieves a specific version of a named artifact
+// (with content), or nil
+func GetArtifactVersion(db *sql.DB, chatID, name string, version int) (*Artifact, error) {
+	var a Artifact
+	err := db.QueryRow(`
+		SELECT id, chat_id, name, kind, language, version, content, created_at
+		FROM artifacts WHERE chat_id = ? AND name = ? AND version = ?`, chatID, name, version).
+		Scan(&a.ID, &a.ChatID, &a.Name, &a.Kind, &a.Language, &a.Version, &a.Content, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artifact version: %w", err)
+	}
+	return &a, nil
+}
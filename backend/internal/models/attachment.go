@@ -0,0 +1,207 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// CreateAttachment stores an attachment for a message, encrypting the
+// data when content encryption is enabled
+func CreateAttachment(db *sql.DB, attachment *Attachment) error {
+	if attachment.ID == "" {
+		attachment.ID = uuid.New().String()
+	}
+
+	stored, err := EncodeAttachmentData(attachment.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt attachment: %w", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO attachments (id, message_id, mime_type, data, filename, transcript)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		attachment.ID, attachment.MessageID, attachment.MimeType, stored,
+		attachment.Filename, attachment.Transcript,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+	return nil
+}
+
+// SetAttachmentTranscript stores the transcript for an attachment
+func SetAttachmentTranscript(db *sql.DB, id, transcript string) error {
+	_, err := db.Exec(`UPDATE attachments SET transcript = ? WHERE id = ?`, transcript, id)
+	if err != nil {
+		return fmt.Errorf("failed to set attachment transcript: %w", err)
+	}
+	return nil
+}
+
+// GetAttachment returns one attachment including its (decrypted) file
+// data, for the download route
+func GetAttachment(db *sql.DB, id string) (*Attachment, error) {
+	var attachment Attachment
+	var stored []byte
+	err := db.QueryRow(`
+		SELECT id, message_id, mime_type, data, filename, transcript
+		FROM attachments WHERE id = ?`, id).
+		Scan(&attachment.ID, &attachment.MessageID, &attachment.MimeType,
+			&stored, &attachment.Filename, &attachment.Transcript)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	attachment.Data = DecodeAttachmentData(stored)
+	return &attachment, nil
+}
+
+// ListChatAttachmentsWithData returns every attachment in a chat including
+// its (decrypted) file data, for export paths that inline the files
+func ListChatAttachmentsWithData(db *sql.DB, chatID string) ([]Attachment, error) {
+	rows, err := db.Query(`
+		SELECT a.id, a.message_id, a.mime_type, a.data, a.filename, a.transcript
+		FROM attachments a
+		JOIN messages m ON m.id = a.message_id
+		WHERE m.chat_id = ?
+		ORDER BY m.created_at ASC`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chat attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []Attachment{}
+	for rows.Next() {
+		var attachment Attachment
+		var stored []byte
+		if err := rows.Scan(&attachment.ID, &attachment.MessageID, &attachment.MimeType,
+			&stored, &attachment.Filename, &attachment.Transcript); err != nil {
+			return nil, err
+		}
+		attachment.Data = DecodeAttachmentData(stored)
+		attachments = append(attachments, attachment)
+	}
+	return attachments, rows.Err()
+}
+
+// ListChatAttachments returns attachment metadata (no file data) for every
+// message in a chat, so clients can pull transcripts into message context
+func ListChatAttachments(db *sql.DB, chatID string) ([]Attachment, error) {
+	rows, err := db.Query(`
+		SELECT a.id, a.message_id, a.mime_type, a.filename, a.transcript
+		FROM attachments a
+		JOIN messages m ON m.id = a.message_id
+		WHERE m.chat_id = ?
+		ORDER BY m.created_at ASC`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chat attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []Attachment{}
+	for rows.Next() {
+		var attachment Attachment
+		if err := rows.Scan(&attachment.ID, &attachment.MessageID, &attachment.MimeType,
+			&attachment.Filename, &attachment.Transcript); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, rows.Err()
+}
+
+// MediaItem is gallery metadata for an image or audio attachment: the
+// attachment identifiers plus where it lives in the conversation tree,
+// without the file data (clients download via the attachment route)
+type MediaItem struct {
+	ID        string `json:"id"`
+	MessageID string `json:"message_id"`
+	ChatID    string `json:"chat_id"`
+	ChatTitle string `json:"chat_title"`
+	MimeType  string `json:"mime_type"`
+	Filename  string `json:"filename"`
+	CreatedAt string `json:"created_at"`
+}
+
+// mediaMimeFilter restricts a query to gallery-worthy attachments,
+// optionally narrowed to one kind ("image" or "audio")
+func mediaMimeFilter(kind string) (string, []interface{}) {
+	switch kind {
+	case "image", "audio":
+		return ` AND a.mime_type LIKE ?`, []interface{}{kind + "/%"}
+	default:
+		return ` AND (a.mime_type LIKE 'image/%' OR a.mime_type LIKE 'audio/%')`, nil
+	}
+}
+
+// ListChatMedia returns the image and audio attachments of one chat in
+// conversation order
+func ListChatMedia(db *sql.DB, chatID, kind string) ([]MediaItem, error) {
+	filter, args := mediaMimeFilter(kind)
+	query := `
+		SELECT a.id, a.message_id, m.chat_id, c.title, a.mime_type, a.filename, m.created_at
+		FROM attachments a
+		JOIN messages m ON m.id = a.message_id
+		JOIN chats c ON c.id = m.chat_id
+		WHERE m.chat_id = ?` + filter + `
+		ORDER BY m.created_at ASC`
+
+	rows, err := db.Query(query, append([]interface{}{chatID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chat media: %w", err)
+	}
+	defer rows.Close()
+	return scanMediaItems(rows)
+}
+
+// ListMedia returns image and audio attachments across all chats,
+// newest first, with the total count for pagination
+func ListMedia(db *sql.DB, kind string, limit, offset int) ([]MediaItem, int, error) {
+	filter, args := mediaMimeFilter(kind)
+
+	var total int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM attachments a
+		JOIN messages m ON m.id = a.message_id
+		WHERE 1=1` + filter
+	if err := db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count media: %w", err)
+	}
+
+	query := `
+		SELECT a.id, a.message_id, m.chat_id, c.title, a.mime_type, a.filename, m.created_at
+		FROM attachments a
+		JOIN messages m ON m.id = a.message_id
+		JOIN chats c ON c.id = m.chat_id
+		WHERE 1=1` + filter + `
+		ORDER BY m.created_at DESC
+		LIMIT ? OFFSET ?`
+
+	rows, err := db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list media: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanMediaItems(rows)
+	return items, total, err
+}
+
+// scanMediaItems collects media rows from either listing query
+func scanMediaItems(rows *sql.Rows) ([]MediaItem, error) {
+	items := []MediaItem{}
+	for rows.Next() {
+		var item MediaItem
+		if err := rows.Scan(&item.ID, &item.MessageID, &item.ChatID, &item.ChatTitle,
+			&item.MimeType, &item.Filename, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
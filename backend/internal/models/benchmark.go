@@ -0,0 +1,79 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ModelBenchmark is one stored benchmark run for a model
+type ModelBenchmark struct {
+	ID                 string    `json:"id"`
+	Model              string    `json:"model"`
+	Prompts            int       `json:"prompts"`
+	TokensPerSecond    float64   `json:"tokens_per_second"`
+	TimeToFirstTokenMs int64     `json:"time_to_first_token_ms"`
+	PromptTokens       int       `json:"prompt_tokens"`
+	CompletionTokens   int       `json:"completion_tokens"`
+	MemoryBytes        int64     `json:"memory_bytes"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// SaveModelBenchmark stores one benchmark run
+func SaveModelBenchmark(db *sql.DB, benchmark *ModelBenchmark) error {
+	if benchmark.ID == "" {
+		benchmark.ID = uuid.New().String()
+	}
+	benchmark.CreatedAt = time.Now().UTC()
+
+	_, err := db.Exec(`
+		INSERT INTO model_benchmarks (id, model, prompts, tokens_per_second, time_to_first_token_ms,
+			prompt_tokens, completion_tokens, memory_bytes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		benchmark.ID, benchmark.Model, benchmark.Prompts, benchmark.TokensPerSecond,
+		benchmark.TimeToFirstTokenMs, benchmark.PromptTokens, benchmark.CompletionTokens,
+		benchmark.MemoryBytes, benchmark.CreatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save benchmark: %w", err)
+	}
+	return nil
+}
+
+// ListModelBenchmarks returns stored benchmark runs, newest first. With a
+// non-empty model, only that model's runs are returned.
+func ListModelBenchmarks(db *sql.DB, model string) ([]ModelBenchmark, error) {
+	query := `
+		SELECT id, model, prompts, tokens_per_second, time_to_first_token_ms,
+			prompt_tokens, completion_tokens, memory_bytes, created_at
+		FROM model_benchmarks`
+	args := []interface{}{}
+	if model != "" {
+		query += ` WHERE model = ?`
+		args = append(args, model)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list benchmarks: %w", err)
+	}
+	defer rows.Close()
+
+	var benchmarks []ModelBenchmark
+	for rows.Next() {
+		var benchmark ModelBenchmark
+		var createdAt string
+		if err := rows.Scan(&benchmark.ID, &benchmark.Model, &benchmark.Prompts,
+			&benchmark.TokensPerSecond, &benchmark.TimeToFirstTokenMs,
+			&benchmark.PromptTokens, &benchmark.CompletionTokens,
+			&benchmark.MemoryBytes, &createdAt); err != nil {
+			return nil, err
+		}
+		benchmark.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		benchmarks = append(benchmarks, benchmark)
+	}
+	return benchmarks, rows.Err()
+}
@@ -2,7 +2,9 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,7 +17,11 @@ type Chat struct {
 	Model          string    `json:"model"`
 	Pinned         bool      `json:"pinned"`
 	Archived       bool      `json:"archived"`
+	Locked         bool      `json:"locked,omitempty"`
 	SystemPromptID *string   `json:"system_prompt_id,omitempty"`
+	PersonaID      *string   `json:"persona_id,omitempty"`
+	WorkspaceID    *string   `json:"workspace_id,omitempty"`
+	Language       string    `json:"language,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 	SyncVersion    int64     `json:"sync_version"`
@@ -37,11 +43,12 @@ type Message struct {
 
 // Attachment represents a file attached to a message
 type Attachment struct {
-	ID        string `json:"id"`
-	MessageID string `json:"message_id"`
-	MimeType  string `json:"mime_type"`
-	Data      []byte `json:"data,omitempty"`
-	Filename  string `json:"filename"`
+	ID         string `json:"id"`
+	MessageID  string `json:"message_id"`
+	MimeType   string `json:"mime_type"`
+	Data       []byte `json:"data,omitempty"`
+	Filename   string `json:"filename"`
+	Transcript string `json:"transcript,omitempty"`
 }
 
 // CreateChat creates a new chat in the database
@@ -55,9 +62,9 @@ func CreateChat(db *sql.DB, chat *Chat) error {
 	chat.SyncVersion = 1
 
 	_, err := db.Exec(`
-		INSERT INTO chats (id, title, model, pinned, archived, system_prompt_id, created_at, updated_at, sync_version)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		chat.ID, chat.Title, chat.Model, chat.Pinned, chat.Archived, chat.SystemPromptID,
+		INSERT INTO chats (id, title, model, pinned, archived, system_prompt_id, persona_id, workspace_id, created_at, updated_at, sync_version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		chat.ID, chat.Title, chat.Model, chat.Pinned, chat.Archived, chat.SystemPromptID, chat.PersonaID, chat.WorkspaceID,
 		chat.CreatedAt.Format(time.RFC3339), chat.UpdatedAt.Format(time.RFC3339), chat.SyncVersion,
 	)
 	if err != nil {
@@ -70,14 +77,14 @@ func CreateChat(db *sql.DB, chat *Chat) error {
 func GetChat(db *sql.DB, id string) (*Chat, error) {
 	chat := &Chat{}
 	var createdAt, updatedAt string
-	var pinned, archived int
-	var systemPromptID sql.NullString
+	var pinned, archived, locked int
+	var systemPromptID, personaID, workspaceID sql.NullString
 
 	err := db.QueryRow(`
-		SELECT id, title, model, pinned, archived, system_prompt_id, created_at, updated_at, sync_version
+		SELECT id, title, model, pinned, archived, locked, system_prompt_id, persona_id, workspace_id, language, created_at, updated_at, sync_version
 		FROM chats WHERE id = ?`, id).Scan(
-		&chat.ID, &chat.Title, &chat.Model, &pinned, &archived, &systemPromptID,
-		&createdAt, &updatedAt, &chat.SyncVersion,
+		&chat.ID, &chat.Title, &chat.Model, &pinned, &archived, &locked, &systemPromptID, &personaID, &workspaceID,
+		&chat.Language, &createdAt, &updatedAt, &chat.SyncVersion,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -88,9 +95,16 @@ func GetChat(db *sql.DB, id string) (*Chat, error) {
 
 	chat.Pinned = pinned == 1
 	chat.Archived = archived == 1
+	chat.Locked = locked == 1
 	if systemPromptID.Valid {
 		chat.SystemPromptID = &systemPromptID.String
 	}
+	if personaID.Valid {
+		chat.PersonaID = &personaID.String
+	}
+	if workspaceID.Valid {
+		chat.WorkspaceID = &workspaceID.String
+	}
 	chat.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 	chat.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
 
@@ -104,17 +118,27 @@ func GetChat(db *sql.DB, id string) (*Chat, error) {
 	return chat, nil
 }
 
-// ListChats retrieves all chats ordered by updated_at
-func ListChats(db *sql.DB, includeArchived bool) ([]Chat, error) {
+// ListChats retrieves all chats ordered by updated_at, optionally scoped
+// to one workspace
+func ListChats(db *sql.DB, workspace, language string, includeArchived bool) ([]Chat, error) {
 	query := `
-		SELECT id, title, model, pinned, archived, system_prompt_id, created_at, updated_at, sync_version
-		FROM chats`
+		SELECT id, title, model, pinned, archived, locked, system_prompt_id, persona_id, workspace_id, language, created_at, updated_at, sync_version
+		FROM chats WHERE 1=1`
+	args := []interface{}{}
 	if !includeArchived {
-		query += " WHERE archived = 0"
+		query += " AND archived = 0"
+	}
+	if workspace != "" {
+		query += " AND workspace_id = ?"
+		args = append(args, workspace)
+	}
+	if language != "" {
+		query += " AND language = ?"
+		args = append(args, language)
 	}
 	query += " ORDER BY pinned DESC, updated_at DESC"
 
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list chats: %w", err)
 	}
@@ -124,19 +148,26 @@ func ListChats(db *sql.DB, includeArchived bool) ([]Chat, error) {
 	for rows.Next() {
 		var chat Chat
 		var createdAt, updatedAt string
-		var pinned, archived int
-		var systemPromptID sql.NullString
+		var pinned, archived, locked int
+		var systemPromptID, personaID, workspaceID sql.NullString
 
-		if err := rows.Scan(&chat.ID, &chat.Title, &chat.Model, &pinned, &archived, &systemPromptID,
-			&createdAt, &updatedAt, &chat.SyncVersion); err != nil {
+		if err := rows.Scan(&chat.ID, &chat.Title, &chat.Model, &pinned, &archived, &locked, &systemPromptID,
+			&personaID, &workspaceID, &chat.Language, &createdAt, &updatedAt, &chat.SyncVersion); err != nil {
 			return nil, fmt.Errorf("failed to scan chat: %w", err)
 		}
 
 		chat.Pinned = pinned == 1
 		chat.Archived = archived == 1
+		chat.Locked = locked == 1
 		if systemPromptID.Valid {
 			chat.SystemPromptID = &systemPromptID.String
 		}
+		if personaID.Valid {
+			chat.PersonaID = &personaID.String
+		}
+		if workspaceID.Valid {
+			chat.WorkspaceID = &workspaceID.String
+		}
 		chat.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 		chat.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
 		chats = append(chats, chat)
@@ -151,10 +182,10 @@ func UpdateChat(db *sql.DB, chat *Chat) error {
 	chat.SyncVersion++
 
 	result, err := db.Exec(`
-		UPDATE chats SET title = ?, model = ?, pinned = ?, archived = ?, system_prompt_id = ?,
+		UPDATE chats SET title = ?, model = ?, pinned = ?, archived = ?, system_prompt_id = ?, persona_id = ?, workspace_id = ?,
 		updated_at = ?, sync_version = ?
 		WHERE id = ?`,
-		chat.Title, chat.Model, chat.Pinned, chat.Archived, chat.SystemPromptID,
+		chat.Title, chat.Model, chat.Pinned, chat.Archived, chat.SystemPromptID, chat.PersonaID, chat.WorkspaceID,
 		chat.UpdatedAt.Format(time.RFC3339), chat.SyncVersion, chat.ID,
 	)
 	if err != nil {
@@ -169,8 +200,155 @@ func UpdateChat(db *sql.DB, chat *Chat) error {
 	return nil
 }
 
-// DeleteChat deletes a chat and its associated messages
+// GetChatContextStrategy returns the chat's context window strategy
+// ("" means none configured)
+func GetChatContextStrategy(db *sql.DB, id string) (string, error) {
+	var strategy string
+	err := db.QueryRow(`SELECT context_strategy FROM chats WHERE id = ?`, id).Scan(&strategy)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("chat not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get context strategy: %w", err)
+	}
+	return strategy, nil
+}
+
+// SetChatContextStrategy stores the chat's context window strategy
+func SetChatContextStrategy(db *sql.DB, id, strategy string) error {
+	result, err := db.Exec(`UPDATE chats SET context_strategy = ? WHERE id = ?`, strategy, id)
+	if err != nil {
+		return fmt.Errorf("failed to set context strategy: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("chat not found")
+	}
+	return nil
+}
+
+// GetChatSamplingOptions returns the chat's stored advanced sampling
+// options (empty map when none are set)
+func GetChatSamplingOptions(db *sql.DB, id string) (map[string]interface{}, error) {
+	var stored string
+	err := db.QueryRow(`SELECT sampling_options FROM chats WHERE id = ?`, id).Scan(&stored)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("chat not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sampling options: %w", err)
+	}
+
+	options := map[string]interface{}{}
+	if stored != "" {
+		if err := json.Unmarshal([]byte(stored), &options); err != nil {
+			return nil, fmt.Errorf("failed to parse sampling options: %w", err)
+		}
+	}
+	return options, nil
+}
+
+// SetChatSamplingOptions stores the chat's advanced sampling options
+func SetChatSamplingOptions(db *sql.DB, id string, options map[string]interface{}) error {
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("failed to serialize sampling options: %w", err)
+	}
+
+	result, err := db.Exec(`UPDATE chats SET sampling_options = ? WHERE id = ?`, string(encoded), id)
+	if err != nil {
+		return fmt.Errorf("failed to set sampling options: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("chat not found")
+	}
+	return nil
+}
+
+// GetChatTags returns the chat's tags (stored comma-separated)
+func GetChatTags(db *sql.DB, id string) ([]string, error) {
+	var stored string
+	err := db.QueryRow(`SELECT tags FROM chats WHERE id = ?`, id).Scan(&stored)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("chat not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	tags := []string{}
+	for _, tag := range strings.Split(stored, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// AddChatTag appends a tag to the chat unless it already carries it
+func AddChatTag(db *sql.DB, id, tag string) error {
+	tags, err := GetChatTags(db, id)
+	if err != nil {
+		return err
+	}
+	for _, existing := range tags {
+		if existing == tag {
+			return nil
+		}
+	}
+	tags = append(tags, tag)
+
+	_, err = db.Exec(`UPDATE chats SET tags = ? WHERE id = ?`, strings.Join(tags, ","), id)
+	if err != nil {
+		return fmt.Errorf("failed to add tag: %w", err)
+	}
+	return nil
+}
+
+// SetChatLanguage records the detected primary language of a chat.
+// Detection runs once per chat - an already-set language is left alone
+// so later messages in another language don't flip it back and forth.
+func SetChatLanguage(db *sql.DB, id, language string) error {
+	_, err := db.Exec(`UPDATE chats SET language = ? WHERE id = ? AND language = ''`, language, id)
+	if err != nil {
+		return fmt.Errorf("failed to set chat language: %w", err)
+	}
+	return nil
+}
+
+// SetChatLocked marks a chat as locked (or unlocks it). Locked chats
+// are refused by every deletion path until explicitly unlocked.
+func SetChatLocked(db *sql.DB, id string, locked bool) error {
+	result, err := db.Exec(`UPDATE chats SET locked = ? WHERE id = ?`, locked, id)
+	if err != nil {
+		return fmt.Errorf("failed to set chat lock: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("chat not found")
+	}
+	return nil
+}
+
+// DeleteChat deletes a chat and its associated messages. Locked chats
+// are protected - the caller must unlock them first.
 func DeleteChat(db *sql.DB, id string) error {
+	var locked int
+	err := db.QueryRow(`SELECT locked FROM chats WHERE id = ?`, id).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("chat not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check chat lock: %w", err)
+	}
+	if locked == 1 {
+		return fmt.Errorf("chat is locked")
+	}
+
 	result, err := db.Exec("DELETE FROM chats WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("failed to delete chat: %w", err)
@@ -192,10 +370,15 @@ func CreateMessage(db *sql.DB, msg *Message) error {
 	msg.CreatedAt = time.Now().UTC()
 	msg.SyncVersion = 1
 
-	_, err := db.Exec(`
+	storedContent, err := EncodeMessageContent(msg.Content)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt message content: %w", err)
+	}
+
+	_, err = db.Exec(`
 		INSERT INTO messages (id, chat_id, parent_id, role, content, sibling_index, created_at, sync_version)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-		msg.ID, msg.ChatID, msg.ParentID, msg.Role, msg.Content,
+		msg.ID, msg.ChatID, msg.ParentID, msg.Role, storedContent,
 		msg.SiblingIndex, msg.CreatedAt.Format(time.RFC3339), msg.SyncVersion,
 	)
 	if err != nil {
@@ -209,6 +392,31 @@ func CreateMessage(db *sql.DB, msg *Message) error {
 	return nil
 }
 
+// GetMessage retrieves a single message by ID
+func GetMessage(db *sql.DB, id string) (*Message, error) {
+	var msg Message
+	var createdAt string
+	var parentID sql.NullString
+
+	err := db.QueryRow(`
+		SELECT id, chat_id, parent_id, role, content, sibling_index, created_at, sync_version
+		FROM messages WHERE id = ?`, id).Scan(&msg.ID, &msg.ChatID, &parentID, &msg.Role,
+		&msg.Content, &msg.SiblingIndex, &createdAt, &msg.SyncVersion)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	if parentID.Valid {
+		msg.ParentID = &parentID.String
+	}
+	msg.Content = DecodeMessageContent(msg.Content)
+	msg.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	return &msg, nil
+}
+
 // GetMessagesByChatID retrieves all messages for a chat
 func GetMessagesByChatID(db *sql.DB, chatID string) ([]Message, error) {
 	rows, err := db.Query(`
@@ -233,6 +441,7 @@ func GetMessagesByChatID(db *sql.DB, chatID string) ([]Message, error) {
 		if parentID.Valid {
 			msg.ParentID = &parentID.String
 		}
+		msg.Content = DecodeMessageContent(msg.Content)
 		msg.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 		messages = append(messages, msg)
 	}
@@ -243,7 +452,7 @@ func GetMessagesByChatID(db *sql.DB, chatID string) ([]Message, error) {
 // GetChangedChats retrieves chats changed since a given sync version
 func GetChangedChats(db *sql.DB, sinceVersion int64) ([]Chat, error) {
 	rows, err := db.Query(`
-		SELECT id, title, model, pinned, archived, system_prompt_id, created_at, updated_at, sync_version
+		SELECT id, title, model, pinned, archived, system_prompt_id, persona_id, workspace_id, created_at, updated_at, sync_version
 		FROM chats WHERE sync_version > ? ORDER BY sync_version ASC`, sinceVersion)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get changed chats: %w", err)
@@ -255,10 +464,10 @@ func GetChangedChats(db *sql.DB, sinceVersion int64) ([]Chat, error) {
 		var chat Chat
 		var createdAt, updatedAt string
 		var pinned, archived int
-		var systemPromptID sql.NullString
+		var systemPromptID, personaID, workspaceID sql.NullString
 
 		if err := rows.Scan(&chat.ID, &chat.Title, &chat.Model, &pinned, &archived, &systemPromptID,
-			&createdAt, &updatedAt, &chat.SyncVersion); err != nil {
+			&personaID, &workspaceID, &createdAt, &updatedAt, &chat.SyncVersion); err != nil {
 			return nil, fmt.Errorf("failed to scan chat: %w", err)
 		}
 
@@ -267,6 +476,12 @@ func GetChangedChats(db *sql.DB, sinceVersion int64) ([]Chat, error) {
 		if systemPromptID.Valid {
 			chat.SystemPromptID = &systemPromptID.String
 		}
+		if personaID.Valid {
+			chat.PersonaID = &personaID.String
+		}
+		if workspaceID.Valid {
+			chat.WorkspaceID = &workspaceID.String
+		}
 		chat.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 		chat.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
 
@@ -303,9 +518,15 @@ type GroupedChat struct {
 	Model          string    `json:"model"`
 	Pinned         bool      `json:"pinned"`
 	Archived       bool      `json:"archived"`
+	Locked         bool      `json:"locked,omitempty"`
 	SystemPromptID *string   `json:"system_prompt_id,omitempty"`
+	PersonaID      *string   `json:"persona_id,omitempty"`
+	WorkspaceID    *string   `json:"workspace_id,omitempty"`
+	Language       string    `json:"language,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+	MessageCount   int       `json:"message_count"`
+	LastMessage    string    `json:"last_message,omitempty"`
 }
 
 // ChatGroup represents a group of chats with a date label
@@ -321,49 +542,44 @@ type GroupedChatsResponse struct {
 	TotalPinned int         `json:"totalPinned"`
 }
 
-// getDateGroup determines which date group a timestamp belongs to
-func getDateGroup(t time.Time, now time.Time) DateGroup {
-	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	startOfYesterday := startOfToday.AddDate(0, 0, -1)
-
-	// Calculate start of this week (Monday)
-	daysFromMonday := int(now.Weekday()) - 1
-	if daysFromMonday < 0 {
-		daysFromMonday = 6 // Sunday
-	}
-	startOfThisWeek := startOfToday.AddDate(0, 0, -daysFromMonday)
-	startOfLastWeek := startOfThisWeek.AddDate(0, 0, -7)
-
-	// Start of this month and last month
-	startOfThisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	startOfLastMonth := startOfThisMonth.AddDate(0, -1, 0)
-
-	if t.After(startOfToday) || t.Equal(startOfToday) {
-		return DateGroupToday
-	}
-	if t.After(startOfYesterday) || t.Equal(startOfYesterday) {
-		return DateGroupYesterday
-	}
-	if t.After(startOfThisWeek) || t.Equal(startOfThisWeek) {
-		return DateGroupThisWeek
-	}
-	if t.After(startOfLastWeek) || t.Equal(startOfLastWeek) {
-		return DateGroupLastWeek
-	}
-	if t.After(startOfThisMonth) || t.Equal(startOfThisMonth) {
-		return DateGroupThisMonth
-	}
-	if t.After(startOfLastMonth) || t.Equal(startOfLastMonth) {
-		return DateGroupLastMonth
-	}
-	return DateGroupOlder
+// dateGroupSQL labels a chat's updated_at with its date group directly
+// in SQL, so the grouped listing stays a single query. RFC3339 UTC
+// timestamps compare lexicographically against date() boundaries; weeks
+// start on Monday ('weekday 0' advances to Sunday, minus six days).
+const dateGroupSQL = `CASE
+	WHEN updated_at >= date('now') THEN 'Today'
+	WHEN updated_at >= date('now', '-1 day') THEN 'Yesterday'
+	WHEN updated_at >= date('now', 'weekday 0', '-6 days') THEN 'This Week'
+	WHEN updated_at >= date('now', 'weekday 0', '-13 days') THEN 'Last Week'
+	WHEN updated_at >= date('now', 'start of month') THEN 'This Month'
+	WHEN updated_at >= date('now', 'start of month', '-1 month') THEN 'Last Month'
+	ELSE 'Older'
+END`
+
+// previewRuneLimit caps the last-message preview in the sidebar listing
+const previewRuneLimit = 120
+
+// previewText trims a decoded message down to a short sidebar preview
+func previewText(content string) string {
+	runes := []rune(content)
+	if len(runes) > previewRuneLimit {
+		return string(runes[:previewRuneLimit]) + "…"
+	}
+	return content
 }
 
-// ListChatsGrouped retrieves chats grouped by date with search/filter support
-func ListChatsGrouped(db *sql.DB, search string, includeArchived bool, limit, offset int) (*GroupedChatsResponse, error) {
+// ListChatsGrouped retrieves chats grouped by date with search/filter
+// support. Message counts, last-message previews, and date-group labels
+// all come from the one listing query, so the sidebar stays fast on
+// databases with thousands of chats.
+func ListChatsGrouped(db *sql.DB, search, workspace, language string, includeArchived bool, limit, offset int) (*GroupedChatsResponse, error) {
 	// Build query with optional search filter
 	query := `
-		SELECT id, title, model, pinned, archived, system_prompt_id, created_at, updated_at
+		SELECT id, title, model, pinned, archived, locked, system_prompt_id, persona_id, workspace_id, language, created_at, updated_at,
+			(SELECT COUNT(*) FROM messages m WHERE m.chat_id = chats.id) AS message_count,
+			COALESCE((SELECT m.content FROM messages m WHERE m.chat_id = chats.id
+				ORDER BY m.created_at DESC LIMIT 1), '') AS last_message,
+			` + dateGroupSQL + ` AS date_group
 		FROM chats
 		WHERE 1=1`
 	args := []interface{}{}
@@ -377,6 +593,16 @@ func ListChatsGrouped(db *sql.DB, search string, includeArchived bool, limit, of
 		args = append(args, "%"+search+"%")
 	}
 
+	if workspace != "" {
+		query += " AND workspace_id = ?"
+		args = append(args, workspace)
+	}
+
+	if language != "" {
+		query += " AND language = ?"
+		args = append(args, language)
+	}
+
 	// Always sort: pinned first, then by updated_at desc
 	query += " ORDER BY pinned DESC, updated_at DESC"
 
@@ -396,32 +622,7 @@ func ListChatsGrouped(db *sql.DB, search string, includeArchived bool, limit, of
 	}
 	defer rows.Close()
 
-	// Collect all chats first
-	var chats []GroupedChat
-	now := time.Now()
-
-	for rows.Next() {
-		var chat GroupedChat
-		var createdAt, updatedAt string
-		var pinned, archived int
-		var systemPromptID sql.NullString
-
-		if err := rows.Scan(&chat.ID, &chat.Title, &chat.Model, &pinned, &archived, &systemPromptID,
-			&createdAt, &updatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan chat: %w", err)
-		}
-
-		chat.Pinned = pinned == 1
-		chat.Archived = archived == 1
-		if systemPromptID.Valid {
-			chat.SystemPromptID = &systemPromptID.String
-		}
-		chat.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
-		chat.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
-		chats = append(chats, chat)
-	}
-
-	// Group chats by date
+	// Group chats by the SQL-computed label as they stream in
 	orderedGroups := []DateGroup{
 		DateGroupToday,
 		DateGroupYesterday,
@@ -438,12 +639,38 @@ func ListChatsGrouped(db *sql.DB, search string, includeArchived bool, limit, of
 	}
 
 	totalPinned := 0
-	for _, chat := range chats {
+	for rows.Next() {
+		var chat GroupedChat
+		var createdAt, updatedAt, lastMessage, dateGroup string
+		var pinned, archived, locked int
+		var systemPromptID, personaID, workspaceID sql.NullString
+
+		if err := rows.Scan(&chat.ID, &chat.Title, &chat.Model, &pinned, &archived, &locked, &systemPromptID,
+			&personaID, &workspaceID, &chat.Language, &createdAt, &updatedAt,
+			&chat.MessageCount, &lastMessage, &dateGroup); err != nil {
+			return nil, fmt.Errorf("failed to scan chat: %w", err)
+		}
+
+		chat.Pinned = pinned == 1
+		chat.Archived = archived == 1
+		chat.Locked = locked == 1
+		if systemPromptID.Valid {
+			chat.SystemPromptID = &systemPromptID.String
+		}
+		if personaID.Valid {
+			chat.PersonaID = &personaID.String
+		}
+		if workspaceID.Valid {
+			chat.WorkspaceID = &workspaceID.String
+		}
+		chat.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		chat.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+		chat.LastMessage = previewText(DecodeMessageContent(lastMessage))
+
 		if chat.Pinned {
 			totalPinned++
 		}
-		group := getDateGroup(chat.UpdatedAt, now)
-		groupMap[group] = append(groupMap[group], chat)
+		groupMap[DateGroup(dateGroup)] = append(groupMap[DateGroup(dateGroup)], chat)
 	}
 
 	// Build result with non-empty groups only
@@ -467,6 +694,14 @@ func ListChatsGrouped(db *sql.DB, search string, includeArchived bool, limit, of
 		countQuery += " AND title LIKE ?"
 		countArgs = append(countArgs, "%"+search+"%")
 	}
+	if workspace != "" {
+		countQuery += " AND workspace_id = ?"
+		countArgs = append(countArgs, workspace)
+	}
+	if language != "" {
+		countQuery += " AND language = ?"
+		countArgs = append(countArgs, language)
+	}
 
 	var total int
 	err = db.QueryRow(countQuery, countArgs...).Scan(&total)
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CollectionEmbedding records which embedding model produced a knowledge
+// collection's vectors. The vectors themselves live client-side; this
+// registry lets the backend spot collections embedded with a model the
+// user has since switched away from.
+type CollectionEmbedding struct {
+	CollectionID string    `json:"collection_id"`
+	Name         string    `json:"name"`
+	EmbedModel   string    `json:"embed_model"`
+	Vectors      int       `json:"vectors"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// UpsertCollectionEmbedding records or refreshes a collection's
+// embedding model registration
+func UpsertCollectionEmbedding(db *sql.DB, entry *CollectionEmbedding) error {
+	entry.UpdatedAt = time.Now().UTC()
+	_, err := db.Exec(`
+		INSERT INTO collection_embeddings (collection_id, name, embed_model, vectors, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(collection_id) DO UPDATE SET
+			name = excluded.name,
+			embed_model = excluded.embed_model,
+			vectors = excluded.vectors,
+			updated_at = excluded.updated_at`,
+		entry.CollectionID, entry.Name, entry.EmbedModel, entry.Vectors,
+		entry.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert collection embedding: %w", err)
+	}
+	return nil
+}
+
+// ListCollectionEmbeddings returns all registered collections ordered by
+// name
+func ListCollectionEmbeddings(db *sql.DB) ([]CollectionEmbedding, error) {
+	rows, err := db.Query(`
+		SELECT collection_id, name, embed_model, vectors, updated_at
+		FROM collection_embeddings ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []CollectionEmbedding{}
+	for rows.Next() {
+		var entry CollectionEmbedding
+		var updatedAt string
+		if err := rows.Scan(&entry.CollectionID, &entry.Name, &entry.EmbedModel,
+			&entry.Vectors, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan collection embedding: %w", err)
+		}
+		entry.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// DeleteCollectionEmbedding removes a collection's registration
+func DeleteCollectionEmbedding(db *sql.DB, collectionID string) error {
+	result, err := db.Exec(`DELETE FROM collection_embeddings WHERE collection_id = ?`, collectionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection embedding: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("collection not found")
+	}
+	return nil
+}
+
+// SetCollectionEmbeddingModel switches a collection's registry entry to
+// a new model, preserving its name; used when a migration completes.
+// Unregistered collections get a nameless entry rather than an error.
+func SetCollectionEmbeddingModel(db *sql.DB, collectionID, model string, vectors int) error {
+	result, err := db.Exec(`
+		UPDATE collection_embeddings SET embed_model = ?, vectors = ?, updated_at = ?
+		WHERE collection_id = ?`,
+		model, vectors, time.Now().UTC().Format(time.RFC3339), collectionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set collection embedding model: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return UpsertCollectionEmbedding(db, &CollectionEmbedding{
+			CollectionID: collectionID,
+			EmbedModel:   model,
+			Vectors:      vectors,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+
+	"vessel-backend/internal/config"
+	"vessel-backend/internal/crypto"
+)
+
+// encryptedPrefix marks stored values that are ciphertext. Rows written
+// before encryption was enabled keep their plaintext and still read fine,
+// so the switch can be flipped on an existing database.
+const encryptedPrefix = "enc:v1:"
+
+// contentEncryptionEnabled reports whether message content and attachment
+// data should be encrypted at rest. Configured via database.encrypt_content
+// or DB_ENCRYPT_CONTENT; the key comes from ENCRYPTION_KEY / the key file.
+func contentEncryptionEnabled() bool {
+	return config.Get().Database.EncryptContent
+}
+
+// EncodeMessageContent encrypts message content for storage when encryption is on
+func EncodeMessageContent(content string) (string, error) {
+	if !contentEncryptionEnabled() || content == "" {
+		return content, nil
+	}
+	ciphertext, err := crypto.Encrypt([]byte(content))
+	if err != nil {
+		return "", err
+	}
+	return encryptedPrefix + ciphertext, nil
+}
+
+// DecodeMessageContent reverses EncodeMessageContent. Unprefixed values pass through so
+// plaintext rows from before the switch keep working; undecryptable rows
+// (wrong key) come back as a marker rather than failing the whole query.
+func DecodeMessageContent(stored string) string {
+	if !strings.HasPrefix(stored, encryptedPrefix) {
+		return stored
+	}
+	plaintext, err := crypto.Decrypt(strings.TrimPrefix(stored, encryptedPrefix))
+	if err != nil {
+		return "[encrypted: unable to decrypt with current key]"
+	}
+	return string(plaintext)
+}
+
+// EncodeAttachmentData encrypts attachment bytes for storage when encryption
+// is on
+func EncodeAttachmentData(data []byte) ([]byte, error) {
+	if !contentEncryptionEnabled() || len(data) == 0 {
+		return data, nil
+	}
+	ciphertext, err := crypto.Encrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(encryptedPrefix + ciphertext), nil
+}
+
+// DecodeAttachmentData reverses EncodeAttachmentData, passing plaintext through
+func DecodeAttachmentData(stored []byte) []byte {
+	if !strings.HasPrefix(string(stored), encryptedPrefix) {
+		return stored
+	}
+	plaintext, err := crypto.Decrypt(strings.TrimPrefix(string(stored), encryptedPrefix))
+	if err != nil {
+		return nil
+	}
+	return plaintext
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vessel-backend/internal/crypto"
+)
+
+// FetchProfile represents a saved credential profile for fetching
+// authenticated pages. The secret material is never included in JSON output.
+type FetchProfile struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Domain    string    `json:"domain"`
+	AuthType  string    `json:"auth_type"` // "cookie", "bearer", or "basic"
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FetchProfileSecret holds the credential material, stored encrypted at rest
+type FetchProfileSecret struct {
+	Cookies  string `json:"cookies,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// CreateFetchProfile creates a new fetch profile with an encrypted secret
+func CreateFetchProfile(db *sql.DB, profile *FetchProfile, secret *FetchProfileSecret) error {
+	if profile.ID == "" {
+		profile.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	profile.CreatedAt = now
+	profile.UpdatedAt = now
+
+	plaintext, err := json.Marshal(secret)
+	if err != nil {
+		return fmt.Errorf("failed to serialize secret: %w", err)
+	}
+	encrypted, err := crypto.Encrypt(plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO fetch_profiles (id, name, domain, auth_type, secret, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		profile.ID, profile.Name, profile.Domain, profile.AuthType, encrypted,
+		profile.CreatedAt.Format(time.RFC3339), profile.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create fetch profile: %w", err)
+	}
+	return nil
+}
+
+// ListFetchProfiles retrieves all fetch profiles without secret material
+func ListFetchProfiles(db *sql.DB) ([]FetchProfile, error) {
+	rows, err := db.Query(`
+		SELECT id, name, domain, auth_type, created_at, updated_at
+		FROM fetch_profiles ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fetch profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []FetchProfile
+	for rows.Next() {
+		var profile FetchProfile
+		var createdAt, updatedAt string
+
+		if err := rows.Scan(&profile.ID, &profile.Name, &profile.Domain, &profile.AuthType,
+			&createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan fetch profile: %w", err)
+		}
+
+		profile.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		profile.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, nil
+}
+
+// GetFetchProfileByName retrieves a fetch profile and its decrypted secret
+func GetFetchProfileByName(db *sql.DB, name string) (*FetchProfile, *FetchProfileSecret, error) {
+	profile := &FetchProfile{}
+	var encrypted, createdAt, updatedAt string
+
+	err := db.QueryRow(`
+		SELECT id, name, domain, auth_type, secret, created_at, updated_at
+		FROM fetch_profiles WHERE name = ?`, name).Scan(
+		&profile.ID, &profile.Name, &profile.Domain, &profile.AuthType, &encrypted,
+		&createdAt, &updatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get fetch profile: %w", err)
+	}
+
+	profile.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	profile.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+
+	plaintext, err := crypto.Decrypt(encrypted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	secret := &FetchProfileSecret{}
+	if err := json.Unmarshal(plaintext, secret); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse secret: %w", err)
+	}
+
+	return profile, secret, nil
+}
+
+// DeleteFetchProfile deletes a fetch profile by ID
+func DeleteFetchProfile(db *sql.DB, id string) error {
+	result, err := db.Exec("DELETE FROM fetch_profiles WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete fetch profile: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("fetch profile not found")
+	}
+
+	return nil
+}
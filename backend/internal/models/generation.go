@@ -0,0 +1,73 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MessageGeneration is the exact model and sampling options an assistant
+// message was generated with, stored so the generation can be replayed
+// with identical parameters
+type MessageGeneration struct {
+	MessageID string                 `json:"message_id"`
+	Model     string                 `json:"model"`
+	Options   map[string]interface{} `json:"options"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// SetMessageGeneration records generation parameters for a message,
+// replacing any earlier record
+func SetMessageGeneration(db *sql.DB, messageID, model string, options map[string]interface{}) error {
+	var exists int
+	err := db.QueryRow(`SELECT COUNT(*) FROM messages WHERE id = ?`, messageID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to set generation: %w", err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("message not found")
+	}
+
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("failed to encode options: %w", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO message_generations (message_id, model, options, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(message_id) DO UPDATE SET model = excluded.model, options = excluded.options`,
+		messageID, model, string(encoded), time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set generation: %w", err)
+	}
+	return nil
+}
+
+// GetMessageGeneration returns the recorded generation parameters for a
+// message, or nil when none were recorded
+func GetMessageGeneration(db *sql.DB, messageID string) (*MessageGeneration, error) {
+	gen := &MessageGeneration{MessageID: messageID}
+	var options, createdAt string
+
+	err := db.QueryRow(`
+		SELECT model, options, created_at FROM message_generations WHERE message_id = ?`,
+		messageID).Scan(&gen.Model, &options, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get generation: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(options), &gen.Options); err != nil {
+		gen.Options = map[string]interface{}{}
+	}
+	gen.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	return gen, nil
+}
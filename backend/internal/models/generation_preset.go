@@ -0,0 +1,144 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GenerationPreset is a named bundle of Ollama sampling options
+// ("creative", "precise"...) selectable per completion via ?preset=
+type GenerationPreset struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Options     map[string]interface{} `json:"options"`
+	CreatedAt   string                 `json:"created_at"`
+	UpdatedAt   string                 `json:"updated_at"`
+}
+
+// CreateGenerationPreset stores a new preset
+func CreateGenerationPreset(db *sql.DB, preset *GenerationPreset) error {
+	if preset.ID == "" {
+		preset.ID = uuid.New().String()
+	}
+	if preset.Options == nil {
+		preset.Options = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(preset.Options)
+	if err != nil {
+		return fmt.Errorf("failed to serialize preset options: %w", err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	preset.CreatedAt = now
+	preset.UpdatedAt = now
+
+	_, err = db.Exec(`
+		INSERT INTO generation_presets (id, name, description, options, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		preset.ID, preset.Name, preset.Description, string(encoded), preset.CreatedAt, preset.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create preset: %w", err)
+	}
+	return nil
+}
+
+// ListGenerationPresets returns every preset ordered by name
+func ListGenerationPresets(db *sql.DB) ([]GenerationPreset, error) {
+	rows, err := db.Query(`
+		SELECT id, name, description, options, created_at, updated_at
+		FROM generation_presets ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list presets: %w", err)
+	}
+	defer rows.Close()
+
+	presets := []GenerationPreset{}
+	for rows.Next() {
+		preset, err := scanGenerationPreset(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, *preset)
+	}
+	return presets, nil
+}
+
+// GetGenerationPreset retrieves a preset by ID or name, so ?preset=creative
+// and ?preset=<uuid> both resolve
+func GetGenerationPreset(db *sql.DB, idOrName string) (*GenerationPreset, error) {
+	row := db.QueryRow(`
+		SELECT id, name, description, options, created_at, updated_at
+		FROM generation_presets WHERE id = ? OR name = ?`, idOrName, idOrName)
+	preset, err := scanGenerationPreset(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return preset, nil
+}
+
+// UpdateGenerationPreset updates a preset's name, description, and options
+func UpdateGenerationPreset(db *sql.DB, preset *GenerationPreset) error {
+	if preset.Options == nil {
+		preset.Options = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(preset.Options)
+	if err != nil {
+		return fmt.Errorf("failed to serialize preset options: %w", err)
+	}
+	preset.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	result, err := db.Exec(`
+		UPDATE generation_presets SET name = ?, description = ?, options = ?, updated_at = ?
+		WHERE id = ?`,
+		preset.Name, preset.Description, string(encoded), preset.UpdatedAt, preset.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update preset: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("preset not found")
+	}
+	return nil
+}
+
+// DeleteGenerationPreset removes a preset
+func DeleteGenerationPreset(db *sql.DB, id string) error {
+	result, err := db.Exec(`DELETE FROM generation_presets WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete preset: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("preset not found")
+	}
+	return nil
+}
+
+// scanGenerationPreset reads one preset row via the given scan function
+func scanGenerationPreset(scan func(...interface{}) error) (*GenerationPreset, error) {
+	preset := &GenerationPreset{}
+	var options string
+	if err := scan(&preset.ID, &preset.Name, &preset.Description, &options,
+		&preset.CreatedAt, &preset.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan preset: %w", err)
+	}
+	preset.Options = map[string]interface{}{}
+	if options != "" {
+		if err := json.Unmarshal([]byte(options), &preset.Options); err != nil {
+			return nil, fmt.Errorf("failed to parse preset options: %w", err)
+		}
+	}
+	return preset, nil
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GuardrailEvent is one moderation pipeline hit: which rule fired, what it
+// did, and a short excerpt of the matched text
+type GuardrailEvent struct {
+	ID        string `json:"id"`
+	Rule      string `json:"rule"`
+	Action    string `json:"action"`
+	Direction string `json:"direction"`
+	Model     string `json:"model,omitempty"`
+	Excerpt   string `json:"excerpt,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// RecordGuardrailEvent logs one pipeline hit
+func RecordGuardrailEvent(db *sql.DB, event *GuardrailEvent) error {
+	event.ID = uuid.New().String()
+	event.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	_, err := db.Exec(`
+		INSERT INTO guardrail_events (id, rule, action, direction, model, excerpt, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		event.ID, event.Rule, event.Action, event.Direction, event.Model, event.Excerpt, event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record guardrail event: %w", err)
+	}
+	return nil
+}
+
+// ListGuardrailEvents returns recent events, newest first, optionally
+// filtered by rule name
+func ListGuardrailEvents(db *sql.DB, rule string, limit int) ([]GuardrailEvent, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT id, rule, action, direction, model, excerpt, created_at
+		FROM guardrail_events`
+	args := []interface{}{}
+	if rule != "" {
+		query += " WHERE rule = ?"
+		args = append(args, rule)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list guardrail events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []GuardrailEvent
+	for rows.Next() {
+		var e GuardrailEvent
+		if err := rows.Scan(&e.ID, &e.Rule, &e.Action, &e.Direction, &e.Model, &e.Excerpt, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan guardrail event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,276 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job is one unit of background work in the persistent queue. Rows stay
+// around after completion and double as the job history.
+type Job struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+	Status      string          `json:"status"`
+	Error       string          `json:"error,omitempty"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	ScheduledAt string          `json:"scheduled_at"`
+	StartedAt   *string         `json:"started_at,omitempty"`
+	FinishedAt  *string         `json:"finished_at,omitempty"`
+	CreatedAt   string          `json:"created_at"`
+}
+
+// CreateJob enqueues a new job. An empty payload is stored as '{}' and a
+// zero maxAttempts means one attempt.
+func CreateJob(db *sql.DB, kind string, payload json.RawMessage, maxAttempts int, scheduledAt time.Time) (*Job, error) {
+	if len(payload) == 0 {
+		payload = json.RawMessage(`{}`)
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	job := &Job{
+		ID:          uuid.New().String(),
+		Kind:        kind,
+		Payload:     payload,
+		Status:      "queued",
+		MaxAttempts: maxAttempts,
+		ScheduledAt: scheduledAt.UTC().Format(time.RFC3339),
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO jobs (id, kind, payload, status, max_attempts, scheduled_at, created_at)
+		VALUES (?, ?, ?, 'queued', ?, ?, ?)`,
+		job.ID, job.Kind, string(job.Payload), job.MaxAttempts, job.ScheduledAt, job.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+	return job, nil
+}
+
+// ClaimNextJob atomically picks the oldest due queued job and marks it
+// running. It returns nil without error when nothing is due.
+func ClaimNextJob(db *sql.DB) (*Job, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow(`
+		SELECT id, kind, payload, status, error, attempts, max_attempts, scheduled_at, started_at, finished_at, created_at
+		FROM jobs
+		WHERE status = 'queued' AND scheduled_at <= ?
+		ORDER BY scheduled_at ASC
+		LIMIT 1`, now)
+
+	job, err := scanJob(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	job.Status = "running"
+	job.Attempts++
+	started := now
+	job.StartedAt = &started
+	_, err = tx.Exec(`
+		UPDATE jobs SET status = 'running', attempts = attempts + 1, started_at = ?
+		WHERE id = ?`, now, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return job, nil
+}
+
+// FinishJob records the outcome of a running job
+func FinishJob(db *sql.DB, id, status, errMsg string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	result, err := db.Exec(`
+		UPDATE jobs SET status = ?, error = ?, finished_at = ?
+		WHERE id = ? AND status = 'running'`,
+		status, errMsg, now, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("job not found")
+	}
+	return nil
+}
+
+// RequeueJob puts a failed attempt back in the queue for a later retry
+func RequeueJob(db *sql.DB, id, errMsg string, at time.Time) error {
+	_, err := db.Exec(`
+		UPDATE jobs SET status = 'queued', error = ?, scheduled_at = ?
+		WHERE id = ? AND status = 'running'`,
+		errMsg, at.UTC().Format(time.RFC3339), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to requeue job: %w", err)
+	}
+	return nil
+}
+
+// DeferJob puts a job that could not run yet back in the queue for a
+// later time; unlike a retry, the deferral hands the attempt back
+func DeferJob(db *sql.DB, id, reason string, at time.Time) error {
+	_, err := db.Exec(`
+		UPDATE jobs SET status = 'queued', error = ?, scheduled_at = ?, attempts = attempts - 1
+		WHERE id = ? AND status = 'running'`,
+		reason, at.UTC().Format(time.RFC3339), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to defer job: %w", err)
+	}
+	return nil
+}
+
+// CancelQueuedJob cancels a job that has not started yet. Running jobs
+// are cancelled through their context, not here.
+func CancelQueuedJob(db *sql.DB, id string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	result, err := db.Exec(`
+		UPDATE jobs SET status = 'cancelled', finished_at = ?
+		WHERE id = ? AND status = 'queued'`, now, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("job not queued")
+	}
+	return nil
+}
+
+// RetryJob re-queues a failed or cancelled job for an immediate attempt,
+// resetting its attempt counter
+func RetryJob(db *sql.DB, id string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	result, err := db.Exec(`
+		UPDATE jobs SET status = 'queued', error = '', attempts = 0, scheduled_at = ?, started_at = NULL, finished_at = NULL
+		WHERE id = ? AND status IN ('failed', 'cancelled')`, now, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to retry job: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("job not failed or cancelled")
+	}
+	return nil
+}
+
+// GetJob returns a single job by ID
+func GetJob(db *sql.DB, id string) (*Job, error) {
+	row := db.QueryRow(`
+		SELECT id, kind, payload, status, error, attempts, max_attempts, scheduled_at, started_at, finished_at, created_at
+		FROM jobs WHERE id = ?`, id)
+
+	job, err := scanJob(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// ListJobs returns jobs newest first, optionally filtered by kind and
+// status; limit defaults to 50
+func ListJobs(db *sql.DB, kind, status string, limit int) ([]Job, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `
+		SELECT id, kind, payload, status, error, attempts, max_attempts, scheduled_at, started_at, finished_at, created_at
+		FROM jobs`
+	var conditions []string
+	var args []interface{}
+	if kind != "" {
+		conditions = append(conditions, "kind = ?")
+		args = append(args, kind)
+	}
+	if status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, status)
+	}
+	for i, cond := range conditions {
+		if i == 0 {
+			query += " WHERE " + cond
+		} else {
+			query += " AND " + cond
+		}
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		job, err := scanJob(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// ResetRunningJobs re-queues jobs left in the running state by an unclean
+// shutdown; called once at startup before workers begin polling
+func ResetRunningJobs(db *sql.DB) error {
+	_, err := db.Exec(`UPDATE jobs SET status = 'queued', attempts = attempts - 1 WHERE status = 'running'`)
+	if err != nil {
+		return fmt.Errorf("failed to reset running jobs: %w", err)
+	}
+	return nil
+}
+
+// scanJob scans a job row from either sql.Row or sql.Rows
+func scanJob(scan func(...interface{}) error) (*Job, error) {
+	var job Job
+	var payload string
+	var startedAt, finishedAt sql.NullString
+
+	err := scan(&job.ID, &job.Kind, &payload, &job.Status, &job.Error, &job.Attempts,
+		&job.MaxAttempts, &job.ScheduledAt, &startedAt, &finishedAt, &job.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Payload = json.RawMessage(payload)
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.String
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.String
+	}
+	return &job, nil
+}
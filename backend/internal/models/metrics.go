@@ -0,0 +1,170 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GenerationMetric is one completed generation's usage record
+type GenerationMetric struct {
+	ID               string    `json:"id"`
+	Model            string    `json:"model"`
+	User             string    `json:"user"`
+	Kind             string    `json:"kind"` // "chat" or "generate"
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	DurationMs       int64     `json:"duration_ms"`
+	FirstTokenMs     int64     `json:"first_token_ms"`
+	QueueMs          int64     `json:"queue_ms"`
+	ToolCalls        int       `json:"tool_calls"`
+	CostUSD          float64   `json:"cost_usd"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// UsageRollup aggregates generation metrics along one dimension (a day,
+// a model, or a user)
+type UsageRollup struct {
+	Key              string  `json:"key"`
+	Generations      int64   `json:"generations"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	DurationMs       int64   `json:"duration_ms"`
+	ToolCalls        int64   `json:"tool_calls"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// RecordGeneration inserts one usage record
+func RecordGeneration(db *sql.DB, metric *GenerationMetric) error {
+	if metric.ID == "" {
+		metric.ID = uuid.New().String()
+	}
+	if metric.Kind == "" {
+		metric.Kind = "chat"
+	}
+	metric.CreatedAt = time.Now().UTC()
+
+	_, err := db.Exec(`
+		INSERT INTO generation_metrics (id, model, user, kind, prompt_tokens, completion_tokens, duration_ms, first_token_ms, queue_ms, tool_calls, cost_usd, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		metric.ID, metric.Model, metric.User, metric.Kind,
+		metric.PromptTokens, metric.CompletionTokens, metric.DurationMs,
+		metric.FirstTokenMs, metric.QueueMs, metric.ToolCalls,
+		metric.CostUSD, metric.CreatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record generation metric: %w", err)
+	}
+	return nil
+}
+
+// usageDimensions maps the rollup dimension to its SQL grouping expression
+var usageDimensions = map[string]string{
+	"day":   `substr(created_at, 1, 10)`,
+	"model": `model`,
+	"user":  `user`,
+}
+
+// UsageRollupBy aggregates metrics since the given time along one of the
+// supported dimensions: "day", "model", or "user"
+func UsageRollupBy(db *sql.DB, dimension string, since time.Time) ([]UsageRollup, error) {
+	expr, ok := usageDimensions[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unsupported rollup dimension: %s", dimension)
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT %s AS key,
+			COUNT(*),
+			COALESCE(SUM(prompt_tokens), 0),
+			COALESCE(SUM(completion_tokens), 0),
+			COALESCE(SUM(duration_ms), 0),
+			COALESCE(SUM(tool_calls), 0),
+			COALESCE(SUM(cost_usd), 0)
+		FROM generation_metrics
+		WHERE created_at >= ?
+		GROUP BY key
+		ORDER BY key DESC`, expr),
+		since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll up usage: %w", err)
+	}
+	defer rows.Close()
+
+	rollups := []UsageRollup{}
+	for rows.Next() {
+		var rollup UsageRollup
+		if err := rows.Scan(&rollup.Key, &rollup.Generations, &rollup.PromptTokens,
+			&rollup.CompletionTokens, &rollup.DurationMs, &rollup.ToolCalls, &rollup.CostUSD); err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, rollup)
+	}
+	return rollups, rows.Err()
+}
+
+// ListGenerationMetrics returns raw metric rows since the given time,
+// oldest first (used by the CSV export)
+func ListGenerationMetrics(db *sql.DB, since time.Time) ([]GenerationMetric, error) {
+	rows, err := db.Query(`
+		SELECT id, model, user, kind, prompt_tokens, completion_tokens, duration_ms, first_token_ms, queue_ms, tool_calls, cost_usd, created_at
+		FROM generation_metrics
+		WHERE created_at >= ?
+		ORDER BY created_at ASC`,
+		since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list generation metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var metrics []GenerationMetric
+	for rows.Next() {
+		var metric GenerationMetric
+		var createdAt string
+		if err := rows.Scan(&metric.ID, &metric.Model, &metric.User, &metric.Kind,
+			&metric.PromptTokens, &metric.CompletionTokens, &metric.DurationMs,
+			&metric.FirstTokenMs, &metric.QueueMs,
+			&metric.ToolCalls, &metric.CostUSD, &createdAt); err != nil {
+			return nil, err
+		}
+		metric.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		metrics = append(metrics, metric)
+	}
+	return metrics, rows.Err()
+}
+
+// MonthlyCost is one user's estimated spend in one calendar month
+type MonthlyCost struct {
+	Month       string  `json:"month"` // "2025-06"
+	User        string  `json:"user"`
+	Generations int64   `json:"generations"`
+	CostUSD     float64 `json:"cost_usd"`
+}
+
+// MonthlyCostTotals aggregates estimated costs per calendar month and
+// user, skipping months where nothing cost anything
+func MonthlyCostTotals(db *sql.DB) ([]MonthlyCost, error) {
+	rows, err := db.Query(`
+		SELECT substr(created_at, 1, 7) AS month, user,
+			COUNT(*), COALESCE(SUM(cost_usd), 0) AS total
+		FROM generation_metrics
+		GROUP BY month, user
+		HAVING total > 0
+		ORDER BY month DESC, user ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total costs: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []MonthlyCost{}
+	for rows.Next() {
+		var total MonthlyCost
+		if err := rows.Scan(&total.Month, &total.User, &total.Generations, &total.CostUSD); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+	return totals, rows.Err()
+}
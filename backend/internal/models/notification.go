@@ -0,0 +1,107 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification is one in-app notification shown in the UI's notification
+// center, fed by system events
+type Notification struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Body      string `json:"body,omitempty"`
+	Read      bool   `json:"read"`
+	CreatedAt string `json:"created_at"`
+}
+
+// CreateNotification stores a new unread notification
+func CreateNotification(db *sql.DB, notifType, title, body string) (*Notification, error) {
+	n := &Notification{
+		ID:        uuid.New().String(),
+		Type:      notifType,
+		Title:     title,
+		Body:      body,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO notifications (id, type, title, body, read, created_at)
+		VALUES (?, ?, ?, ?, 0, ?)`,
+		n.ID, n.Type, n.Title, n.Body, n.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notification: %w", err)
+	}
+	return n, nil
+}
+
+// ListNotifications returns notifications newest first; unreadOnly limits
+// the list to unread ones and limit defaults to 50
+func ListNotifications(db *sql.DB, unreadOnly bool, limit int) ([]Notification, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `
+		SELECT id, type, title, body, read, created_at
+		FROM notifications`
+	if unreadOnly {
+		query += " WHERE read = 0"
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+
+	rows, err := db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []Notification
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.Type, &n.Title, &n.Body, &n.Read, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// CountUnreadNotifications returns how many notifications are unread
+func CountUnreadNotifications(db *sql.DB) (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM notifications WHERE read = 0`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkNotificationRead marks one notification as read
+func MarkNotificationRead(db *sql.DB, id string) error {
+	result, err := db.Exec(`UPDATE notifications SET read = 1 WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("notification not found")
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead marks every unread notification as read and
+// returns how many were affected
+func MarkAllNotificationsRead(db *sql.DB) (int, error) {
+	result, err := db.Exec(`UPDATE notifications SET read = 1 WHERE read = 0`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Persona is a named assistant configuration: system prompt, default
+// model, generation parameters, enabled tools, and attached knowledge
+// collections. Chats created "as" a persona reference it by ID.
+type Persona struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Avatar       string                 `json:"avatar"`
+	SystemPrompt string                 `json:"system_prompt"`
+	Model        string                 `json:"model"`
+	Parameters   map[string]interface{} `json:"parameters"`
+	Tools        []string               `json:"tools"`
+	Knowledge    []string               `json:"knowledge"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+// CreatePersona creates a new persona
+func CreatePersona(db *sql.DB, persona *Persona) error {
+	if persona.ID == "" {
+		persona.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	persona.CreatedAt = now
+	persona.UpdatedAt = now
+
+	parameters, tools, knowledge, err := marshalPersonaFields(persona)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO personas (id, name, avatar, system_prompt, model, parameters, tools, knowledge, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		persona.ID, persona.Name, persona.Avatar, persona.SystemPrompt, persona.Model,
+		parameters, tools, knowledge,
+		persona.CreatedAt.Format(time.RFC3339), persona.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create persona: %w", err)
+	}
+	return nil
+}
+
+// ListPersonas retrieves all personas ordered by name
+func ListPersonas(db *sql.DB) ([]Persona, error) {
+	rows, err := db.Query(`
+		SELECT id, name, avatar, system_prompt, model, parameters, tools, knowledge, created_at, updated_at
+		FROM personas ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personas: %w", err)
+	}
+	defer rows.Close()
+
+	var personas []Persona
+	for rows.Next() {
+		persona, err := scanPersona(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		personas = append(personas, *persona)
+	}
+	return personas, rows.Err()
+}
+
+// GetPersona retrieves a single persona by ID, or nil when missing
+func GetPersona(db *sql.DB, id string) (*Persona, error) {
+	row := db.QueryRow(`
+		SELECT id, name, avatar, system_prompt, model, parameters, tools, knowledge, created_at, updated_at
+		FROM personas WHERE id = ?`, id)
+
+	persona, err := scanPersona(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return persona, err
+}
+
+// UpdatePersona updates an existing persona
+func UpdatePersona(db *sql.DB, persona *Persona) error {
+	persona.UpdatedAt = time.Now().UTC()
+
+	parameters, tools, knowledge, err := marshalPersonaFields(persona)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec(`
+		UPDATE personas SET name = ?, avatar = ?, system_prompt = ?, model = ?,
+		parameters = ?, tools = ?, knowledge = ?, updated_at = ?
+		WHERE id = ?`,
+		persona.Name, persona.Avatar, persona.SystemPrompt, persona.Model,
+		parameters, tools, knowledge,
+		persona.UpdatedAt.Format(time.RFC3339), persona.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update persona: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("persona not found")
+	}
+	return nil
+}
+
+// DeletePersona deletes a persona by ID. Chats keep their persona_id;
+// lookups against a deleted persona simply find nothing.
+func DeletePersona(db *sql.DB, id string) error {
+	result, err := db.Exec(`DELETE FROM personas WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete persona: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("persona not found")
+	}
+	return nil
+}
+
+// marshalPersonaFields serializes the JSON-typed columns, defaulting nil
+// collections to their empty forms
+func marshalPersonaFields(persona *Persona) (string, string, string, error) {
+	if persona.Parameters == nil {
+		persona.Parameters = map[string]interface{}{}
+	}
+	if persona.Tools == nil {
+		persona.Tools = []string{}
+	}
+	if persona.Knowledge == nil {
+		persona.Knowledge = []string{}
+	}
+
+	parameters, err := json.Marshal(persona.Parameters)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to serialize parameters: %w", err)
+	}
+	tools, err := json.Marshal(persona.Tools)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to serialize tools: %w", err)
+	}
+	knowledge, err := json.Marshal(persona.Knowledge)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to serialize knowledge: %w", err)
+	}
+	return string(parameters), string(tools), string(knowledge), nil
+}
+
+// scanPersona scans one persona row, decoding the JSON-typed columns
+func scanPersona(scan func(...interface{}) error) (*Persona, error) {
+	persona := &Persona{}
+	var parameters, tools, knowledge, createdAt, updatedAt string
+
+	err := scan(&persona.ID, &persona.Name, &persona.Avatar, &persona.SystemPrompt, &persona.Model,
+		&parameters, &tools, &knowledge, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(parameters), &persona.Parameters); err != nil {
+		persona.Parameters = map[string]interface{}{}
+	}
+	if err := json.Unmarshal([]byte(tools), &persona.Tools); err != nil {
+		persona.Tools = []string{}
+	}
+	if err := json.Unmarshal([]byte(knowledge), &persona.Knowledge); err != nil {
+		persona.Knowledge = []string{}
+	}
+	persona.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	persona.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return persona, nil
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PromptSnippet is a reusable fragment of system prompt (a tone guide,
+// formatting rules, a safety preamble) that personas and chats pull in
+// by writing {{snippet:name}}. References resolve to the current content
+// at request time, so editing a snippet rolls out to everything using
+// it; Version counts edits so clients can tell when that happened.
+type PromptSnippet struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Content     string    `json:"content"`
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// snippetReferencePattern matches {{snippet:name}} references, with
+// optional whitespace around the name
+var snippetReferencePattern = regexp.MustCompile(`\{\{snippet:\s*([a-zA-Z0-9_.-]+)\s*\}\}`)
+
+// CreatePromptSnippet creates a new snippet at version 1
+func CreatePromptSnippet(db *sql.DB, snippet *PromptSnippet) error {
+	if snippet.ID == "" {
+		snippet.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	snippet.CreatedAt = now
+	snippet.UpdatedAt = now
+	snippet.Version = 1
+
+	_, err := db.Exec(`
+		INSERT INTO prompt_snippets (id, name, description, content, version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 1, ?, ?)`,
+		snippet.ID, snippet.Name, snippet.Description, snippet.Content,
+		snippet.CreatedAt.Format(time.RFC3339), snippet.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create prompt snippet: %w", err)
+	}
+	return nil
+}
+
+// ListPromptSnippets retrieves all snippets ordered by name
+func ListPromptSnippets(db *sql.DB) ([]PromptSnippet, error) {
+	rows, err := db.Query(`
+		SELECT id, name, description, content, version, created_at, updated_at
+		FROM prompt_snippets ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt snippets: %w", err)
+	}
+	defer rows.Close()
+
+	var snippets []PromptSnippet
+	for rows.Next() {
+		snippet, err := scanPromptSnippet(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		snippets = append(snippets, *snippet)
+	}
+	return snippets, rows.Err()
+}
+
+// GetPromptSnippet retrieves a single snippet by ID, or nil when missing
+func GetPromptSnippet(db *sql.DB, id string) (*PromptSnippet, error) {
+	row := db.QueryRow(`
+		SELECT id, name, description, content, version, created_at, updated_at
+		FROM prompt_snippets WHERE id = ?`, id)
+
+	snippet, err := scanPromptSnippet(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return snippet, err
+}
+
+// UpdatePromptSnippet updates an existing snippet, bumping its version
+func UpdatePromptSnippet(db *sql.DB, snippet *PromptSnippet) error {
+	snippet.UpdatedAt = time.Now().UTC()
+
+	result, err := db.Exec(`
+		UPDATE prompt_snippets
+		SET name = ?, description = ?, content = ?, version = version + 1, updated_at = ?
+		WHERE id = ?`,
+		snippet.Name, snippet.Description, snippet.Content,
+		snippet.UpdatedAt.Format(time.RFC3339), snippet.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update prompt snippet: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("prompt snippet not found")
+	}
+
+	return db.QueryRow(`SELECT version FROM prompt_snippets WHERE id = ?`, snippet.ID).Scan(&snippet.Version)
+}
+
+// DeletePromptSnippet deletes a snippet by ID
+func DeletePromptSnippet(db *sql.DB, id string) error {
+	result, err := db.Exec(`DELETE FROM prompt_snippets WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete prompt snippet: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("prompt snippet not found")
+	}
+	return nil
+}
+
+// ExpandPromptSnippets replaces {{snippet:name}} references in text with
+// the current snippet contents. Expansion is a single pass - snippets
+// cannot reference other snippets - and unknown names are left in place
+// so the problem is visible rather than silently dropped.
+func ExpandPromptSnippets(db *sql.DB, text string) (string, error) {
+	refs := snippetReferencePattern.FindAllStringSubmatch(text, -1)
+	if len(refs) == 0 {
+		return text, nil
+	}
+
+	contents := make(map[string]string, len(refs))
+	for _, ref := range refs {
+		name := ref[1]
+		if _, seen := contents[name]; seen {
+			continue
+		}
+		var content string
+		err := db.QueryRow(`SELECT content FROM prompt_snippets WHERE name = ?`, name).Scan(&content)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return text, fmt.Errorf("failed to resolve prompt snippet %q: %w", name, err)
+		}
+		contents[name] = content
+	}
+
+	expanded := snippetReferencePattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := snippetReferencePattern.FindStringSubmatch(match)[1]
+		if content, ok := contents[name]; ok {
+			return content
+		}
+		return match
+	})
+	return expanded, nil
+}
+
+// scanPromptSnippet scans a snippet row from either a Row or Rows scan
+func scanPromptSnippet(scan func(...interface{}) error) (*PromptSnippet, error) {
+	var snippet PromptSnippet
+	var createdAt, updatedAt string
+
+	err := scan(&snippet.ID, &snippet.Name, &snippet.Description, &snippet.Content,
+		&snippet.Version, &createdAt, &updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan prompt snippet: %w", err)
+	}
+
+	snippet.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	snippet.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return &snippet, nil
+}
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PromptTemplate is a reusable prompt with {{variable}} placeholders.
+// Variables is derived from Content on read and never stored.
+type PromptTemplate struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Content     string    `json:"content"`
+	Variables   []string  `json:"variables"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// templateVariablePattern matches {{name}} placeholders, with optional
+// whitespace inside the braces
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_.-]+)\s*\}\}`)
+
+// TemplateVariables returns the distinct placeholder names in content, in
+// order of first appearance
+func TemplateVariables(content string) []string {
+	seen := make(map[string]bool)
+	variables := []string{}
+	for _, match := range templateVariablePattern.FindAllStringSubmatch(content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			variables = append(variables, match[1])
+		}
+	}
+	return variables
+}
+
+// RenderTemplate substitutes values into the placeholders and returns the
+// rendered text along with any placeholders that had no value
+func RenderTemplate(content string, values map[string]string) (string, []string) {
+	var missing []string
+	seen := make(map[string]bool)
+
+	rendered := templateVariablePattern.ReplaceAllStringFunc(content, func(match string) string {
+		name := templateVariablePattern.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+		return match
+	})
+	return rendered, missing
+}
+
+// CreatePromptTemplate creates a new prompt template
+func CreatePromptTemplate(db *sql.DB, template *PromptTemplate) error {
+	if template.ID == "" {
+		template.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	template.CreatedAt = now
+	template.UpdatedAt = now
+	template.Variables = TemplateVariables(template.Content)
+
+	_, err := db.Exec(`
+		INSERT INTO prompt_templates (id, name, description, category, content, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		template.ID, template.Name, template.Description, template.Category, template.Content,
+		template.CreatedAt.Format(time.RFC3339), template.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create prompt template: %w", err)
+	}
+	return nil
+}
+
+// ListPromptTemplates retrieves all templates, optionally filtered by category
+func ListPromptTemplates(db *sql.DB, category string) ([]PromptTemplate, error) {
+	query := `SELECT id, name, description, category, content, created_at, updated_at
+		FROM prompt_templates`
+	args := []interface{}{}
+	if category != "" {
+		query += ` WHERE category = ?`
+		args = append(args, category)
+	}
+	query += ` ORDER BY category ASC, name ASC`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []PromptTemplate
+	for rows.Next() {
+		template, err := scanPromptTemplate(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	return templates, rows.Err()
+}
+
+// GetPromptTemplate retrieves a single template by ID, or nil when missing
+func GetPromptTemplate(db *sql.DB, id string) (*PromptTemplate, error) {
+	row := db.QueryRow(`
+		SELECT id, name, description, category, content, created_at, updated_at
+		FROM prompt_templates WHERE id = ?`, id)
+
+	template, err := scanPromptTemplate(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return template, err
+}
+
+// UpdatePromptTemplate updates an existing template
+func UpdatePromptTemplate(db *sql.DB, template *PromptTemplate) error {
+	template.UpdatedAt = time.Now().UTC()
+	template.Variables = TemplateVariables(template.Content)
+
+	result, err := db.Exec(`
+		UPDATE prompt_templates SET name = ?, description = ?, category = ?, content = ?, updated_at = ?
+		WHERE id = ?`,
+		template.Name, template.Description, template.Category, template.Content,
+		template.UpdatedAt.Format(time.RFC3339), template.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update prompt template: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("prompt template not found")
+	}
+	return nil
+}
+
+// DeletePromptTemplate deletes a template by ID
+func DeletePromptTemplate(db *sql.DB, id string) error {
+	result, err := db.Exec(`DELETE FROM prompt_templates WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete prompt template: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("prompt template not found")
+	}
+	return nil
+}
+
+// ListPromptTemplateCategories returns the distinct non-empty categories
+func ListPromptTemplateCategories(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT category FROM prompt_templates
+		WHERE category != '' ORDER BY category ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []string{}
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// scanPromptTemplate scans one template row and derives its variable list
+func scanPromptTemplate(scan func(...interface{}) error) (*PromptTemplate, error) {
+	template := &PromptTemplate{}
+	var createdAt, updatedAt string
+
+	err := scan(&template.ID, &template.Name, &template.Description, &template.Category,
+		&template.Content, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	template.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	template.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	template.Variables = TemplateVariables(template.Content)
+	return template, nil
+}
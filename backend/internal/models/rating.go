@@ -0,0 +1,67 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SetMessageRating records thumbs-up (1) or thumbs-down (-1) feedback on
+// a message, replacing any earlier rating
+func SetMessageRating(db *sql.DB, messageID string, rating int) error {
+	if rating != 1 && rating != -1 {
+		return fmt.Errorf("rating must be 1 or -1")
+	}
+
+	var exists int
+	err := db.QueryRow(`SELECT COUNT(*) FROM messages WHERE id = ?`, messageID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to set rating: %w", err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("message not found")
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO message_ratings (message_id, rating)
+		VALUES (?, ?)
+		ON CONFLICT(message_id) DO UPDATE SET rating = excluded.rating`,
+		messageID, rating,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set rating: %w", err)
+	}
+	return nil
+}
+
+// ClearMessageRating removes feedback from a message
+func ClearMessageRating(db *sql.DB, messageID string) error {
+	_, err := db.Exec(`DELETE FROM message_ratings WHERE message_id = ?`, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to clear rating: %w", err)
+	}
+	return nil
+}
+
+// GetChatRatings returns the rating per message ID for one chat
+func GetChatRatings(db *sql.DB, chatID string) (map[string]int, error) {
+	rows, err := db.Query(`
+		SELECT r.message_id, r.rating
+		FROM message_ratings r
+		JOIN messages m ON m.id = r.message_id
+		WHERE m.chat_id = ?`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ratings: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var rating int
+		if err := rows.Scan(&id, &rating); err != nil {
+			return nil, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		ratings[id] = rating
+	}
+	return ratings, rows.Err()
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ChatReadState is one device's last-read position in a chat. Devices
+// identify themselves with an opaque client-chosen ID; unread counts
+// compare message timestamps against the read message's created_at.
+type ChatReadState struct {
+	ChatID            string `json:"chat_id"`
+	DeviceID          string `json:"device_id"`
+	LastReadMessageID string `json:"last_read_message_id"`
+	LastReadMessageAt string `json:"last_read_message_at"`
+	UpdatedAt         string `json:"updated_at"`
+}
+
+// SetChatReadState records that the device has read the chat up to and
+// including the given message
+func SetChatReadState(db *sql.DB, chatID, deviceID, messageID, messageAt string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err := db.Exec(`
+		INSERT INTO chat_read_state (chat_id, device_id, last_read_message_id, last_read_message_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(chat_id, device_id) DO UPDATE SET
+			last_read_message_id = excluded.last_read_message_id,
+			last_read_message_at = excluded.last_read_message_at,
+			updated_at = excluded.updated_at`,
+		chatID, deviceID, messageID, messageAt, now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set read state: %w", err)
+	}
+	return nil
+}
+
+// GetChatReadState returns the device's read position in a chat, or nil
+// when the device has never marked it read
+func GetChatReadState(db *sql.DB, chatID, deviceID string) (*ChatReadState, error) {
+	state := &ChatReadState{}
+	err := db.QueryRow(`
+		SELECT chat_id, device_id, last_read_message_id, last_read_message_at, updated_at
+		FROM chat_read_state WHERE chat_id = ? AND device_id = ?`, chatID, deviceID).Scan(
+		&state.ChatID, &state.DeviceID, &state.LastReadMessageID, &state.LastReadMessageAt, &state.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get read state: %w", err)
+	}
+	return state, nil
+}
+
+// CountUnreadMessages counts the chat's messages newer than the
+// device's read position; a device without one sees everything as
+// unread
+func CountUnreadMessages(db *sql.DB, chatID, deviceID string) (int, error) {
+	var unread int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM messages m
+		WHERE m.chat_id = ? AND m.created_at > COALESCE(
+			(SELECT last_read_message_at FROM chat_read_state WHERE chat_id = ? AND device_id = ?), '')`,
+		chatID, chatID, deviceID).Scan(&unread)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return unread, nil
+}
+
+// ChatUnreadCount is one chat's unread total for a device
+type ChatUnreadCount struct {
+	ChatID string `json:"chat_id"`
+	Unread int    `json:"unread"`
+}
+
+// ListUnreadCounts returns the device's unread message count for every
+// chat that has any, in one query so the sidebar badge refresh stays
+// cheap
+func ListUnreadCounts(db *sql.DB, deviceID string) ([]ChatUnreadCount, error) {
+	rows, err := db.Query(`
+		SELECT m.chat_id, COUNT(*) FROM messages m
+		LEFT JOIN chat_read_state r ON r.chat_id = m.chat_id AND r.device_id = ?
+		WHERE m.created_at > COALESCE(r.last_read_message_at, '')
+		GROUP BY m.chat_id`, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unread counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []ChatUnreadCount{}
+	for rows.Next() {
+		var count ChatUnreadCount
+		if err := rows.Scan(&count.ChatID, &count.Unread); err != nil {
+			return nil, fmt.Errorf("failed to scan unread count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
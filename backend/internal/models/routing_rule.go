@@ -0,0 +1,115 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RoutingRule classifies a chat's first message and applies actions: a
+// tag on the chat, a persona, a model override, or any combination.
+// Regex rules match the pattern against the message; semantic rules
+// embed both the pattern text and the message and compare similarity.
+type RoutingRule struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	MatchType  string    `json:"match_type"` // "regex" or "semantic"
+	Pattern    string    `json:"pattern"`
+	Threshold  float64   `json:"threshold,omitempty"`
+	EmbedModel string    `json:"embed_model,omitempty"`
+	Tag        string    `json:"tag,omitempty"`
+	PersonaID  string    `json:"persona_id,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	Priority   int       `json:"priority"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CreateRoutingRule creates a new routing rule
+func CreateRoutingRule(db *sql.DB, rule *RoutingRule) error {
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	rule.CreatedAt = time.Now().UTC()
+
+	_, err := db.Exec(`
+		INSERT INTO routing_rules (id, name, match_type, pattern, threshold, embed_model, tag, persona_id, model, priority, enabled, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.ID, rule.Name, rule.MatchType, rule.Pattern, rule.Threshold, rule.EmbedModel,
+		rule.Tag, rule.PersonaID, rule.Model, rule.Priority, rule.Enabled,
+		rule.CreatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create routing rule: %w", err)
+	}
+	return nil
+}
+
+// ListRoutingRules retrieves all routing rules, highest priority first
+func ListRoutingRules(db *sql.DB, enabledOnly bool) ([]RoutingRule, error) {
+	query := `
+		SELECT id, name, match_type, pattern, threshold, embed_model, tag, persona_id, model, priority, enabled, created_at
+		FROM routing_rules`
+	if enabledOnly {
+		query += ` WHERE enabled = 1`
+	}
+	query += ` ORDER BY priority DESC, created_at ASC`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routing rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []RoutingRule
+	for rows.Next() {
+		var rule RoutingRule
+		var enabled int
+		var createdAt string
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.MatchType, &rule.Pattern, &rule.Threshold,
+			&rule.EmbedModel, &rule.Tag, &rule.PersonaID, &rule.Model, &rule.Priority,
+			&enabled, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan routing rule: %w", err)
+		}
+		rule.Enabled = enabled == 1
+		rule.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// UpdateRoutingRule updates an existing routing rule
+func UpdateRoutingRule(db *sql.DB, rule *RoutingRule) error {
+	result, err := db.Exec(`
+		UPDATE routing_rules SET name = ?, match_type = ?, pattern = ?, threshold = ?, embed_model = ?,
+		tag = ?, persona_id = ?, model = ?, priority = ?, enabled = ?
+		WHERE id = ?`,
+		rule.Name, rule.MatchType, rule.Pattern, rule.Threshold, rule.EmbedModel,
+		rule.Tag, rule.PersonaID, rule.Model, rule.Priority, rule.Enabled, rule.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update routing rule: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("routing rule not found")
+	}
+	return nil
+}
+
+// DeleteRoutingRule deletes a routing rule by ID
+func DeleteRoutingRule(db *sql.DB, id string) error {
+	result, err := db.Exec(`DELETE FROM routing_rules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete routing rule: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("routing rule not found")
+	}
+	return nil
+}
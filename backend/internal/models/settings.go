@@ -0,0 +1,42 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// GetAllSettings returns every stored setting as raw JSON keyed by name
+func GetAllSettings(db *sql.DB) (map[string]json.RawMessage, error) {
+	rows, err := db.Query(`SELECT key, value FROM settings ORDER BY key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := make(map[string]json.RawMessage)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		settings[key] = json.RawMessage(value)
+	}
+	return settings, rows.Err()
+}
+
+// SetSetting upserts one setting's JSON value
+func SetSetting(db *sql.DB, key string, value json.RawMessage) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err := db.Exec(`
+		INSERT INTO settings (key, value, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, key, string(value), now)
+	return err
+}
+
+// DeleteSetting removes a setting; deleting a missing key is not an error
+func DeleteSetting(db *sql.DB, key string) error {
+	_, err := db.Exec(`DELETE FROM settings WHERE key = ?`, key)
+	return err
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SlashCommand is a composer shortcut like /summarize or /translate-to:fr.
+// The template uses the same {{variable}} syntax as prompt templates, with
+// {{text}} bound to the selected text and {{arg}} to the part after the
+// colon.
+type SlashCommand struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Template    string    `json:"template"`
+	Variables   []string  `json:"variables"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// slashCommandNamePattern limits command names to the characters the
+// composer can parse unambiguously
+var slashCommandNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ValidSlashCommandName reports whether name is usable as a command name
+// (lowercase letters, digits, and hyphens; no leading slash)
+func ValidSlashCommandName(name string) bool {
+	return slashCommandNamePattern.MatchString(name)
+}
+
+// CreateSlashCommand creates a new slash command
+func CreateSlashCommand(db *sql.DB, command *SlashCommand) error {
+	if command.ID == "" {
+		command.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	command.CreatedAt = now
+	command.UpdatedAt = now
+	command.Variables = TemplateVariables(command.Template)
+
+	_, err := db.Exec(`
+		INSERT INTO slash_commands (id, name, description, template, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		command.ID, command.Name, command.Description, command.Template,
+		command.CreatedAt.Format(time.RFC3339), command.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create slash command: %w", err)
+	}
+	return nil
+}
+
+// ListSlashCommands retrieves all slash commands ordered by name
+func ListSlashCommands(db *sql.DB) ([]SlashCommand, error) {
+	rows, err := db.Query(`
+		SELECT id, name, description, template, created_at, updated_at
+		FROM slash_commands ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list slash commands: %w", err)
+	}
+	defer rows.Close()
+
+	var commands []SlashCommand
+	for rows.Next() {
+		command, err := scanSlashCommand(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, *command)
+	}
+	return commands, rows.Err()
+}
+
+// GetSlashCommandByName retrieves a command by its name, or nil when missing
+func GetSlashCommandByName(db *sql.DB, name string) (*SlashCommand, error) {
+	row := db.QueryRow(`
+		SELECT id, name, description, template, created_at, updated_at
+		FROM slash_commands WHERE name = ?`, name)
+
+	command, err := scanSlashCommand(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return command, err
+}
+
+// UpdateSlashCommand updates an existing slash command
+func UpdateSlashCommand(db *sql.DB, command *SlashCommand) error {
+	command.UpdatedAt = time.Now().UTC()
+	command.Variables = TemplateVariables(command.Template)
+
+	result, err := db.Exec(`
+		UPDATE slash_commands SET name = ?, description = ?, template = ?, updated_at = ?
+		WHERE id = ?`,
+		command.Name, command.Description, command.Template,
+		command.UpdatedAt.Format(time.RFC3339), command.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update slash command: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("slash command not found")
+	}
+	return nil
+}
+
+// DeleteSlashCommand deletes a slash command by ID
+func DeleteSlashCommand(db *sql.DB, id string) error {
+	result, err := db.Exec(`DELETE FROM slash_commands WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete slash command: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("slash command not found")
+	}
+	return nil
+}
+
+// scanSlashCommand scans one command row and derives its variable list
+func scanSlashCommand(scan func(...interface{}) error) (*SlashCommand, error) {
+	command := &SlashCommand{}
+	var createdAt, updatedAt string
+
+	err := scan(&command.ID, &command.Name, &command.Description, &command.Template,
+		&createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	command.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	command.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	command.Variables = TemplateVariables(command.Template)
+	return command, nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// TelegramChat maps a Telegram conversation to a vessel chat. Model, when
+// set, overrides the bridge's default model for this conversation.
+type TelegramChat struct {
+	TelegramChatID int64  `json:"telegram_chat_id"`
+	ChatID         string `json:"chat_id"`
+	Model          string `json:"model,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// GetTelegramChat retrieves the mapping for a Telegram conversation, or
+// nil when none exists yet
+func GetTelegramChat(db *sql.DB, telegramChatID int64) (*TelegramChat, error) {
+	var tc TelegramChat
+	err := db.QueryRow(`
+		SELECT telegram_chat_id, chat_id, model, created_at
+		FROM telegram_chats WHERE telegram_chat_id = ?`, telegramChatID).
+		Scan(&tc.TelegramChatID, &tc.ChatID, &tc.Model, &tc.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get telegram chat: %w", err)
+	}
+	return &tc, nil
+}
+
+// UpsertTelegramChat points a Telegram conversation at a vessel chat,
+// replacing any previous mapping (used by /new to start fresh)
+func UpsertTelegramChat(db *sql.DB, tc *TelegramChat) error {
+	tc.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	_, err := db.Exec(`
+		INSERT INTO telegram_chats (telegram_chat_id, chat_id, model, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(telegram_chat_id) DO UPDATE SET chat_id = excluded.chat_id, model = excluded.model`,
+		tc.TelegramChatID, tc.ChatID, tc.Model, tc.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert telegram chat: %w", err)
+	}
+	return nil
+}
+
+// SetTelegramChatModel stores a per-conversation model override
+func SetTelegramChatModel(db *sql.DB, telegramChatID int64, model string) error {
+	result, err := db.Exec(`
+		UPDATE telegram_chats SET model = ? WHERE telegram_chat_id = ?`,
+		model, telegramChatID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set telegram chat model: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("telegram chat not found")
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook is an outbound event subscriber. Events lists the event names
+// it receives; an empty list subscribes it to everything. Secret, when
+// set, signs each delivery with HMAC-SHA256.
+type Webhook struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Secret    string   `json:"secret,omitempty"`
+	Events    []string `json:"events"`
+	Enabled   bool     `json:"enabled"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// WebhookDelivery is one attempt log entry: the final status of delivering
+// an event to a webhook, including how many attempts it took
+type WebhookDelivery struct {
+	ID         string `json:"id"`
+	WebhookID  string `json:"webhook_id"`
+	Event      string `json:"event"`
+	StatusCode int    `json:"status_code"`
+	Attempts   int    `json:"attempts"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// SubscribesTo reports whether the webhook should receive the named event
+func (w *Webhook) SubscribesTo(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhook inserts a new webhook
+func CreateWebhook(db *sql.DB, webhook *Webhook) error {
+	webhook.ID = uuid.New().String()
+	webhook.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+	if webhook.Events == nil {
+		events = []byte("[]")
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO webhooks (id, url, secret, events, enabled, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		webhook.ID, webhook.URL, webhook.Secret, string(events), webhook.Enabled, webhook.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// ListWebhooks returns all webhooks
+func ListWebhooks(db *sql.DB) ([]Webhook, error) {
+	rows, err := db.Query(`
+		SELECT id, url, secret, events, enabled, created_at
+		FROM webhooks ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// ListEnabledWebhooksFor returns the enabled webhooks subscribed to an event
+func ListEnabledWebhooksFor(db *sql.DB, event string) ([]Webhook, error) {
+	all, err := ListWebhooks(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []Webhook
+	for _, webhook := range all {
+		if webhook.Enabled && webhook.SubscribesTo(event) {
+			matched = append(matched, webhook)
+		}
+	}
+	return matched, nil
+}
+
+// UpdateWebhook updates a webhook's URL, secret, event filter, and enabled flag
+func UpdateWebhook(db *sql.DB, webhook *Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+	if webhook.Events == nil {
+		events = []byte("[]")
+	}
+
+	result, err := db.Exec(`
+		UPDATE webhooks SET url = ?, secret = ?, events = ?, enabled = ?
+		WHERE id = ?`,
+		webhook.URL, webhook.Secret, string(events), webhook.Enabled, webhook.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+// GetWebhook retrieves a webhook by ID, or nil when it doesn't exist
+func GetWebhook(db *sql.DB, id string) (*Webhook, error) {
+	row := db.QueryRow(`
+		SELECT id, url, secret, events, enabled, created_at
+		FROM webhooks WHERE id = ?`, id)
+
+	webhook, err := scanWebhook(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return webhook, err
+}
+
+// DeleteWebhook deletes a webhook and its delivery log (via cascade)
+func DeleteWebhook(db *sql.DB, id string) error {
+	result, err := db.Exec("DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+// RecordWebhookDelivery logs the final outcome of one event delivery
+func RecordWebhookDelivery(db *sql.DB, delivery *WebhookDelivery) error {
+	delivery.ID = uuid.New().String()
+	delivery.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	_, err := db.Exec(`
+		INSERT INTO webhook_deliveries (id, webhook_id, event, status_code, attempts, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		delivery.ID, delivery.WebhookID, delivery.Event, delivery.StatusCode,
+		delivery.Attempts, delivery.Error, delivery.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns the most recent deliveries for a webhook
+func ListWebhookDeliveries(db *sql.DB, webhookID string, limit int) ([]WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := db.Query(`
+		SELECT id, webhook_id, event, status_code, attempts, error, created_at
+		FROM webhook_deliveries WHERE webhook_id = ?
+		ORDER BY created_at DESC LIMIT ?`, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Event, &d.StatusCode, &d.Attempts, &d.Error, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// scanWebhook scans a webhook row, decoding the JSON events column
+func scanWebhook(scan func(...interface{}) error) (*Webhook, error) {
+	var webhook Webhook
+	var events string
+
+	if err := scan(&webhook.ID, &webhook.URL, &webhook.Secret, &events, &webhook.Enabled, &webhook.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan webhook: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(events), &webhook.Events); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook events: %w", err)
+	}
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+	return &webhook, nil
+}
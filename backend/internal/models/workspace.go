@@ -0,0 +1,248 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Workspace is a namespace above chats: its own chat list, model
+// allowlist, settings blob, and membership. ModelAllowlist empty means
+// every model is allowed.
+type Workspace struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	ModelAllowlist []string        `json:"model_allowlist"`
+	Settings       json.RawMessage `json:"settings"`
+	CreatedAt      string          `json:"created_at"`
+	UpdatedAt      string          `json:"updated_at"`
+}
+
+// WorkspaceMember is one user's membership in a workspace. Role is
+// "owner" or "member".
+type WorkspaceMember struct {
+	WorkspaceID string `json:"workspace_id"`
+	User        string `json:"user"`
+	Role        string `json:"role"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// ModelAllowed reports whether the workspace permits a model; an empty
+// allowlist permits everything
+func (w *Workspace) ModelAllowed(model string) bool {
+	if len(w.ModelAllowlist) == 0 {
+		return true
+	}
+	for _, allowed := range w.ModelAllowlist {
+		if allowed == model {
+			return true
+		}
+	}
+	return false
+}
+
+// marshalWorkspaceFields normalizes the JSON columns, defaulting nils
+func marshalWorkspaceFields(w *Workspace) (allowlist, settings string, err error) {
+	if w.ModelAllowlist == nil {
+		w.ModelAllowlist = []string{}
+	}
+	if len(w.Settings) == 0 {
+		w.Settings = json.RawMessage("{}")
+	}
+
+	allowlistBytes, err := json.Marshal(w.ModelAllowlist)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode model allowlist: %w", err)
+	}
+	return string(allowlistBytes), string(w.Settings), nil
+}
+
+// CreateWorkspace inserts a new workspace
+func CreateWorkspace(db *sql.DB, w *Workspace) error {
+	w.ID = uuid.New().String()
+	now := time.Now().UTC().Format(time.RFC3339)
+	w.CreatedAt = now
+	w.UpdatedAt = now
+
+	allowlist, settings, err := marshalWorkspaceFields(w)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO workspaces (id, name, description, model_allowlist, settings, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		w.ID, w.Name, w.Description, allowlist, settings, w.CreatedAt, w.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create workspace: %w", err)
+	}
+	return nil
+}
+
+// GetWorkspace retrieves a workspace by ID, or nil when it doesn't exist
+func GetWorkspace(db *sql.DB, id string) (*Workspace, error) {
+	row := db.QueryRow(`
+		SELECT id, name, description, model_allowlist, settings, created_at, updated_at
+		FROM workspaces WHERE id = ?`, id)
+
+	workspace, err := scanWorkspace(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return workspace, err
+}
+
+// ListWorkspaces returns all workspaces ordered by name
+func ListWorkspaces(db *sql.DB) ([]Workspace, error) {
+	rows, err := db.Query(`
+		SELECT id, name, description, model_allowlist, settings, created_at, updated_at
+		FROM workspaces ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	var workspaces []Workspace
+	for rows.Next() {
+		workspace, err := scanWorkspace(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, *workspace)
+	}
+	return workspaces, rows.Err()
+}
+
+// UpdateWorkspace updates a workspace's fields
+func UpdateWorkspace(db *sql.DB, w *Workspace) error {
+	w.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	allowlist, settings, err := marshalWorkspaceFields(w)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec(`
+		UPDATE workspaces SET name = ?, description = ?, model_allowlist = ?, settings = ?, updated_at = ?
+		WHERE id = ?`,
+		w.Name, w.Description, allowlist, settings, w.UpdatedAt, w.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update workspace: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("workspace not found")
+	}
+	return nil
+}
+
+// DeleteWorkspace deletes a workspace and its memberships. Chats keep
+// their workspace_id; they become orphaned rather than deleted.
+func DeleteWorkspace(db *sql.DB, id string) error {
+	result, err := db.Exec("DELETE FROM workspaces WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete workspace: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("workspace not found")
+	}
+	return nil
+}
+
+// AddWorkspaceMember adds or updates one membership
+func AddWorkspaceMember(db *sql.DB, member *WorkspaceMember) error {
+	if member.Role == "" {
+		member.Role = "member"
+	}
+	member.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	_, err := db.Exec(`
+		INSERT INTO workspace_members (workspace_id, user, role, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(workspace_id, user) DO UPDATE SET role = excluded.role`,
+		member.WorkspaceID, member.User, member.Role, member.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add workspace member: %w", err)
+	}
+	return nil
+}
+
+// RemoveWorkspaceMember removes one membership
+func RemoveWorkspaceMember(db *sql.DB, workspaceID, user string) error {
+	result, err := db.Exec(`
+		DELETE FROM workspace_members WHERE workspace_id = ? AND user = ?`,
+		workspaceID, user,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove workspace member: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("member not found")
+	}
+	return nil
+}
+
+// ListWorkspaceMembers returns a workspace's members
+func ListWorkspaceMembers(db *sql.DB, workspaceID string) ([]WorkspaceMember, error) {
+	rows, err := db.Query(`
+		SELECT workspace_id, user, role, created_at
+		FROM workspace_members WHERE workspace_id = ? ORDER BY user`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []WorkspaceMember
+	for rows.Next() {
+		var member WorkspaceMember
+		if err := rows.Scan(&member.WorkspaceID, &member.User, &member.Role, &member.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace member: %w", err)
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// scanWorkspace scans a workspace row, decoding the JSON columns
+func scanWorkspace(scan func(...interface{}) error) (*Workspace, error) {
+	var workspace Workspace
+	var allowlist, settings string
+
+	if err := scan(&workspace.ID, &workspace.Name, &workspace.Description, &allowlist, &settings,
+		&workspace.CreatedAt, &workspace.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan workspace: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(allowlist), &workspace.ModelAllowlist); err != nil {
+		return nil, fmt.Errorf("failed to decode model allowlist: %w", err)
+	}
+	if workspace.ModelAllowlist == nil {
+		workspace.ModelAllowlist = []string{}
+	}
+	workspace.Settings = json.RawMessage(settings)
+	return &workspace, nil
+}
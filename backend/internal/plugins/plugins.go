@@ -0,0 +1,372 @@
+// Package plugins runs external plugins: separate processes that extend
+// vessel with tools, search providers, storage backends, and ingestion
+// formats without recompiling it.
+//
+// A plugin lives in its own subdirectory of the plugins dir with a
+// plugin.yaml manifest. It speaks JSON-RPC 2.0, either line-delimited
+// over stdio (vessel spawns and owns the process) or over HTTP POST (the
+// plugin runs wherever it likes). The core methods are:
+//
+//	plugin.describe          -> capabilities and provided tools
+//	tool.execute             -> run one named tool with arguments
+//	search.query             -> answer a search as a provider
+//
+// Unknown methods are the plugin's own business; vessel forwards whatever
+// the admin API asks for.
+package plugins
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"vessel-backend/internal/logging"
+)
+
+// manifestFile is the per-plugin manifest name
+const manifestFile = "plugin.yaml"
+
+// callTimeout bounds one JSON-RPC round trip
+const callTimeout = 30 * time.Second
+
+// Manifest describes one plugin: how to reach it and what it claims to
+// provide. Protocol is "stdio" (command is spawned and owned by vessel)
+// or "http" (url points at a running JSON-RPC endpoint).
+type Manifest struct {
+	Name         string   `yaml:"name" json:"name"`
+	Version      string   `yaml:"version" json:"version"`
+	Description  string   `yaml:"description" json:"description"`
+	Protocol     string   `yaml:"protocol" json:"protocol"`
+	Command      []string `yaml:"command" json:"command,omitempty"`
+	URL          string   `yaml:"url" json:"url,omitempty"`
+	Capabilities []string `yaml:"capabilities" json:"capabilities"`
+}
+
+// Plugin is one loaded plugin and, for stdio plugins, its process
+type Plugin struct {
+	Manifest Manifest
+	Dir      string
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   *json.Encoder
+	stdout  *bufio.Reader
+	nextID  int64
+	lastErr string
+}
+
+// Status is the admin view of one plugin
+type Status struct {
+	Manifest Manifest `json:"manifest"`
+	Running  bool     `json:"running"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// rpcRequest and rpcResponse are the JSON-RPC 2.0 wire format
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int64       `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// httpClient serves HTTP-protocol plugins
+var httpClient = &http.Client{Timeout: callTimeout}
+
+// Manager discovers and supervises the installed plugins
+type Manager struct {
+	dir string
+
+	mu      sync.RWMutex
+	plugins map[string]*Plugin
+}
+
+// NewManager creates a manager rooted at dir; an empty dir disables
+// plugins (Discover becomes a no-op)
+func NewManager(dir string) *Manager {
+	return &Manager{dir: dir, plugins: make(map[string]*Plugin)}
+}
+
+// Discover scans the plugins dir, loads every manifest, and starts stdio
+// plugins. Broken plugins are logged and skipped; one bad manifest should
+// not take the rest down.
+func (m *Manager) Discover() {
+	if m.dir == "" {
+		return
+	}
+	logger := logging.Module("plugins")
+
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("Failed to read plugins dir", "dir", m.dir, "error", err)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pluginDir := filepath.Join(m.dir, entry.Name())
+
+		manifest, err := loadManifest(filepath.Join(pluginDir, manifestFile))
+		if err != nil {
+			logger.Warn("Skipping plugin", "dir", pluginDir, "error", err)
+			continue
+		}
+
+		plugin := &Plugin{Manifest: *manifest, Dir: pluginDir}
+		if manifest.Protocol == "stdio" {
+			if err := plugin.start(); err != nil {
+				plugin.lastErr = err.Error()
+				logger.Warn("Failed to start plugin", "plugin", manifest.Name, "error", err)
+			}
+		}
+
+		m.mu.Lock()
+		m.plugins[manifest.Name] = plugin
+		m.mu.Unlock()
+		logger.Info("Loaded plugin", "plugin", manifest.Name, "version", manifest.Version, "protocol", manifest.Protocol)
+	}
+}
+
+// loadManifest reads and validates one plugin.yaml
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	if manifest.Name == "" {
+		return nil, fmt.Errorf("manifest missing name")
+	}
+	switch manifest.Protocol {
+	case "stdio":
+		if len(manifest.Command) == 0 {
+			return nil, fmt.Errorf("stdio plugin missing command")
+		}
+	case "http":
+		if manifest.URL == "" {
+			return nil, fmt.Errorf("http plugin missing url")
+		}
+	default:
+		return nil, fmt.Errorf("unknown protocol %q (want stdio or http)", manifest.Protocol)
+	}
+	return &manifest, nil
+}
+
+// List returns every plugin with its current status
+func (m *Manager) List() []Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(m.plugins))
+	for _, plugin := range m.plugins {
+		statuses = append(statuses, plugin.status())
+	}
+	return statuses
+}
+
+// Get returns one plugin by name, or nil
+func (m *Manager) Get(name string) *Plugin {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.plugins[name]
+}
+
+// Restart stops and restarts a stdio plugin (HTTP plugins have nothing to
+// restart and just get their error state cleared)
+func (m *Manager) Restart(name string) error {
+	plugin := m.Get(name)
+	if plugin == nil {
+		return fmt.Errorf("plugin not found")
+	}
+
+	plugin.stop()
+	plugin.mu.Lock()
+	plugin.lastErr = ""
+	plugin.mu.Unlock()
+
+	if plugin.Manifest.Protocol == "stdio" {
+		if err := plugin.start(); err != nil {
+			plugin.mu.Lock()
+			plugin.lastErr = err.Error()
+			plugin.mu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+// StopAll terminates every stdio plugin process (shutdown path)
+func (m *Manager) StopAll() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, plugin := range m.plugins {
+		plugin.stop()
+	}
+}
+
+// Call forwards one JSON-RPC call to a plugin by name
+func (m *Manager) Call(name, method string, params interface{}) (json.RawMessage, error) {
+	plugin := m.Get(name)
+	if plugin == nil {
+		return nil, fmt.Errorf("plugin not found")
+	}
+	return plugin.Call(method, params)
+}
+
+// status snapshots the plugin's running state
+func (p *Plugin) status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	running := p.Manifest.Protocol == "http" || (p.cmd != nil && p.cmd.Process != nil)
+	return Status{Manifest: p.Manifest, Running: running, Error: p.lastErr}
+}
+
+// start spawns a stdio plugin and wires up its pipes
+func (p *Plugin) start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cmd := exec.Command(p.Manifest.Command[0], p.Manifest.Command[1:]...)
+	cmd.Dir = p.Dir
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", p.Manifest.Command[0], err)
+	}
+
+	p.cmd = cmd
+	p.stdin = json.NewEncoder(stdin)
+	p.stdout = bufio.NewReader(stdout)
+
+	// Reap the process when it exits so a crashed plugin shows as stopped
+	go func() {
+		err := cmd.Wait()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.cmd == cmd {
+			p.cmd = nil
+			if err != nil {
+				p.lastErr = err.Error()
+			}
+		}
+	}()
+	return nil
+}
+
+// stop terminates a stdio plugin process if one is running
+func (p *Plugin) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd != nil && p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+		p.cmd = nil
+	}
+}
+
+// Call performs one JSON-RPC round trip. Stdio calls are serialized per
+// plugin - the protocol is strictly request/response over one pipe pair.
+func (p *Plugin) Call(method string, params interface{}) (json.RawMessage, error) {
+	if p.Manifest.Protocol == "http" {
+		return p.callHTTP(method, params)
+	}
+	return p.callStdio(method, params)
+}
+
+// callStdio writes one request line and reads one response line
+func (p *Plugin) callStdio(method string, params interface{}) (json.RawMessage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cmd == nil {
+		return nil, fmt.Errorf("plugin is not running")
+	}
+
+	p.nextID++
+	if err := p.stdin.Encode(rpcRequest{JSONRPC: "2.0", ID: p.nextID, Method: method, Params: params}); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	line, err := p.stdout.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var resp rpcResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("plugin error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+	return resp.Result, nil
+}
+
+// callHTTP posts one JSON-RPC request to the plugin's endpoint
+func (p *Plugin) callHTTP(method string, params interface{}) (json.RawMessage, error) {
+	p.mu.Lock()
+	p.nextID++
+	id := p.nextID
+	p.mu.Unlock()
+
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := httpClient.Post(p.Manifest.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("plugin returned status %d", httpResp.StatusCode)
+	}
+
+	var resp rpcResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("plugin error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+	return resp.Result, nil
+}
@@ -0,0 +1,58 @@
+// Package web embeds the built frontend so a single binary can serve the
+// whole application. Populate internal/web/dist with the frontend build
+// output (make embed-frontend) before compiling; otherwise a placeholder
+// page is served that points at the standalone frontend setup.
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed all:dist
+var distFS embed.FS
+
+// SPAHandler serves the embedded frontend with history-mode fallback:
+// requests for real files get the file, everything else (client-side
+// routes like /chats/123) gets index.html. API routes never reach this
+// handler because it is registered via NoRoute.
+func SPAHandler() gin.HandlerFunc {
+	dist, err := fs.Sub(distFS, "dist")
+	if err != nil {
+		// The embed directive guarantees dist exists; this is unreachable
+		// outside of a broken build.
+		panic(err)
+	}
+	fileServer := http.FileServer(http.FS(dist))
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		requestPath := strings.TrimPrefix(path.Clean(c.Request.URL.Path), "/")
+		if requestPath == "" {
+			requestPath = "index.html"
+		}
+
+		if _, err := fs.Stat(dist, requestPath); err != nil {
+			// Not a real file - serve index.html so the SPA router takes over
+			c.Request.URL.Path = "/"
+		}
+
+		// Hashed build assets are immutable; everything else revalidates
+		if strings.HasPrefix(requestPath, "_app/immutable/") {
+			c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			c.Header("Cache-Control", "no-cache")
+		}
+
+		fileServer.ServeHTTP(c.Writer, c.Request)
+	}
+}